@@ -31,12 +31,14 @@ import (
 "github.com/gin-gonic/gin"
 "github.com/google/uuid"
 "gorm.io/gorm"
+"gorm.io/gorm/clause"
 
 {{- if .EnableRBAC}}
 "github.com/Jayleonc/service/internal/rbac"
 {{- end}}
 "github.com/Jayleonc/service/internal/feature"
 "github.com/Jayleonc/service/pkg/database"
+"github.com/Jayleonc/service/pkg/ginx/filter"
 "github.com/Jayleonc/service/pkg/ginx/paginator"
 "github.com/Jayleonc/service/pkg/ginx/request"
 "github.com/Jayleonc/service/pkg/ginx/response"
@@ -102,15 +104,13 @@ AuthenticatedRoutes: []feature.RouteDefinition{
 func (h *Handler) create(c *gin.Context) {
 var input CreateInput
 if err := c.ShouldBindJSON(&input); err != nil {
-response.Error(c, http.StatusBadRequest,
-        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+response.BindError(c, err)
 return
 }
 
 db := database.Default()
 if db == nil {
-response.Error(c, http.StatusInternalServerError,
-        xerr.ErrInternalServer.WithMessage("database is not initialised"))
+response.Fail(c, xerr.ErrInternalServer.WithMessage("database is not initialised"))
 return
 }
 
@@ -120,7 +120,7 @@ return
         }
 
 if err := db.WithContext(c.Request.Context()).Create(record).Error; err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -130,33 +130,29 @@ response.SuccessWithStatus(c, http.StatusCreated, record)
 func (h *Handler) getByID(c *gin.Context) {
         var input GetByIDInput
 if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
 db := database.Default()
 if db == nil {
-response.Error(c, http.StatusInternalServerError,
-        xerr.ErrInternalServer.WithMessage("database is not initialised"))
+response.Fail(c, xerr.ErrInternalServer.WithMessage("database is not initialised"))
 return
 }
 
 var record {{.EntityName}}
 if err := db.WithContext(c.Request.Context()).First(&record, "id = ?", id).Error; err != nil {
 if errors.Is(err, gorm.ErrRecordNotFound) {
-response.Error(c, http.StatusNotFound,
-        xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
+response.Fail(c, xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
 return
 }
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -166,22 +162,19 @@ response.Success(c, record)
 func (h *Handler) update(c *gin.Context) {
         var input UpdateInput
 if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
 db := database.Default()
 if db == nil {
-response.Error(c, http.StatusInternalServerError,
-        xerr.ErrInternalServer.WithMessage("database is not initialised"))
+response.Fail(c, xerr.ErrInternalServer.WithMessage("database is not initialised"))
 return
 }
 
@@ -189,18 +182,17 @@ ctx := c.Request.Context()
 var record {{.EntityName}}
         if err := db.WithContext(ctx).First(&record, "id = ?", id).Error; err != nil {
                 if errors.Is(err, gorm.ErrRecordNotFound) {
-                        response.Error(c, http.StatusNotFound,
-                                xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
+                        response.Fail(c, xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
                         return
                 }
-                response.Error(c, http.StatusInternalServerError, err)
+                response.Fail(c, err)
                 return
         }
 
         record.Name = input.Name
 
 if err := db.WithContext(ctx).Save(&record).Error; err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -210,27 +202,24 @@ response.Success(c, record)
 func (h *Handler) delete(c *gin.Context) {
         var input DeleteInput
 if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
 db := database.Default()
 if db == nil {
-response.Error(c, http.StatusInternalServerError,
-        xerr.ErrInternalServer.WithMessage("database is not initialised"))
+response.Fail(c, xerr.ErrInternalServer.WithMessage("database is not initialised"))
 return
 }
 
 if err := db.WithContext(c.Request.Context()).Delete(&{{.EntityName}}{}, "id = ?", id).Error; err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -241,16 +230,14 @@ func (h *Handler) list(c *gin.Context) {
         var query ListQuery
 if err := c.ShouldBindJSON(&query); err != nil {
                 if !errors.Is(err, io.EOF) {
-                        response.Error(c, http.StatusBadRequest,
-                                xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                        response.BindError(c, err)
                         return
                 }
 }
 
 db := database.Default()
 if db == nil {
-response.Error(c, http.StatusInternalServerError,
-        xerr.ErrInternalServer.WithMessage("database is not initialised"))
+response.Fail(c, xerr.ErrInternalServer.WithMessage("database is not initialised"))
 return
 }
 
@@ -262,12 +249,12 @@ pageReq := request.Pagination{
 
 session := db.WithContext(c.Request.Context()).Model(&{{.EntityName}}{})
 if query.Name != "" {
-session = session.Where("name LIKE ?", "%"+query.Name+"%")
+session = session.Where(filter.Contains(clause.Column{Name: "name"}, query.Name))
 }
 
-result, err := paginator.Paginate[{{.EntityName}}](session, &pageReq)
+result, err := paginator.Paginate[{{.EntityName}}](session, &pageReq, "name", "created_at", "updated_at")
 if err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -324,7 +311,9 @@ import (
 
 "github.com/google/uuid"
 "gorm.io/gorm"
+"gorm.io/gorm/clause"
 
+"github.com/Jayleonc/service/pkg/ginx/filter"
 "github.com/Jayleonc/service/pkg/ginx/paginator"
 "github.com/Jayleonc/service/pkg/ginx/request"
 "github.com/Jayleonc/service/pkg/ginx/response"
@@ -374,11 +363,11 @@ return &entity, nil
 func (r *Repository) List(ctx context.Context, opts ListOptions) (*response.PageResult[{{.EntityName}}], error) {
 query := r.db.WithContext(ctx).Model(&{{.EntityName}}{})
 if opts.Name != "" {
-query = query.Where("name LIKE ?", "%"+opts.Name+"%")
+query = query.Where(filter.Contains(clause.Column{Name: "name"}, opts.Name))
 }
 
 pagination := opts.Pagination
-return paginator.Paginate[{{.EntityName}}](query, &pagination)
+return paginator.Paginate[{{.EntityName}}](query, &pagination, "name", "created_at", "updated_at")
 }
 `
 
@@ -564,8 +553,7 @@ AuthenticatedRoutes: []feature.RouteDefinition{
 func (h *Handler) create(c *gin.Context) {
 var input CreateInput
 if err := c.ShouldBindJSON(&input); err != nil {
-response.Error(c, http.StatusBadRequest,
-        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+response.BindError(c, err)
 return
 }
 
@@ -573,7 +561,7 @@ return
                 Name: input.Name,
         })
 if err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -583,26 +571,23 @@ response.SuccessWithStatus(c, http.StatusCreated, entity)
 func (h *Handler) getByID(c *gin.Context) {
         var input GetByIDInput
         if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
 record, err := h.service.GetByID(c.Request.Context(), id)
 if err != nil {
 if errors.Is(err, gorm.ErrRecordNotFound) {
-response.Error(c, http.StatusNotFound,
-        xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
+response.Fail(c, xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
 return
 }
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -612,15 +597,13 @@ response.Success(c, record)
 func (h *Handler) update(c *gin.Context) {
         var input UpdateInput
         if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
@@ -630,11 +613,10 @@ func (h *Handler) update(c *gin.Context) {
         })
 if err != nil {
 if errors.Is(err, gorm.ErrRecordNotFound) {
-response.Error(c, http.StatusNotFound,
-        xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
+response.Fail(c, xerr.ErrNotFound.WithMessage("{{.EntityVar}} not found"))
 return
 }
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -644,20 +626,18 @@ response.Success(c, record)
 func (h *Handler) delete(c *gin.Context) {
         var input DeleteInput
         if err := c.ShouldBindJSON(&input); err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                response.BindError(c, err)
                 return
         }
 
         id, err := uuid.Parse(input.ID)
         if err != nil {
-                response.Error(c, http.StatusBadRequest,
-                        xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
+                response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid resource identifier"))
                 return
         }
 
         if err := h.service.Delete(c.Request.Context(), id); err != nil {
-                response.Error(c, http.StatusInternalServerError, err)
+                response.Fail(c, err)
                 return
         }
 
@@ -668,8 +648,7 @@ func (h *Handler) list(c *gin.Context) {
         var query ListQuery
         if err := c.ShouldBindJSON(&query); err != nil {
                 if !errors.Is(err, io.EOF) {
-                        response.Error(c, http.StatusBadRequest,
-                                xerr.ErrBadRequest.WithMessage("invalid request payload"))
+                        response.BindError(c, err)
                         return
                 }
         }
@@ -683,7 +662,7 @@ OrderBy:  query.OrderBy,
 Name: query.Name,
 })
 if err != nil {
-response.Error(c, http.StatusInternalServerError, err)
+response.Fail(c, err)
 return
 }
 
@@ -739,4 +718,8 @@ const errorsTemplate = `package {{.Package}}
 // // var (
 // //     ErrExampleFailure = xerr.New({{.SuggestedErrorCode}}, "示例错误描述")
 // // )
+//
+// 定义错误码后，需要在模块的 locales/en.json 与 locales/zh.json 中补充对应的翻译，
+// 并参考 internal/user/errors.go 通过 go:embed 嵌入、在 init 中调用 xerr.MustRegisterCatalog 注册；
+// 缺少翻译或错误码重复时服务将无法启动。
 `
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/config"
+	databasepkg "github.com/Jayleonc/service/pkg/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cmd := os.Args[1]
+	args := os.Args[2:]
+
+	var err error
+	switch cmd {
+	case "export":
+		err = runExport(args)
+	case "plan":
+		err = runPlan(args, false)
+	case "apply":
+		err = runPlan(args, true)
+	case "help", "-h", "--help":
+		printUsage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "rbac-cli: unknown command %q\n\n", cmd)
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: rbac-cli <command> [flags]")
+	fmt.Println()
+	fmt.Println("Available commands:")
+	fmt.Println("  export   Write the roles stored in the database as a policy document")
+	fmt.Println("           -o <file>       output file (default stdout)")
+	fmt.Println("           -format <fmt>   yaml or json (default derived from -o, else yaml)")
+	fmt.Println("  plan     Show the changes a policy document would make")
+	fmt.Println("           -f <file>       policy document (required)")
+	fmt.Println("           -prune          also delete roles missing from the document")
+	fmt.Println("  apply    Apply a policy document to the database")
+	fmt.Println("           -f <file>       policy document (required)")
+	fmt.Println("           -prune          also delete roles missing from the document")
+	fmt.Println()
+	fmt.Println("Database settings are read from config/config.yaml and AUTH_* environment variables.")
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "rbac-cli: %v\n", err)
+	os.Exit(1)
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file")
+	format := flags.String("format", "", "yaml or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = rbac.FormatYAML
+		if *output != "" {
+			*format = rbac.FormatFromPath(*output)
+		}
+	}
+
+	ctx := context.Background()
+	svc, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+
+	doc, err := svc.ExportPolicyDocument(ctx)
+	if err != nil {
+		return fmt.Errorf("export policy document: %w", err)
+	}
+	data, err := doc.Encode(*format)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", *output, err)
+	}
+	fmt.Printf("Exported %d roles to %s\n", len(doc.Roles), *output)
+	return nil
+}
+
+func runPlan(args []string, apply bool) error {
+	name := "plan"
+	if apply {
+		name = "apply"
+	}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("f", "", "policy document")
+	prune := flags.Bool("prune", false, "delete roles missing from the document")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%s: -f <file> is required", name)
+	}
+
+	doc, err := rbac.LoadPolicyDocument(*file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	svc, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := rbac.ApplyOptions{Prune: *prune}
+	if !apply {
+		plan, err := svc.PlanPolicyDocument(ctx, doc, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Print(plan.String())
+		return nil
+	}
+
+	plan, err := svc.ApplyPolicyDocument(ctx, doc, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan.String())
+	if !plan.Empty() {
+		if err := svc.EnsureAdminHasAllPermissions(ctx); err != nil {
+			return fmt.Errorf("sync admin permissions: %w", err)
+		}
+		fmt.Println("Applied.")
+	}
+	return nil
+}
+
+// openService connects to the configured database and prepares the RBAC tables.
+func openService(ctx context.Context) (*rbac.Service, error) {
+	cfg, err := config.Load(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	db, err := databasepkg.New(databasepkg.Config{
+		Driver:   cfg.Database.Driver,
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Database: cfg.Database.Name,
+		SSLMode:  cfg.Database.SSLMode,
+		Params:   cfg.Database.Params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	svc, err := rbac.EnsureService(ctx, rbac.NewRepository(db))
+	if err != nil {
+		return nil, fmt.Errorf("ensure rbac service: %w", err)
+	}
+	return svc, nil
+}
@@ -1,21 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	application "github.com/Jayleonc/service/internal/app"
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
 )
 
 func main() {
-	app, err := application.Bootstrap()
+	err := run()
+	if err != nil {
+		slog.Error("service exited", "error", err)
+	}
+	// 日志按批写出，所有退出路径（包括启动失败）都要在进程结束前写出缓冲区。
+	_ = applogger.Close()
 	if err != nil {
-		slog.Error("failed to bootstrap application", "error", err)
-		return
+		os.Exit(1)
 	}
+}
 
-	addr := fmt.Sprintf("%s:%d", app.Config.Server.Host, app.Config.Server.Port)
-	if err := app.Engine.Run(addr); err != nil {
-		slog.Error("server exited", "error", err)
+// run 启动服务并阻塞到收到 SIGINT 或 SIGTERM，随后优雅关闭 HTTP 服务。
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app, err := application.Bootstrap()
+	if err != nil {
+		return fmt.Errorf("bootstrap application: %w", err)
 	}
+	return app.Run(ctx)
 }
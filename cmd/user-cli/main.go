@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/internal/user"
+	"github.com/Jayleonc/service/pkg/config"
+	databasepkg "github.com/Jayleonc/service/pkg/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cmd := os.Args[1]
+	args := os.Args[2:]
+
+	var err error
+	switch cmd {
+	case "import":
+		err = runImport(args)
+	case "export":
+		err = runExport(args)
+	case "help", "-h", "--help":
+		printUsage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "user-cli: unknown command %q\n\n", cmd)
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: user-cli <command> [flags]")
+	fmt.Println()
+	fmt.Println("Available commands:")
+	fmt.Println("  import   Create users from a CSV or JSONL file")
+	fmt.Println("           -f <file>       input file (required)")
+	fmt.Println("           -format <fmt>   csv or jsonl (default derived from -f)")
+	fmt.Println("           -dry-run        validate only, do not create any user")
+	fmt.Println("  export   Write all users and their roles as CSV or JSONL")
+	fmt.Println("           -o <file>       output file (default stdout)")
+	fmt.Println("           -format <fmt>   csv or jsonl (default derived from -o, else csv)")
+	fmt.Println()
+	fmt.Println("CSV files use the header name,email,phone,password,roles; separate multiple roles with ';'.")
+	fmt.Println("Database settings are read from config/config.yaml and AUTH_* environment variables.")
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "user-cli: %v\n", err)
+	os.Exit(1)
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("f", "", "input file")
+	format := flags.String("format", "", "csv or jsonl")
+	dryRun := flags.Bool("dry-run", false, "validate only")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("import: -f <file> is required")
+	}
+	if *format == "" {
+		*format = user.FormatFromPath(*file)
+	}
+	// Reject the format before connecting to the database so a typo fails fast.
+	parsed, err := user.ParseFormat(*format)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", *file, err)
+	}
+	*format = parsed
+
+	input, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", *file, err)
+	}
+	defer input.Close()
+
+	ctx := context.Background()
+	svc, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := svc.ImportUsers(ctx, input, user.ImportOptions{Format: *format, DryRun: *dryRun})
+	if err != nil {
+		return fmt.Errorf("import users: %w", err)
+	}
+
+	for _, rowErr := range report.Errors {
+		fmt.Printf("row %d\t%s\t%s\n", rowErr.Row, rowErr.Email, rowErr.Message)
+	}
+	verb := "Created"
+	if report.DryRun {
+		verb = "Validated"
+	}
+	fmt.Printf("%s %d of %d users, %d failed\n", verb, report.Succeeded, report.Total, report.Failed)
+	if report.Failed > 0 {
+		return fmt.Errorf("%d rows failed", report.Failed)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file")
+	format := flags.String("format", "", "csv or jsonl")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = user.FormatCSV
+		if inferred := user.FormatFromPath(*output); inferred != "" {
+			*format = inferred
+		}
+	}
+	// Validate before creating the output file so an unsupported format leaves nothing behind.
+	parsed, err := user.ParseFormat(*format)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	*format = parsed
+
+	ctx := context.Background()
+	svc, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := svc.ExportUsers(ctx, w, *format); err != nil {
+		return fmt.Errorf("export users: %w", err)
+	}
+	if *output != "" {
+		fmt.Printf("Exported users to %s\n", *output)
+	}
+	return nil
+}
+
+// openService connects to the configured database and prepares the user and RBAC tables.
+func openService(ctx context.Context) (*user.Service, error) {
+	cfg, err := config.Load(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	db, err := databasepkg.New(databasepkg.Config{
+		Driver:   cfg.Database.Driver,
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Database: cfg.Database.Name,
+		SSLMode:  cfg.Database.SSLMode,
+		Params:   cfg.Database.Params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	rbacService, err := rbac.EnsureService(ctx, rbac.NewRepository(db))
+	if err != nil {
+		return nil, fmt.Errorf("ensure rbac service: %w", err)
+	}
+
+	repo := user.NewRepository(db)
+	if err := repo.Migrate(ctx); err != nil {
+		return nil, fmt.Errorf("run user migrations: %w", err)
+	}
+
+	// Import and export never touch sessions, so no auth service is needed.
+	return user.NewService(repo, nil, rbacService, cfg.User), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/internal/user"
+)
+
+// TestRunImportArguments covers argument errors that are reported before any database connection is made.
+func TestRunImportArguments(t *testing.T) {
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "users.txt")
+	require.NoError(t, os.WriteFile(textFile, []byte("name,email\n"), 0o644))
+
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "missing file", args: nil, wantMsg: "import: -f <file> is required"},
+		{name: "format not derivable from extension", args: []string{"-f", textFile}, wantErr: user.ErrUnsupportedFormat},
+		{name: "unsupported explicit format", args: []string{"-f", textFile, "-format", "xlsx"}, wantErr: user.ErrUnsupportedFormat},
+		{name: "unknown flag", args: []string{"-force"}, wantMsg: "flag provided but not defined: -force"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := runImport(tc.args)
+			require.Error(t, err)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+			if tc.wantMsg != "" {
+				require.EqualError(t, err, tc.wantMsg)
+			}
+		})
+	}
+}
+
+// TestRunExportUnsupportedFormat verifies that an unsupported format fails without creating the output file.
+func TestRunExportUnsupportedFormat(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "users.xml")
+
+	err := runExport([]string{"-o", output, "-format", "xml"})
+	require.ErrorIs(t, err, user.ErrUnsupportedFormat)
+
+	_, statErr := os.Stat(output)
+	require.True(t, os.IsNotExist(statErr))
+}
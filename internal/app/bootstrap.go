@@ -4,6 +4,7 @@ import (
 	"github.com/Jayleonc/service/internal/auth"
 	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/internal/server"
+	"github.com/Jayleonc/service/internal/system"
 	"github.com/Jayleonc/service/internal/user"
 )
 
@@ -11,6 +12,7 @@ import (
 var Features = []feature.Entry{
 	{Name: "auth", Registrar: auth.Register},
 	{Name: "user", Registrar: user.Register},
+	{Name: "system", Registrar: system.Register},
 	// {Name: "rbac", Registrar: rbac.Register}, // 取消注释以启用高级RBAC插件（建议保持在列表末尾）
 }
 
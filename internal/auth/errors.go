@@ -1,12 +1,35 @@
 package auth
 
-import "github.com/Jayleonc/service/pkg/xerr"
+import (
+	"embed"
+	"errors"
+	"net/http"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
 
 // 认证模块错误码范围：1000-1999
 var (
-	ErrInvalidRefreshToken        = xerr.New(1001, "invalid refresh token")
+	ErrInvalidRefreshToken        = xerr.New(1001, "invalid refresh token").WithStatus(http.StatusUnauthorized)
 	ErrRefreshFailed              = xerr.New(1002, "failed to refresh token")
-	ErrMissingAuthorizationHeader = xerr.New(1101, "missing authorization header")
-	ErrInvalidAuthorizationHeader = xerr.New(1102, "invalid authorization header")
-	ErrInvalidToken               = xerr.New(1103, "invalid token")
+	ErrMissingAuthorizationHeader = xerr.New(1101, "missing authorization header").WithStatus(http.StatusUnauthorized)
+	ErrInvalidAuthorizationHeader = xerr.New(1102, "invalid authorization header").WithStatus(http.StatusUnauthorized)
+	ErrInvalidToken               = xerr.New(1103, "invalid token").WithStatus(http.StatusUnauthorized)
+	ErrAccountPending             = xerr.New(1201, "account is pending activation").WithStatus(http.StatusForbidden)
+	ErrAccountSuspended           = xerr.New(1202, "account is suspended").WithStatus(http.StatusForbidden)
+	ErrAccountDisabled            = xerr.New(1203, "account is disabled").WithStatus(http.StatusForbidden)
 )
+
+// IsAccountStatusError 判断错误是否表示账户状态不允许继续访问。
+func IsAccountStatusError(err error) bool {
+	return errors.Is(err, ErrAccountPending) || errors.Is(err, ErrAccountSuspended) || errors.Is(err, ErrAccountDisabled)
+}
+
+// locales 保存认证模块错误码的中英文消息。
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	xerr.MustRegisterCatalog(locales, "locales")
+}
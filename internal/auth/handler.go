@@ -1,9 +1,6 @@
 package auth
 
 import (
-	"errors"
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 
 	"github.com/Jayleonc/service/internal/feature"
@@ -43,17 +40,13 @@ type refreshResponse struct {
 func (h *Handler) refresh(c *gin.Context) {
 	var req refreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	tokens, err := h.svc.Refresh(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		if errors.Is(err, ErrInvalidRefreshToken) {
-			response.Error(c, http.StatusUnauthorized, ErrInvalidRefreshToken)
-			return
-		}
-		response.Error(c, http.StatusInternalServerError, ErrRefreshFailed)
+		response.Fail(c, xerr.FromOr(err, ErrRefreshFailed))
 		return
 	}
 
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Jayleonc/service/pkg/observe/metrics"
+)
+
+var tokenRefreshesTotal = metrics.Register(prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "auth_token_refreshes_total",
+		Help: "Refresh token exchanges by outcome.",
+	},
+	[]string{"outcome"},
+))
+
+// refreshOutcome 将刷新结果归类为 success、invalid_token、account_inactive 或 error。
+func refreshOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrInvalidRefreshToken):
+		return "invalid_token"
+	case IsAccountStatusError(err):
+		return "account_inactive"
+	default:
+		return "error"
+	}
+}
@@ -1,10 +1,11 @@
 package auth
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/pkg/ginx/response"
@@ -15,26 +16,34 @@ func AuthenticatedMiddleware(service *Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
 		if header == "" {
-			response.Error(c, http.StatusUnauthorized, ErrMissingAuthorizationHeader)
+			response.Fail(c, ErrMissingAuthorizationHeader)
 			c.Abort()
 			return
 		}
 
 		parts := strings.SplitN(header, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
-			response.Error(c, http.StatusUnauthorized, ErrInvalidAuthorizationHeader)
+			response.Fail(c, ErrInvalidAuthorizationHeader)
 			c.Abort()
 			return
 		}
 
 		session, err := service.Validate(c.Request.Context(), parts[1])
 		if err != nil {
-			response.Error(c, http.StatusUnauthorized, ErrInvalidToken)
+			log.Debug(c.Request.Context(), "authentication rejected", "path", c.FullPath(), "error", err)
+			if IsAccountStatusError(err) {
+				response.Fail(c, err)
+				c.Abort()
+				return
+			}
+			response.Fail(c, ErrInvalidToken)
 			c.Abort()
 			return
 		}
 
 		feature.SetAuthContext(c, session)
+		// 在请求 Span 上标记用户，便于按用户检索链路。
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(semconv.EnduserID(session.UserID.String()))
 		c.Next()
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/internal/middleware"
 	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/observe/logger"
 )
 
 var (
@@ -17,6 +18,9 @@ var (
 	service   *Service
 )
 
+// log 是认证模块的日志器，调到 debug 可查看被拒绝的认证请求及原因。
+var log = logger.Module("auth")
+
 // Register 以结构化/依赖注入方式初始化认证特性。
 func Register(ctx context.Context, deps *feature.Dependencies) error {
 	if deps.Auth == nil {
@@ -23,11 +23,17 @@ type Tokens struct {
 	ExpiresIn    time.Duration
 }
 
+// AccountChecker 判断用户账户当前是否允许使用会话，返回的错误会透传给调用方。
+type AccountChecker interface {
+	CheckAccount(ctx context.Context, userID uuid.UUID) error
+}
+
 // Service 基于 Redis 会话存储，负责无状态 JWT 的签发与校验。
 type Service struct {
 	manager    *authpkg.Manager
 	store      *SessionStore
 	refreshTTL time.Duration
+	checker    AccountChecker
 }
 
 // NewService 构造 Service 实例。
@@ -39,6 +45,12 @@ func NewService(manager *authpkg.Manager, store *SessionStore) *Service {
 	}
 }
 
+// SetAccountChecker 注册账户状态校验器。Refresh 在轮换令牌前调用它；Validate 只在会话不存在时调用，
+// 正常请求依赖暂停、禁用账户时注销全部会话，不为每个请求查询账户状态。
+func (s *Service) SetAccountChecker(checker AccountChecker) {
+	s.checker = checker
+}
+
 // IssueTokens 创建新的认证会话并返回令牌对。
 func (s *Service) IssueTokens(ctx context.Context, userID uuid.UUID, roles []string) (Tokens, error) {
 	// 生成访问令牌和刷新令牌需要独立的随机标识符，保证每次登录互不干扰。
@@ -70,11 +82,16 @@ func (s *Service) IssueTokens(ctx context.Context, userID uuid.UUID, roles []str
 }
 
 // Refresh 根据旧的刷新令牌生成新的访问令牌，并同时轮换刷新令牌。
-func (s *Service) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (_ Tokens, err error) {
+	defer func() { tokenRefreshesTotal.WithLabelValues(refreshOutcome(err)).Inc() }()
+
 	session, err := s.store.GetByRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return Tokens{}, err
 	}
+	if err := s.checkAccount(ctx, session.UserID); err != nil {
+		return Tokens{}, err
+	}
 
 	// 为防止刷新令牌被重放，每次刷新都生成新的随机值并立即替换旧值。
 	newRefreshToken := uuid.NewString()
@@ -96,6 +113,12 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (Tokens, err
 	}, nil
 }
 
+// RevokeUserSessions 注销用户的全部会话，已签发的访问令牌将在下一次校验时失效。
+func (s *Service) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.store.DeleteUserSessions(ctx, userID)
+	return err
+}
+
 // Validate 根据访问令牌解析出会话上下文。
 func (s *Service) Validate(ctx context.Context, token string) (feature.AuthContext, error) {
 	claims, err := s.manager.ParseToken(token)
@@ -103,11 +126,29 @@ func (s *Service) Validate(ctx context.Context, token string) (feature.AuthConte
 		return feature.AuthContext{}, err
 	}
 
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return feature.AuthContext{}, ErrInvalidToken
+	}
+
 	// 结合会话 ID 从 Redis 读取完整上下文，确保权限信息实时可控。
 	session, err := s.store.Get(ctx, claims.SessionID)
 	if err != nil {
+		// 暂停或禁用账户时其会话已被注销，此时补查账户状态，返回明确的状态错误而不是会话失效。
+		if errors.Is(err, ErrSessionNotFound) {
+			if err := s.checkAccount(ctx, userID); err != nil {
+				return feature.AuthContext{}, err
+			}
+		}
 		return feature.AuthContext{}, err
 	}
 
 	return session, nil
 }
+
+func (s *Service) checkAccount(ctx context.Context, userID uuid.UUID) error {
+	if s.checker == nil {
+		return nil
+	}
+	return s.checker.CheckAccount(ctx, userID)
+}
@@ -36,6 +36,10 @@ func (s *SessionStore) refreshKey(token string) string {
 	return fmt.Sprintf("refresh:%s", token)
 }
 
+func (s *SessionStore) userKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user_sessions:%s", userID)
+}
+
 // Save 保存新的会话数据及其刷新令牌映射关系。
 func (s *SessionStore) Save(ctx context.Context, data feature.AuthContext, ttl time.Duration) error {
 	payload := sessionPayload{
@@ -52,6 +56,9 @@ func (s *SessionStore) Save(ctx context.Context, data feature.AuthContext, ttl t
 	pipe := s.client.TxPipeline()
 	pipe.Set(ctx, s.sessionKey(data.SessionID), raw, ttl)
 	pipe.Set(ctx, s.refreshKey(data.RefreshToken), data.SessionID, ttl)
+	// 维护用户到会话的索引，便于角色变更时批量注销。
+	pipe.SAdd(ctx, s.userKey(data.UserID), data.SessionID)
+	pipe.Expire(ctx, s.userKey(data.UserID), ttl)
 
 	_, err = pipe.Exec(ctx)
 	return err
@@ -126,7 +133,38 @@ func (s *SessionStore) ReplaceRefreshToken(ctx context.Context, data feature.Aut
 	if previousToken != "" {
 		pipe.Del(ctx, s.refreshKey(previousToken))
 	}
+	pipe.SAdd(ctx, s.userKey(data.UserID), data.SessionID)
+	pipe.Expire(ctx, s.userKey(data.UserID), ttl)
 
 	_, err = pipe.Exec(ctx)
 	return err
 }
+
+// DeleteUserSessions 删除用户的全部会话及其刷新令牌，返回被删除的会话数量。
+func (s *SessionStore) DeleteUserSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	sessionIDs, err := s.client.SMembers(ctx, s.userKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	pipe := s.client.TxPipeline()
+	for _, sessionID := range sessionIDs {
+		session, err := s.Get(ctx, sessionID)
+		if err != nil {
+			if err == ErrSessionNotFound {
+				continue
+			}
+			return 0, err
+		}
+		pipe.Del(ctx, s.sessionKey(sessionID))
+		if session.RefreshToken != "" {
+			pipe.Del(ctx, s.refreshKey(session.RefreshToken))
+		}
+	}
+	pipe.Del(ctx, s.userKey(userID))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return len(sessionIDs), nil
+}
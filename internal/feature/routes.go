@@ -2,12 +2,22 @@ package feature
 
 import "github.com/gin-gonic/gin"
 
+const contextAuthorizesResourceKey = "route.authorizes_resource"
+
 // RouteDefinition 定义了最基础的路由信息
 type RouteDefinition struct {
 	Path    string
 	Handler gin.HandlerFunc
 	// RequiredPermission declares the RBAC permission necessary to access this route.
 	RequiredPermission string
+	// AuthorizesResource declares that Handler loads the resource and calls rbac.Service.Authorize
+	// for RequiredPermission. Only such routes may be admitted by an allow policy whose conditions
+	// reference resource attributes; on other routes that allow is treated as a denial.
+	AuthorizesResource bool
+	// SkipIdempotency opts the route out of Idempotency-Key handling. Use it for uploads that
+	// should not be buffered in memory and for responses carrying secrets or bulk data that must
+	// not be kept in the idempotency store.
+	SkipIdempotency bool
 }
 
 // ModuleRoutes 是一个功能对外暴露的、按权限划分的路由清单
@@ -16,3 +26,13 @@ type ModuleRoutes struct {
 	AuthenticatedRoutes []RouteDefinition
 	AdminRoutes         []RouteDefinition
 }
+
+// MarkAuthorizesResource 标记当前路由的 handler 会调用 Authorize 完成资源级校验，由路由注册时挂在权限中间件之前。
+func MarkAuthorizesResource(c *gin.Context) {
+	c.Set(contextAuthorizesResourceKey, true)
+}
+
+// AuthorizesResource 报告当前路由是否声明了资源级校验。
+func AuthorizesResource(c *gin.Context) bool {
+	return c.GetBool(contextAuthorizesResourceKey)
+}
@@ -1,12 +1,16 @@
 package feature
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 const contextAuthContextKey = "auth.context"
 
+type authContextKey struct{}
+
 // AuthContext 描述功能模块之间共享的认证上下文。
 type AuthContext struct {
 	SessionID    string
@@ -15,9 +19,12 @@ type AuthContext struct {
 	RefreshToken string
 }
 
-// SetAuthContext 将认证上下文写入 Gin Context。
+// SetAuthContext 将认证上下文写入 Gin Context，并同步写入请求的标准 context 供服务层读取。
 func SetAuthContext(c *gin.Context, ctx AuthContext) {
 	c.Set(contextAuthContextKey, ctx)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(ContextWithAuthContext(c.Request.Context(), ctx))
+	}
 }
 
 // GetAuthContext 从 Gin Context 中读取认证上下文。
@@ -43,3 +50,20 @@ func MustGetAuthContext(c *gin.Context) AuthContext {
 	}
 	return session
 }
+
+// ContextWithAuthContext 将认证上下文写入标准 context。
+func ContextWithAuthContext(ctx context.Context, auth AuthContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, authContextKey{}, auth)
+}
+
+// AuthContextFromContext 从标准 context 中读取认证上下文。
+func AuthContextFromContext(ctx context.Context) (AuthContext, bool) {
+	if ctx == nil {
+		return AuthContext{}, false
+	}
+	auth, ok := ctx.Value(authContextKey{}).(AuthContext)
+	return auth, ok
+}
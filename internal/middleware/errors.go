@@ -1,9 +1,27 @@
 package middleware
 
-import "github.com/Jayleonc/service/pkg/xerr"
+import (
+	"embed"
+	"net/http"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
 
 // 中间件模块错误码范围：4000-4999
 var (
-	ErrMissingSession        = xerr.New(4001, "missing session")
-	ErrInsufficientPrivilege = xerr.New(4002, "insufficient permissions")
+	ErrMissingSession         = xerr.New(4001, "missing session").WithStatus(http.StatusUnauthorized)
+	ErrInsufficientPrivilege  = xerr.New(4002, "insufficient permissions").WithStatus(http.StatusForbidden)
+	ErrInvalidIdempotencyKey  = xerr.New(4003, "invalid idempotency key").WithStatus(http.StatusBadRequest)
+	ErrIdempotencyKeyReused   = xerr.New(4004, "idempotency key was already used with a different request").WithStatus(http.StatusUnprocessableEntity)
+	ErrIdempotencyInProgress  = xerr.New(4005, "a request with this idempotency key is still in progress").WithStatus(http.StatusConflict)
+	ErrIdempotentBodyTooLarge = xerr.New(4006, "request body is too large for an idempotent request").WithStatus(http.StatusRequestEntityTooLarge)
 )
+
+// locales 保存中间件错误码的本地化消息。
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	xerr.MustRegisterCatalog(locales, "locales")
+}
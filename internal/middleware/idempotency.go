@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+	"github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+const (
+	// IdempotencyKeyHeader 是客户端携带幂等键的请求头。
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 标记响应是对先前结果的重放。
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	idempotencyKeyPrefix    = "idempotency:"
+)
+
+// replayedHeaders 列出随响应一起保存并在重放时写回的响应头。
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "ETag", "Location"}
+
+// IdempotencyConfig 控制幂等中间件的存储与保留时间。
+type IdempotencyConfig struct {
+	Store IdempotencyStore
+	// TTL 为已完成响应的保留时间，窗口内携带同一幂等键的请求直接重放该响应。
+	TTL time.Duration
+	// LockTTL 为处理中记录的最长保留时间，防止进程崩溃后幂等键被永久占用。
+	LockTTL time.Duration
+	// MaxBodyBytes 为可保存的最大响应体，超出时不保存结果，客户端可使用同一个键重试。
+	MaxBodyBytes int
+	// MaxRequestBytes 为携带幂等键的请求体上限，计算指纹需要将请求体读入内存，超出时返回 413。
+	MaxRequestBytes int64
+}
+
+// Idempotency 为携带 Idempotency-Key 请求头的请求提供幂等保证：
+//   - 首个请求正常处理，响应连同请求指纹一起保存 TTL 时长；5xx 与 retryableStatus 中的状态码不保存；
+//   - 指纹相同的重复请求直接重放保存的响应，并附带 Idempotent-Replayed: true；
+//   - 同一个键搭配不同的请求体返回 422；
+//   - 首个请求仍在处理时，重复请求返回 409 并提示稍后重试；
+//   - 请求体超过 MaxRequestBytes 时返回 413，不读入剩余部分。
+//
+// 幂等键按认证主体（用户 ID）与路由路径隔离，因此中间件必须挂在认证中间件之后；
+// 没有认证上下文的请求（公开接口）以及未携带请求头的请求不受影响。
+func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+	if cfg.MaxRequestBytes <= 0 {
+		cfg.MaxRequestBytes = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		session, authenticated := feature.GetAuthContext(c)
+		if key == "" || cfg.Store == nil || !authenticated {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.Fail(c, ErrInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxRequestBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				response.Fail(c, ErrIdempotentBodyTooLarge)
+			} else {
+				response.Fail(c, ErrInvalidIdempotencyKey.WithMessage("failed to read request body"))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := idempotencyStoreKey(session.UserID.String(), c.Request.URL.Path, key)
+		fingerprint := idempotencyFingerprint(c, body)
+
+		existing, acquired, err := cfg.Store.Acquire(ctx, storeKey, IdempotencyRecord{Fingerprint: fingerprint}, cfg.LockTTL)
+		if err != nil {
+			// 存储不可用时放行请求，避免幂等能力成为单点故障。
+			logger.Error(ctx, "idempotency store unavailable", "error", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				response.Fail(c, ErrIdempotencyKeyReused)
+			case !existing.Completed:
+				c.Header("Retry-After", strconv.Itoa(1))
+				response.Fail(c, ErrIdempotencyInProgress)
+			default:
+				replayIdempotentResponse(c, existing)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer, limit: cfg.MaxBodyBytes}
+		c.Writer = recorder
+
+		defer func() {
+			// panic、服务端错误或请求未被处理时释放幂等键，允许客户端重试；Recovery 中间件负责记录 panic。
+			status := recorder.Status()
+			if rec := recover(); rec != nil {
+				_ = cfg.Store.Release(ctx, storeKey)
+				panic(rec)
+			}
+			if !storableStatus(status) || recorder.overflow {
+				if err := cfg.Store.Release(ctx, storeKey); err != nil {
+					logger.Error(ctx, "release idempotency key failed", "error", err)
+				}
+				return
+			}
+
+			record := IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Status:      status,
+				Header:      make(map[string]string, len(replayedHeaders)),
+				Body:        recorder.body.Bytes(),
+			}
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					record.Header[name] = value
+				}
+			}
+			if err := cfg.Store.Save(ctx, storeKey, record, cfg.TTL); err != nil {
+				logger.Error(ctx, "save idempotent response failed", "error", err)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// retryableStatus 列出表示请求未被处理、客户端修正后应当可以用同一个键重试的状态码，这类响应不保存。
+var retryableStatus = map[int]struct{}{
+	http.StatusBadRequest:          {},
+	http.StatusUnauthorized:        {},
+	http.StatusForbidden:           {},
+	http.StatusRequestTimeout:      {},
+	http.StatusUnprocessableEntity: {},
+	http.StatusTooManyRequests:     {},
+}
+
+// storableStatus 判断响应是否应当保存并在之后重放。
+func storableStatus(status int) bool {
+	if status >= http.StatusInternalServerError {
+		return false
+	}
+	_, retryable := retryableStatus[status]
+	return !retryable
+}
+
+// idempotencyStoreKey 以认证主体、路由路径与幂等键生成存储键。
+func idempotencyStoreKey(subject, path, key string) string {
+	h := sha256.New()
+	h.Write([]byte(subject))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return idempotencyKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyFingerprint 计算请求方法、路径、Content-Type 与请求体的摘要。
+func idempotencyFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(c.ContentType()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotentResponse(c *gin.Context, record *IdempotencyRecord) {
+	for name, value := range record.Header {
+		c.Header(name, value)
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+}
+
+// idempotencyRecorder 在写出响应的同时缓存响应体，超过 limit 后停止缓存并标记 overflow。
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *idempotencyRecorder) capture(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
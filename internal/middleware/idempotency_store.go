@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyRecord 记录一次幂等请求的指纹与处理结果。Completed 为 false 表示请求仍在处理中。
+type IdempotencyRecord struct {
+	Fingerprint string            `json:"fingerprint"`
+	Completed   bool              `json:"completed"`
+	Status      int               `json:"status,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+// IdempotencyStore 持久化幂等键对应的记录。
+type IdempotencyStore interface {
+	// Acquire 在键不存在时写入处理中记录并返回 acquired=true；键已存在时返回已有记录。
+	Acquire(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (existing *IdempotencyRecord, acquired bool, err error)
+	// Save 写入已完成的记录，覆盖处理中记录。
+	Save(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release 删除记录，使客户端可以使用同一个键重试。
+	Release(ctx context.Context, key string) error
+}
+
+// RedisIdempotencyStore 基于 Redis 保存幂等记录，适用于多实例部署。
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewRedisIdempotencyStore 创建 RedisIdempotencyStore 实例。
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+// Acquire 使用 SETNX 抢占幂等键。
+func (s *RedisIdempotencyStore) Acquire(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 已有记录可能恰好在 SETNX 与 GET 之间过期，此时重试一次抢占。
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.client.SetNX(ctx, key, raw, ttl).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if acquired {
+			return nil, true, nil
+		}
+
+		stored, err := s.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		var existing IdempotencyRecord
+		if err := json.Unmarshal(stored, &existing); err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+	return nil, false, errors.New("idempotency: failed to acquire key")
+}
+
+// Save 写入已完成的记录。
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, raw, ttl).Err()
+}
+
+// Release 删除幂等记录。
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore 在进程内存中保存幂等记录，仅适用于单实例部署与本地开发。
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryIdempotencyStore 创建 MemoryIdempotencyStore 实例。
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]memoryIdempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// Acquire 在键不存在或已过期时写入处理中记录。
+func (s *MemoryIdempotencyStore) Acquire(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		existing := entry.record
+		return &existing, false, nil
+	}
+	s.entries[key] = memoryIdempotencyEntry{record: record, expiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+// Save 写入已完成的记录。
+func (s *MemoryIdempotencyStore) Save(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryIdempotencyEntry{record: record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Release 删除幂等记录。
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep 每分钟最多清理一次过期记录，调用方需持有锁。
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
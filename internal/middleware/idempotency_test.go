@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/internal/feature"
+)
+
+// idempotencyHarness 在 gin 引擎上挂载认证上下文与幂等中间件，并记录处理器的执行次数。
+type idempotencyHarness struct {
+	engine *gin.Engine
+	store  *MemoryIdempotencyStore
+	calls  int
+	status int
+}
+
+func newIdempotencyHarness(t *testing.T) *idempotencyHarness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	h := &idempotencyHarness{engine: gin.New(), store: NewMemoryIdempotencyStore(), status: http.StatusCreated}
+	h.engine.Use(func(c *gin.Context) {
+		if raw := c.GetHeader("X-Test-User"); raw != "" {
+			feature.SetAuthContext(c, feature.AuthContext{UserID: uuid.MustParse(raw)})
+		}
+		c.Next()
+	})
+	h.engine.Use(Idempotency(IdempotencyConfig{Store: h.store}))
+	h.engine.POST("/orders", func(c *gin.Context) {
+		h.calls++
+		c.Header("Location", "/orders/1")
+		c.JSON(h.status, gin.H{"call": h.calls})
+	})
+	return h
+}
+
+func (h *idempotencyHarness) do(user uuid.UUID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != uuid.Nil {
+		req.Header.Set("X-Test-User", user.String())
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	h.engine.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotencyReplay 验证相同请求重放首次响应，处理器只执行一次。
+func TestIdempotencyReplay(t *testing.T) {
+	h := newIdempotencyHarness(t)
+	user := uuid.New()
+
+	first := h.do(user, "key-1", `{"sku":"a"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	second := h.do(user, "key-1", `{"sku":"a"}`)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	require.Equal(t, "/orders/1", second.Header().Get("Location"))
+	require.JSONEq(t, first.Body.String(), second.Body.String())
+	require.Equal(t, 1, h.calls)
+}
+
+// TestIdempotencyFingerprintMismatch 验证同一个键搭配不同请求体返回 422。
+func TestIdempotencyFingerprintMismatch(t *testing.T) {
+	h := newIdempotencyHarness(t)
+	user := uuid.New()
+
+	require.Equal(t, http.StatusCreated, h.do(user, "key-1", `{"sku":"a"}`).Code)
+	w := h.do(user, "key-1", `{"sku":"b"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Contains(t, w.Body.String(), `"code":4004`)
+	require.Equal(t, 1, h.calls)
+}
+
+// TestIdempotencyInFlight 验证首个请求仍在处理时，重复请求返回 409 并带上 Retry-After。
+func TestIdempotencyInFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryIdempotencyStore()
+	user := uuid.New()
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		feature.SetAuthContext(c, feature.AuthContext{UserID: user})
+		c.Next()
+	})
+	engine.Use(Idempotency(IdempotencyConfig{Store: store}))
+	engine.POST("/orders", func(c *gin.Context) {
+		close(started)
+		<-finish
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		return req
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, newRequest())
+		done <- w
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newRequest())
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(finish)
+	require.Equal(t, http.StatusCreated, (<-done).Code)
+}
+
+// TestIdempotencyReleaseUnstoredResponses 验证服务端错误与表示请求未被处理的 4xx 不保存，客户端可用同一个键重试。
+func TestIdempotencyReleaseUnstoredResponses(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+	}{
+		{name: "服务端错误", status: http.StatusInternalServerError},
+		{name: "未认证", status: http.StatusUnauthorized},
+		{name: "无权限", status: http.StatusForbidden},
+		{name: "参数校验失败", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newIdempotencyHarness(t)
+			user := uuid.New()
+
+			h.status = tc.status
+			require.Equal(t, tc.status, h.do(user, "key-1", `{}`).Code)
+
+			h.status = http.StatusCreated
+			w := h.do(user, "key-1", `{}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+			require.Equal(t, 2, h.calls)
+		})
+	}
+}
+
+// TestIdempotencyScopedBySubject 验证幂等键按认证用户隔离，未认证的请求不参与幂等处理。
+func TestIdempotencyScopedBySubject(t *testing.T) {
+	h := newIdempotencyHarness(t)
+	alice, bob := uuid.New(), uuid.New()
+
+	require.JSONEq(t, `{"call":1}`, h.do(alice, "shared", `{}`).Body.String())
+
+	w := h.do(bob, "shared", `{}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	require.JSONEq(t, `{"call":2}`, w.Body.String())
+
+	anonymous := h.do(uuid.Nil, "shared", `{}`)
+	require.Empty(t, anonymous.Header().Get(IdempotentReplayedHeader))
+	require.JSONEq(t, `{"call":3}`, anonymous.Body.String())
+	require.Equal(t, 3, h.calls)
+
+	_, acquired, err := h.store.Acquire(context.Background(), idempotencyStoreKey(alice.String(), "/orders", "shared"), IdempotencyRecord{}, time.Minute)
+	require.NoError(t, err)
+	require.False(t, acquired)
+}
+
+// TestIdempotencyRequestTooLarge 验证携带幂等键的请求体超过上限时返回 413 且不占用幂等键，未携带幂等键的请求不受限制。
+func TestIdempotencyRequestTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryIdempotencyStore()
+	user := uuid.New()
+
+	calls := 0
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		feature.SetAuthContext(c, feature.AuthContext{UserID: user})
+		c.Next()
+	})
+	engine.Use(Idempotency(IdempotencyConfig{Store: store, MaxRequestBytes: 16}))
+	engine.POST("/orders", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	do := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	large := `{"sku":"abcdefghij"}`
+	w := do("key-1", large)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Contains(t, w.Body.String(), `"code":4006`)
+	require.Zero(t, calls)
+
+	require.Equal(t, http.StatusCreated, do("", large).Code)
+	require.Equal(t, http.StatusCreated, do("key-1", `{}`).Code)
+	require.Equal(t, 2, calls)
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -29,7 +28,7 @@ func RBAC(requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		session, ok := feature.GetAuthContext(c)
 		if !ok {
-			response.Error(c, http.StatusUnauthorized, ErrMissingSession)
+			response.Fail(c, ErrMissingSession)
 			c.Abort()
 			return
 		}
@@ -40,7 +39,7 @@ func RBAC(requiredRoles ...string) gin.HandlerFunc {
 		}
 
 		if !hasAnyRole(session.Roles, normalized) {
-			response.Error(c, http.StatusForbidden, ErrInsufficientPrivilege)
+			response.Fail(c, ErrInsufficientPrivilege)
 			c.Abort()
 			return
 		}
@@ -0,0 +1,207 @@
+package rbac
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Jayleonc/service/internal/feature"
+)
+
+// UserRole is the user_role join table. Besides linking a user to a role it records an
+// optional validity window and who granted the role and why.
+//
+// The user module registers it as the join table of User.Roles; assignments without
+// StartsAt/ExpiresAt are permanent.
+type UserRole struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"userId"`
+	RoleID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"roleId"`
+	StartsAt  *time.Time `gorm:"index" json:"startsAt,omitempty"`
+	ExpiresAt *time.Time `gorm:"index" json:"expiresAt,omitempty"`
+	Reason    string     `gorm:"size:512" json:"reason,omitempty"`
+	GrantedBy *uuid.UUID `gorm:"type:uuid" json:"grantedBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName overrides the default table name.
+func (UserRole) TableName() string {
+	return "user_role"
+}
+
+// ActiveAt reports whether the assignment is in effect at t.
+func (a UserRole) ActiveAt(t time.Time) bool {
+	if a.StartsAt != nil && a.StartsAt.After(t) {
+		return false
+	}
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(t) {
+		return false
+	}
+	return true
+}
+
+// ActiveAssignmentScope restricts a user_role query to assignments in effect at now.
+func ActiveAssignmentScope(now time.Time) func(*gorm.DB) *gorm.DB {
+	now = now.UTC()
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("user_role.starts_at IS NULL OR user_role.starts_at <= ?", now).
+			Where("user_role.expires_at IS NULL OR user_role.expires_at > ?", now)
+	}
+}
+
+// RoleAssignment is a user_role row enriched with the role name.
+type RoleAssignment struct {
+	UserRole
+	Role string `json:"role"`
+}
+
+// GrantRoleInput defines the payload for granting a (possibly temporary) role to a user.
+type GrantRoleInput struct {
+	UserID    uuid.UUID  `json:"userId" validate:"required"`
+	Role      string     `json:"role" validate:"required"`
+	StartsAt  *time.Time `json:"startsAt" validate:"omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt" validate:"omitempty"`
+	Reason    string     `json:"reason" validate:"omitempty,max=512"`
+}
+
+// RevokeRoleInput defines the payload for removing a role from a user.
+type RevokeRoleInput struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
+	Role   string    `json:"role" validate:"required"`
+}
+
+// FindActiveUserRoleIDs returns the roles directly assigned to the user that are in effect at now.
+func (r *Repository) FindActiveUserRoleIDs(ctx context.Context, userID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&UserRole{}).
+		Scopes(ActiveAssignmentScope(now)).
+		Where("user_id = ?", userID).
+		Pluck("role_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SaveUserRole creates or updates a role assignment.
+func (r *Repository) SaveUserRole(ctx context.Context, assignment *UserRole) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"starts_at", "expires_at", "reason", "granted_by"}),
+	}).Create(assignment).Error
+}
+
+// DeleteUserRole removes a role assignment.
+func (r *Repository) DeleteUserRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&UserRole{}, "user_id = ? AND role_id = ?", userID, roleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListExpiringUserRoles returns assignments that expire in the (from, until] window, soonest first.
+func (r *Repository) ListExpiringUserRoles(ctx context.Context, from, until time.Time) ([]RoleAssignment, error) {
+	var assignments []RoleAssignment
+	err := r.db.WithContext(ctx).
+		Model(&UserRole{}).
+		Select("user_role.*, role.name AS role").
+		Joins("JOIN role ON role.id = user_role.role_id").
+		Where("user_role.expires_at > ? AND user_role.expires_at <= ?", from, until).
+		Order("user_role.expires_at ASC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// DeleteExpiredUserRoles removes every assignment that expired at or before now and returns them.
+func (r *Repository) DeleteExpiredUserRoles(ctx context.Context, now time.Time) ([]UserRole, error) {
+	var expired []UserRole
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&expired).Error; err != nil {
+			return err
+		}
+		for _, assignment := range expired {
+			if err := tx.Delete(&UserRole{}, "user_id = ? AND role_id = ?", assignment.UserID, assignment.RoleID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// GrantRole assigns a role to a user, optionally limited to a time window. Granting a role the
+// user already has replaces the window, reason and grantor of the existing assignment.
+func (s *Service) GrantRole(ctx context.Context, input GrantRoleInput) (*RoleAssignment, error) {
+	if input.StartsAt != nil && input.ExpiresAt != nil && !input.ExpiresAt.After(*input.StartsAt) {
+		return nil, ErrInvalidAssignment
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAssignment
+	}
+
+	role, err := s.repo.FindRoleByName(ctx, NormalizeRoleName(input.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := UserRole{
+		UserID:    input.UserID,
+		RoleID:    role.ID,
+		StartsAt:  utcTime(input.StartsAt),
+		ExpiresAt: utcTime(input.ExpiresAt),
+		Reason:    strings.TrimSpace(input.Reason),
+	}
+	if subject, ok := feature.AuthContextFromContext(ctx); ok {
+		grantor := subject.UserID
+		assignment.GrantedBy = &grantor
+	}
+
+	if err := s.repo.SaveUserRole(ctx, &assignment); err != nil {
+		return nil, err
+	}
+	return &RoleAssignment{UserRole: assignment, Role: role.Name}, nil
+}
+
+// RevokeRole removes a role from a user.
+func (s *Service) RevokeRole(ctx context.Context, input RevokeRoleInput) error {
+	role, err := s.repo.FindRoleByName(ctx, NormalizeRoleName(input.Role))
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteUserRole(ctx, input.UserID, role.ID)
+}
+
+// ListExpiringAssignments returns the time-bound assignments that expire within the next window.
+func (s *Service) ListExpiringAssignments(ctx context.Context, within time.Duration) ([]RoleAssignment, error) {
+	now := time.Now().UTC()
+	return s.repo.ListExpiringUserRoles(ctx, now, now.Add(within))
+}
+
+// ExpireAssignments deletes the assignments that expired at or before now and returns them so
+// callers can invalidate the sessions of the affected users.
+func (s *Service) ExpireAssignments(ctx context.Context, now time.Time) ([]UserRole, error) {
+	return s.repo.DeleteExpiredUserRoles(ctx, now.UTC())
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
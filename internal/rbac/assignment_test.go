@@ -0,0 +1,218 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestUserRoleActiveAt 覆盖分配生效窗口的边界。
+func TestUserRoleActiveAt(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		assignment UserRole
+		want       bool
+	}{
+		{name: "永久分配", assignment: UserRole{}, want: true},
+		{name: "已生效未过期", assignment: UserRole{StartsAt: &past, ExpiresAt: &future}, want: true},
+		{name: "尚未生效", assignment: UserRole{StartsAt: &future}, want: false},
+		{name: "已过期", assignment: UserRole{ExpiresAt: &past}, want: false},
+		{name: "恰好过期", assignment: UserRole{ExpiresAt: &now}, want: false},
+		{name: "恰好生效", assignment: UserRole{StartsAt: &now}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.assignment.ActiveAt(now))
+		})
+	}
+}
+
+// TestRepositoryFindUserRoleIDsIgnoresInactive 确认过期和未生效的分配不会参与权限计算。
+func TestRepositoryFindUserRoleIDsIgnoresInactive(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		userID := uuid.New()
+		now := time.Now().UTC()
+		past := now.Add(-time.Hour)
+		future := now.Add(time.Hour)
+
+		permanent := &Role{ID: uuid.New(), Name: "PERMANENT"}
+		temporary := &Role{ID: uuid.New(), Name: "TEMPORARY"}
+		expired := &Role{ID: uuid.New(), Name: "EXPIRED"}
+		pending := &Role{ID: uuid.New(), Name: "PENDING"}
+		for _, role := range []*Role{permanent, temporary, expired, pending} {
+			require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+		}
+
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: permanent.ID}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: temporary.ID, StartsAt: &past, ExpiresAt: &future}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: expired.ID, ExpiresAt: &past}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: pending.ID, StartsAt: &future}))
+
+		ids, err := repo.FindUserRoleIDs(ctx, userID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{permanent.ID, temporary.ID}, ids)
+	})
+}
+
+// TestRepositorySaveUserRoleUpsert 验证重复授予会更新已有分配的窗口。
+func TestRepositorySaveUserRoleUpsert(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		role := &Role{ID: uuid.New(), Name: "ONCALL"}
+		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+
+		userID := uuid.New()
+		first := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+		second := first.Add(24 * time.Hour)
+
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: role.ID, ExpiresAt: &first, Reason: "值班"}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: role.ID, ExpiresAt: &second, Reason: "延长值班"}))
+
+		var stored []UserRole
+		require.NoError(t, tx.WithContext(ctx).Find(&stored, "user_id = ?", userID).Error)
+		require.Len(t, stored, 1)
+		require.NotNil(t, stored[0].ExpiresAt)
+		require.True(t, second.Equal(*stored[0].ExpiresAt))
+		require.Equal(t, "延长值班", stored[0].Reason)
+	})
+}
+
+// TestRepositoryExpiringAndExpiredUserRoles 覆盖即将过期查询与过期清理。
+func TestRepositoryExpiringAndExpiredUserRoles(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		role := &Role{ID: uuid.New(), Name: "AUDITOR"}
+		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+
+		now := time.Now().UTC()
+		past := now.Add(-time.Minute)
+		soon := now.Add(time.Hour)
+		later := now.Add(30 * 24 * time.Hour)
+
+		expiredUser, soonUser, laterUser, permanentUser := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: expiredUser, RoleID: role.ID, ExpiresAt: &past}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: soonUser, RoleID: role.ID, ExpiresAt: &soon}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: laterUser, RoleID: role.ID, ExpiresAt: &later}))
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: permanentUser, RoleID: role.ID}))
+
+		expiring, err := repo.ListExpiringUserRoles(ctx, now, now.Add(24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, expiring, 1)
+		require.Equal(t, soonUser, expiring[0].UserID)
+		require.Equal(t, "AUDITOR", expiring[0].Role)
+
+		removed, err := repo.DeleteExpiredUserRoles(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, removed, 1)
+		require.Equal(t, expiredUser, removed[0].UserID)
+
+		var remaining int64
+		require.NoError(t, tx.WithContext(ctx).Model(&UserRole{}).Count(&remaining).Error)
+		require.EqualValues(t, 3, remaining)
+	})
+}
+
+// TestRepositoryDeleteUserRole 验证撤销不存在的分配返回未找到。
+func TestRepositoryDeleteUserRole(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		role := &Role{ID: uuid.New(), Name: "SUPPORT"}
+		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+
+		userID := uuid.New()
+		require.NoError(t, repo.SaveUserRole(ctx, &UserRole{UserID: userID, RoleID: role.ID}))
+		require.NoError(t, repo.DeleteUserRole(ctx, userID, role.ID))
+		require.ErrorIs(t, repo.DeleteUserRole(ctx, userID, role.ID), gorm.ErrRecordNotFound)
+	})
+}
+
+// TestServiceGrantRole 覆盖授予临时角色时的窗口校验与持久化。
+func TestServiceGrantRole(t *testing.T) {
+	userID := uuid.New()
+	role := &Role{ID: uuid.New(), Name: "ONCALL"}
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	farFuture := now.Add(48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		input   GrantRoleInput
+		prepare func(m *mockRepository)
+		wantErr error
+	}{
+		{
+			name:  "授予永久角色",
+			input: GrantRoleInput{UserID: userID, Role: "oncall"},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByName", mock.Anything, "ONCALL").Return(role, nil)
+				m.On("SaveUserRole", mock.Anything, mock.MatchedBy(func(a *UserRole) bool {
+					return a.UserID == userID && a.RoleID == role.ID && a.ExpiresAt == nil
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "授予临时角色",
+			input: GrantRoleInput{UserID: userID, Role: "ONCALL", StartsAt: &future, ExpiresAt: &farFuture, Reason: " 值班 "},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByName", mock.Anything, "ONCALL").Return(role, nil)
+				m.On("SaveUserRole", mock.Anything, mock.MatchedBy(func(a *UserRole) bool {
+					return a.ExpiresAt != nil && a.ExpiresAt.Location() == time.UTC && a.Reason == "值班"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "过期时间早于生效时间",
+			input:   GrantRoleInput{UserID: userID, Role: "ONCALL", StartsAt: &farFuture, ExpiresAt: &future},
+			wantErr: ErrInvalidAssignment,
+		},
+		{
+			name:    "过期时间已过",
+			input:   GrantRoleInput{UserID: userID, Role: "ONCALL", ExpiresAt: &past},
+			wantErr: ErrInvalidAssignment,
+		},
+		{
+			name:  "角色不存在",
+			input: GrantRoleInput{UserID: userID, Role: "MISSING"},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByName", mock.Anything, "MISSING").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			if tt.prepare != nil {
+				tt.prepare(repo)
+			}
+			svc := newMockService(repo)
+
+			assignment, err := svc.GrantRole(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, assignment)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, role.Name, assignment.Role)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,609 @@
+package rbac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// PolicyDocumentVersion 是当前支持的声明式策略文件版本。
+const PolicyDocumentVersion = 1
+
+// 策略文件支持的序列化格式。
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// 策略计划中的角色变更类型。
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// PolicyDocument is the declarative, reviewable form of the RBAC configuration.
+//
+// Every role listed in the document is authoritative for its description, permissions and
+// parents. Roles missing from the document are left alone unless pruning is requested.
+// The permissions of the ADMIN role are always synchronised automatically and cannot be declared.
+type PolicyDocument struct {
+	Version int        `json:"version" yaml:"version"`
+	Roles   []RoleSpec `json:"roles" yaml:"roles"`
+}
+
+// RoleSpec declares a single role inside a PolicyDocument.
+type RoleSpec struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Parents     []string `json:"parents,omitempty" yaml:"parents,omitempty"`
+}
+
+// ApplyOptions controls how a PolicyDocument is reconciled with the database.
+type ApplyOptions struct {
+	// Prune deletes roles that exist in the database but not in the document.
+	// Built-in roles are never pruned.
+	Prune bool
+}
+
+// RoleChange describes the difference between the declared and the stored state of a role.
+type RoleChange struct {
+	Role              string   `json:"role"`
+	Action            string   `json:"action"`
+	Description       *string  `json:"description,omitempty"`
+	AddPermissions    []string `json:"addPermissions,omitempty"`
+	RemovePermissions []string `json:"removePermissions,omitempty"`
+	AddParents        []string `json:"addParents,omitempty"`
+	RemoveParents     []string `json:"removeParents,omitempty"`
+}
+
+// PolicyPlan lists the changes required to reconcile the database with a PolicyDocument.
+type PolicyPlan struct {
+	// NewPermissions are permission keys referenced by the document that do not exist yet.
+	NewPermissions []string     `json:"newPermissions,omitempty"`
+	Changes        []RoleChange `json:"changes"`
+}
+
+// Empty reports whether applying the plan would change nothing.
+func (p *PolicyPlan) Empty() bool {
+	return p == nil || (len(p.NewPermissions) == 0 && len(p.Changes) == 0)
+}
+
+// String renders the plan as a human readable diff.
+func (p *PolicyPlan) String() string {
+	if p.Empty() {
+		return "No changes. RBAC configuration is up to date.\n"
+	}
+
+	var b strings.Builder
+	for _, key := range p.NewPermissions {
+		fmt.Fprintf(&b, "+ permission %s\n", key)
+	}
+	for _, change := range p.Changes {
+		switch change.Action {
+		case ChangeCreate:
+			fmt.Fprintf(&b, "+ role %s\n", change.Role)
+		case ChangeDelete:
+			fmt.Fprintf(&b, "- role %s\n", change.Role)
+			continue
+		default:
+			fmt.Fprintf(&b, "~ role %s\n", change.Role)
+		}
+		if change.Description != nil {
+			fmt.Fprintf(&b, "    description: %q\n", *change.Description)
+		}
+		for _, key := range change.AddPermissions {
+			fmt.Fprintf(&b, "    + permission %s\n", key)
+		}
+		for _, key := range change.RemovePermissions {
+			fmt.Fprintf(&b, "    - permission %s\n", key)
+		}
+		for _, name := range change.AddParents {
+			fmt.Fprintf(&b, "    + parent %s\n", name)
+		}
+		for _, name := range change.RemoveParents {
+			fmt.Fprintf(&b, "    - parent %s\n", name)
+		}
+	}
+	return b.String()
+}
+
+// LoadPolicyDocument reads a policy document from disk. The format is derived from the file
+// extension and defaults to YAML.
+func LoadPolicyDocument(path string) (*PolicyDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy document: %w", err)
+	}
+	return DecodePolicyDocument(data, FormatFromPath(path))
+}
+
+// FormatFromPath returns the serialisation format implied by a file name.
+func FormatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// DecodePolicyDocument parses and normalises a policy document.
+func DecodePolicyDocument(data []byte, format string) (*PolicyDocument, error) {
+	var doc PolicyDocument
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode policy document: %w", err)
+		}
+	case FormatYAML, "yml", "":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode policy document: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported policy document format %q", format)
+	}
+
+	if err := doc.normalize(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Encode serialises the document in the requested format.
+func (d *PolicyDocument) Encode(format string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatJSON:
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML, "yml", "":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported policy document format %q", format)
+	}
+}
+
+// normalize canonicalises role names and permission keys and validates the document shape.
+func (d *PolicyDocument) normalize() error {
+	if d.Version == 0 {
+		d.Version = PolicyDocumentVersion
+	}
+	if d.Version != PolicyDocumentVersion {
+		return fmt.Errorf("unsupported policy document version %d", d.Version)
+	}
+
+	admin := NormalizeRoleName(constant.RoleAdmin)
+	seen := make(map[string]struct{}, len(d.Roles))
+	for i := range d.Roles {
+		spec := &d.Roles[i]
+		spec.Name = NormalizeRoleName(spec.Name)
+		spec.Description = strings.TrimSpace(spec.Description)
+		if spec.Name == "" {
+			return fmt.Errorf("policy document: role #%d has no name", i+1)
+		}
+		if _, ok := seen[spec.Name]; ok {
+			return fmt.Errorf("policy document: role %s is declared more than once", spec.Name)
+		}
+		seen[spec.Name] = struct{}{}
+
+		if spec.Name == admin && len(spec.Permissions) > 0 {
+			return fmt.Errorf("policy document: permissions of role %s are managed automatically", admin)
+		}
+
+		permissions := make([]string, 0, len(spec.Permissions))
+		for _, key := range spec.Permissions {
+			normalized, ok := NormalizePermissionKey(key)
+			if !ok {
+				return fmt.Errorf("policy document: role %s: %w %q", spec.Name, ErrInvalidPermission, key)
+			}
+			permissions = append(permissions, normalized)
+		}
+		spec.Permissions = sortedUnique(permissions)
+
+		spec.Parents = UniqueNormalized(spec.Parents)
+		sort.Strings(spec.Parents)
+		for _, parent := range spec.Parents {
+			if parent == spec.Name {
+				return fmt.Errorf("policy document: role %s: %w", spec.Name, ErrRoleCycle)
+			}
+		}
+	}
+
+	sort.Slice(d.Roles, func(i, j int) bool { return d.Roles[i].Name < d.Roles[j].Name })
+	return nil
+}
+
+// ExportPolicyDocument captures the roles stored in the database as a policy document.
+func (s *Service) ExportPolicyDocument(ctx context.Context) (*PolicyDocument, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := NormalizeRoleName(constant.RoleAdmin)
+	doc := &PolicyDocument{Version: PolicyDocumentVersion, Roles: make([]RoleSpec, 0, len(roles))}
+	for i := range roles {
+		role := &roles[i]
+		spec := RoleSpec{Name: role.Name, Description: role.Description}
+		if role.Name != admin {
+			spec.Permissions = rolePermissionKeys(role)
+		}
+		spec.Parents = roleParentNames(role)
+		doc.Roles = append(doc.Roles, spec)
+	}
+
+	if err := doc.normalize(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// PlanPolicyDocument computes the changes required to reconcile the database with doc
+// without modifying anything.
+func (s *Service) PlanPolicyDocument(ctx context.Context, doc *PolicyDocument, opts ApplyOptions) (*PolicyPlan, error) {
+	plan, _, err := s.planPolicyDocument(ctx, doc, opts)
+	return plan, err
+}
+
+// ApplyPolicyDocument reconciles the database with doc and returns the executed plan.
+//
+// The plan is computed and applied in one transaction that holds a lock on every role row, so
+// concurrent role or inheritance changes cannot invalidate the validation, and an invalid document
+// (unknown parent, inheritance cycle, malformed permission key) or a failed write leaves the
+// database untouched.
+func (s *Service) ApplyPolicyDocument(ctx context.Context, doc *PolicyDocument, opts ApplyOptions) (*PolicyPlan, error) {
+	var plan *PolicyPlan
+	err := s.repo.Transaction(ctx, func(repo RepositoryContract) error {
+		if err := repo.LockRoles(ctx); err != nil {
+			return err
+		}
+		var err error
+		plan, err = (&Service{repo: repo}).applyPolicyDocument(ctx, doc, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// applyPolicyDocument plans and writes doc through s.repo, which must be bound to a transaction.
+func (s *Service) applyPolicyDocument(ctx context.Context, doc *PolicyDocument, opts ApplyOptions) (*PolicyPlan, error) {
+	plan, stored, err := s.planPolicyDocument(ctx, doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Empty() {
+		return plan, nil
+	}
+
+	if err := s.EnsurePermissionsExist(ctx, plan.NewPermissions); err != nil {
+		return nil, err
+	}
+
+	specs := make(map[string]RoleSpec, len(doc.Roles))
+	for _, spec := range doc.Roles {
+		specs[spec.Name] = spec
+	}
+
+	// 先创建/更新全部角色并删除被清理的角色，再统一处理权限与继承关系，保证父角色一定已经存在。
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case ChangeCreate:
+			spec := specs[change.Role]
+			role := &Role{ID: uuid.Must(uuid.NewV7()), Name: spec.Name, Description: spec.Description}
+			if err := s.repo.CreateRole(ctx, role); err != nil {
+				return nil, err
+			}
+			stored[role.Name] = role
+		case ChangeUpdate:
+			if change.Description != nil {
+				role := stored[change.Role]
+				role.Description = *change.Description
+				if err := s.repo.UpdateRole(ctx, role); err != nil {
+					return nil, err
+				}
+			}
+		case ChangeDelete:
+			if err := s.repo.DeleteRole(ctx, stored[change.Role].ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+		}
+	}
+
+	for _, change := range plan.Changes {
+		if change.Action == ChangeDelete {
+			continue
+		}
+		if change.Action == ChangeCreate || len(change.AddPermissions) > 0 || len(change.RemovePermissions) > 0 {
+			permissions, err := s.repo.FindPermissionsByKeys(ctx, specs[change.Role].Permissions)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.repo.ReplaceRolePermissions(ctx, stored[change.Role], permissions); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 继承关系分两轮写入：先移除不再需要的父角色，再补齐新增的父角色。两轮都经过 AssignRoleParents
+	// 加锁并做循环检测；最终的继承图已校验无环，而每一步的中间状态都是它的子图，因此不会误报。
+	for _, change := range plan.Changes {
+		if change.Action != ChangeUpdate || len(change.RemoveParents) == 0 {
+			continue
+		}
+		kept, _ := diffStrings(change.AddParents, specs[change.Role].Parents)
+		if err := s.repo.AssignRoleParents(ctx, stored[change.Role], rolesByName(stored, kept)); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range plan.Changes {
+		if change.Action == ChangeDelete || len(change.AddParents) == 0 {
+			continue
+		}
+		if err := s.repo.AssignRoleParents(ctx, stored[change.Role], rolesByName(stored, specs[change.Role].Parents)); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+func rolesByName(stored map[string]*Role, names []string) []*Role {
+	roles := make([]*Role, 0, len(names))
+	for _, name := range names {
+		roles = append(roles, stored[name])
+	}
+	return roles
+}
+
+// planPolicyDocument returns the plan together with the stored roles indexed by name.
+func (s *Service) planPolicyDocument(ctx context.Context, doc *PolicyDocument, opts ApplyOptions) (*PolicyPlan, map[string]*Role, error) {
+	if doc == nil {
+		return nil, nil, fmt.Errorf("policy document is required")
+	}
+	if err := doc.normalize(); err != nil {
+		return nil, nil, err
+	}
+
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stored := make(map[string]*Role, len(roles))
+	for i := range roles {
+		stored[roles[i].Name] = &roles[i]
+	}
+
+	declared := make(map[string]struct{}, len(doc.Roles))
+	for _, spec := range doc.Roles {
+		declared[spec.Name] = struct{}{}
+	}
+
+	plan := &PolicyPlan{Changes: make([]RoleChange, 0)}
+	referenced := make([]string, 0)
+	for _, spec := range doc.Roles {
+		for _, parent := range spec.Parents {
+			if _, ok := declared[parent]; ok {
+				continue
+			}
+			if _, ok := stored[parent]; ok && !(opts.Prune && !isBuiltinRole(parent)) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("role %s: parent %s: %w", spec.Name, parent, ErrResourceNotFound)
+		}
+		referenced = append(referenced, spec.Permissions...)
+
+		role, exists := stored[spec.Name]
+		if !exists {
+			plan.Changes = append(plan.Changes, RoleChange{
+				Role:           spec.Name,
+				Action:         ChangeCreate,
+				Description:    optionalString(spec.Description),
+				AddPermissions: spec.Permissions,
+				AddParents:     spec.Parents,
+			})
+			continue
+		}
+
+		change := RoleChange{Role: spec.Name, Action: ChangeUpdate}
+		if role.Description != spec.Description {
+			description := spec.Description
+			change.Description = &description
+		}
+		if role.Name != NormalizeRoleName(constant.RoleAdmin) {
+			change.AddPermissions, change.RemovePermissions = diffStrings(rolePermissionKeys(role), spec.Permissions)
+		}
+		change.AddParents, change.RemoveParents = diffStrings(roleParentNames(role), spec.Parents)
+		if change.Description != nil || len(change.AddPermissions)+len(change.RemovePermissions)+len(change.AddParents)+len(change.RemoveParents) > 0 {
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	if opts.Prune {
+		for i := range roles {
+			name := roles[i].Name
+			if _, ok := declared[name]; ok || isBuiltinRole(name) {
+				continue
+			}
+			plan.Changes = append(plan.Changes, RoleChange{Role: name, Action: ChangeDelete})
+		}
+	}
+
+	if err := checkDeclaredInheritance(doc, roles, opts); err != nil {
+		return nil, nil, err
+	}
+
+	newPermissions, err := s.missingPermissionKeys(ctx, referenced)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan.NewPermissions = newPermissions
+
+	return plan, stored, nil
+}
+
+// missingPermissionKeys returns the keys that do not yet exist as permission records.
+func (s *Service) missingPermissionKeys(ctx context.Context, keys []string) ([]string, error) {
+	keys = sortedUnique(keys)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	existing, err := s.repo.FindPermissionsByKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]struct{}, len(existing))
+	for _, permission := range existing {
+		found[PermissionKey(permission.Resource, permission.Action)] = struct{}{}
+	}
+
+	missing := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing, nil
+}
+
+// checkDeclaredInheritance rejects documents whose final role graph contains a cycle.
+func checkDeclaredInheritance(doc *PolicyDocument, stored []Role, opts ApplyOptions) error {
+	nodes := make(map[string]*Role, len(stored)+len(doc.Roles))
+	for i := range stored {
+		nodes[stored[i].Name] = &Role{ID: stored[i].ID, Name: stored[i].Name, Parents: stored[i].Parents}
+	}
+	for _, spec := range doc.Roles {
+		if _, ok := nodes[spec.Name]; !ok {
+			nodes[spec.Name] = &Role{ID: uuid.New(), Name: spec.Name}
+		}
+	}
+
+	declared := make(map[string]struct{}, len(doc.Roles))
+	for _, spec := range doc.Roles {
+		declared[spec.Name] = struct{}{}
+		parents := make([]*Role, 0, len(spec.Parents))
+		for _, name := range spec.Parents {
+			parents = append(parents, nodes[name])
+		}
+		nodes[spec.Name].Parents = parents
+	}
+
+	final := make([]Role, 0, len(nodes))
+	for name, node := range nodes {
+		if _, ok := declared[name]; !ok && opts.Prune && !isBuiltinRole(name) {
+			continue
+		}
+		final = append(final, *node)
+	}
+
+	graph := newRoleGraph(final)
+	for _, spec := range doc.Roles {
+		node := nodes[spec.Name]
+		if graph.wouldCycle(node.ID, graph.parents[node.ID]) {
+			return fmt.Errorf("role %s: %w", spec.Name, ErrRoleCycle)
+		}
+	}
+	return nil
+}
+
+// isBuiltinRole reports whether the role is one of the baseline roles that must always exist.
+func isBuiltinRole(name string) bool {
+	name = NormalizeRoleName(name)
+	return name == NormalizeRoleName(constant.RoleAdmin) || name == NormalizeRoleName(constant.RoleUser)
+}
+
+func rolePermissionKeys(role *Role) []string {
+	keys := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		if permission == nil {
+			continue
+		}
+		keys = append(keys, PermissionKey(permission.Resource, permission.Action))
+	}
+	return sortedUnique(keys)
+}
+
+func roleParentNames(role *Role) []string {
+	names := make([]string, 0, len(role.Parents))
+	for _, parent := range role.Parents {
+		if parent == nil {
+			continue
+		}
+		names = append(names, parent.Name)
+	}
+	return sortedUnique(names)
+}
+
+// diffStrings returns the values that must be added to and removed from current to reach desired.
+func diffStrings(current, desired []string) (added, removed []string) {
+	currentSet := make(map[string]struct{}, len(current))
+	for _, value := range current {
+		currentSet[value] = struct{}{}
+	}
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, value := range desired {
+		desiredSet[value] = struct{}{}
+		if _, ok := currentSet[value]; !ok {
+			added = append(added, value)
+		}
+	}
+	for _, value := range current {
+		if _, ok := desiredSet[value]; !ok {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+func sortedUnique(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,248 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestDecodePolicyDocument 使用表驱动测试策略文件的解析与规范化。
+func TestDecodePolicyDocument(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		format  string
+		wantErr bool
+		check   func(*testing.T, *PolicyDocument)
+	}{
+		{
+			name:   "YAML 规范化",
+			format: FormatYAML,
+			data: `
+roles:
+  - name: editor
+    description: " Content editor "
+    permissions: ["Article:Update", "article:read", "article:read"]
+    parents: [viewer]
+  - name: viewer
+    permissions: ["article:read"]
+`,
+			check: func(t *testing.T, doc *PolicyDocument) {
+				require.Equal(t, PolicyDocumentVersion, doc.Version)
+				require.Len(t, doc.Roles, 2)
+				require.Equal(t, "EDITOR", doc.Roles[0].Name)
+				require.Equal(t, "Content editor", doc.Roles[0].Description)
+				require.Equal(t, []string{"article:read", "article:update"}, doc.Roles[0].Permissions)
+				require.Equal(t, []string{"VIEWER"}, doc.Roles[0].Parents)
+			},
+		},
+		{
+			name:   "JSON 格式",
+			format: FormatJSON,
+			data:   `{"version":1,"roles":[{"name":"viewer","permissions":["article:*"]}]}`,
+			check: func(t *testing.T, doc *PolicyDocument) {
+				require.Equal(t, []string{"article:*"}, doc.Roles[0].Permissions)
+			},
+		},
+		{name: "未知字段", format: FormatYAML, data: "roles:\n  - name: a\n    perms: [x]\n", wantErr: true},
+		{name: "重复角色", format: FormatYAML, data: "roles:\n  - name: a\n  - name: A\n", wantErr: true},
+		{name: "无效权限键", format: FormatYAML, data: "roles:\n  - name: a\n    permissions: [article]\n", wantErr: true},
+		{name: "自我继承", format: FormatYAML, data: "roles:\n  - name: a\n    parents: [a]\n", wantErr: true},
+		{name: "声明管理员权限", format: FormatYAML, data: "roles:\n  - name: admin\n    permissions: [user:read]\n", wantErr: true},
+		{name: "不支持的版本", format: FormatJSON, data: `{"version":2}`, wantErr: true},
+		{name: "不支持的格式", format: "toml", data: "", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := DecodePolicyDocument([]byte(tc.data), tc.format)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, doc)
+		})
+	}
+}
+
+// TestPolicyDocumentRoundTrip 验证导出的文档可被重新解析。
+func TestPolicyDocumentRoundTrip(t *testing.T) {
+	doc := &PolicyDocument{Roles: []RoleSpec{{Name: "VIEWER", Description: "Read only", Permissions: []string{"article:read"}}}}
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := doc.Encode(format)
+		require.NoError(t, err)
+
+		decoded, err := DecodePolicyDocument(data, format)
+		require.NoError(t, err)
+		require.Equal(t, doc.Roles, decoded.Roles)
+	}
+}
+
+// TestServiceApplyPolicyDocument 基于内存数据库验证计划与同步流程。
+func TestServiceApplyPolicyDocument(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		svc := NewService(repo)
+		require.NoError(t, svc.ensureBaselineRoles(ctx))
+		legacy := &Role{ID: uuid.New(), Name: "LEGACY"}
+		require.NoError(t, repo.CreateRole(ctx, legacy))
+
+		doc, err := DecodePolicyDocument([]byte(`
+roles:
+  - name: viewer
+    description: Read only
+    permissions: ["article:read"]
+  - name: editor
+    permissions: ["article:update"]
+    parents: [viewer]
+`), FormatYAML)
+		require.NoError(t, err)
+
+		plan, err := svc.PlanPolicyDocument(ctx, doc, ApplyOptions{Prune: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{"article:read", "article:update"}, plan.NewPermissions)
+		require.Len(t, plan.Changes, 3)
+		require.Contains(t, plan.String(), "- role LEGACY")
+
+		roles, err := repo.ListRoles(ctx)
+		require.NoError(t, err)
+		require.Len(t, roles, 3, "plan must not modify the database")
+
+		_, err = svc.ApplyPolicyDocument(ctx, doc, ApplyOptions{Prune: true})
+		require.NoError(t, err)
+
+		exported, err := svc.ExportPolicyDocument(ctx)
+		require.NoError(t, err)
+		names := make([]string, 0, len(exported.Roles))
+		for _, spec := range exported.Roles {
+			names = append(names, spec.Name)
+			if spec.Name == "EDITOR" {
+				require.Equal(t, []string{"article:update"}, spec.Permissions)
+				require.Equal(t, []string{"VIEWER"}, spec.Parents)
+			}
+		}
+		require.ElementsMatch(t, []string{"ADMIN", "USER", "VIEWER", "EDITOR"}, names)
+
+		plan, err = svc.PlanPolicyDocument(ctx, doc, ApplyOptions{Prune: true})
+		require.NoError(t, err)
+		require.True(t, plan.Empty())
+
+		doc.Roles[0].Permissions = nil
+		doc.Roles[0].Parents = nil
+		plan, err = svc.PlanPolicyDocument(ctx, doc, ApplyOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []RoleChange{{Role: "EDITOR", Action: ChangeUpdate, RemovePermissions: []string{"article:update"}, RemoveParents: []string{"VIEWER"}}}, plan.Changes)
+	})
+}
+
+// TestServicePlanPolicyDocumentErrors 验证无效文档在写入前即被拒绝。
+func TestServicePlanPolicyDocumentErrors(t *testing.T) {
+	db := setupTestDB(t)
+
+	cases := []struct {
+		name    string
+		data    string
+		opts    ApplyOptions
+		wantErr error
+	}{
+		{
+			name:    "父角色不存在",
+			data:    "roles:\n  - name: editor\n    parents: [ghost]\n",
+			wantErr: ErrResourceNotFound,
+		},
+		{
+			name:    "继承成环",
+			data:    "roles:\n  - name: a\n    parents: [b]\n  - name: b\n    parents: [a]\n",
+			wantErr: ErrRoleCycle,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+				doc, err := DecodePolicyDocument([]byte(tc.data), FormatYAML)
+				require.NoError(t, err)
+
+				_, err = NewService(repo).ApplyPolicyDocument(ctx, doc, tc.opts)
+				require.ErrorIs(t, err, tc.wantErr)
+
+				roles, err := repo.ListRoles(ctx)
+				require.NoError(t, err)
+				require.Empty(t, roles)
+			})
+		})
+	}
+}
+
+// TestServiceApplyPolicyDocumentRollback 验证写入中途失败时已写入的角色与权限全部回滚。
+func TestServiceApplyPolicyDocumentRollback(t *testing.T) {
+	db := setupTestDB(t)
+	errInjected := errors.New("injected failure")
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:fail_role_parent", func(tx *gorm.DB) {
+		if tx.Statement.Table == "role_parent" {
+			_ = tx.AddError(errInjected)
+		}
+	}))
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		svc := NewService(repo)
+		require.NoError(t, svc.ensureBaselineRoles(ctx))
+
+		doc, err := DecodePolicyDocument([]byte(`
+roles:
+  - name: viewer
+    permissions: ["article:read"]
+  - name: editor
+    permissions: ["article:update"]
+    parents: [viewer]
+`), FormatYAML)
+		require.NoError(t, err)
+
+		_, err = svc.ApplyPolicyDocument(ctx, doc, ApplyOptions{})
+		require.ErrorIs(t, err, errInjected)
+
+		roles, err := repo.ListRoles(ctx)
+		require.NoError(t, err)
+		names := make([]string, 0, len(roles))
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+		require.ElementsMatch(t, []string{"ADMIN", "USER"}, names)
+
+		permissions, err := repo.ListPermissions(ctx)
+		require.NoError(t, err)
+		require.Empty(t, permissions)
+	})
+}
+
+// TestServiceApplyPolicyDocumentReverseInheritance 验证反转两个角色的继承方向时不会因中间状态误报循环。
+func TestServiceApplyPolicyDocumentReverseInheritance(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		svc := NewService(repo)
+		alpha := &Role{ID: uuid.New(), Name: "ALPHA"}
+		beta := &Role{ID: uuid.New(), Name: "BETA"}
+		require.NoError(t, repo.CreateRole(ctx, alpha))
+		require.NoError(t, repo.CreateRole(ctx, beta))
+		require.NoError(t, repo.AssignRoleParents(ctx, beta, []*Role{alpha}))
+
+		doc := &PolicyDocument{Roles: []RoleSpec{{Name: "alpha", Parents: []string{"beta"}}, {Name: "beta"}}}
+		_, err := svc.ApplyPolicyDocument(ctx, doc, ApplyOptions{})
+		require.NoError(t, err)
+
+		exported, err := svc.ExportPolicyDocument(ctx)
+		require.NoError(t, err)
+		parents := make(map[string][]string, len(exported.Roles))
+		for _, spec := range exported.Roles {
+			parents[spec.Name] = spec.Parents
+		}
+		require.Equal(t, map[string][]string{"ALPHA": {"BETA"}, "BETA": nil}, parents)
+	})
+}
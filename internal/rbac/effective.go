@@ -0,0 +1,94 @@
+package rbac
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Jayleonc/service/internal/feature"
+)
+
+// UserPermissions is the set of concrete permission keys a user may exercise together with a
+// version that changes whenever the set changes, so clients can cache it.
+type UserPermissions struct {
+	Permissions []string `json:"permissions"`
+	Version     string   `json:"version"`
+}
+
+// EffectiveUserPermissions resolves the permissions the user holds at route level.
+//
+// Every concrete key of the permission catalog is checked in the same order as CheckPermission:
+// a deny policy that applies at route level removes the key, otherwise holders of ADMIN (in the
+// session roles or through the user's active and inherited roles), role grants (including wildcard and inherited grants)
+// and applicable allow policies keep it. Allows pending a resource check are included because
+// the user may exercise them on some resources.
+func (s *Service) EffectiveUserPermissions(ctx context.Context, userID uuid.UUID, roles []string) (*UserPermissions, error) {
+	catalog, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(catalog))
+	for _, permission := range catalog {
+		key := PermissionKey(permission.Resource, permission.Action)
+		if IsWildcardPermission(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	roleIDs, err := s.repo.FindUserRoleIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.repo.FindPoliciesByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err = s.bypassRoles(ctx, roleIDs, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	var grantPatterns []string
+	if !HasAdminRole(roles) {
+		grants, err := s.repo.FindRolePermissionGrants(ctx, roleIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, grant := range grants {
+			grantPatterns = append(grantPatterns, grant.Permission)
+		}
+	}
+
+	match := routeMatcher(newPolicyEnv(ctx, feature.AuthContext{UserID: userID, Roles: roles}, nil))
+	effective := make([]string, 0, len(keys))
+	for _, key := range keys {
+		decision, err := decide(roles, key, policies, match, func() (bool, error) {
+			return MatchAnyPermission(grantPatterns, key), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if decision.Allowed {
+			effective = append(effective, key)
+		}
+	}
+	return newUserPermissions(effective), nil
+}
+
+func newUserPermissions(keys []string) *UserPermissions {
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return &UserPermissions{
+		Permissions: sorted,
+		Version:     hex.EncodeToString(sum[:8]),
+	}
+}
@@ -0,0 +1,137 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// TestServiceEffectiveUserPermissions 覆盖有效权限的展开规则。
+func TestServiceEffectiveUserPermissions(t *testing.T) {
+	userID := uuid.New()
+	roleIDs := []uuid.UUID{uuid.New()}
+	catalog := []Permission{
+		{Resource: "article", Action: "read"},
+		{Resource: "article", Action: "update"},
+		{Resource: "article", Action: "delete"},
+		{Resource: "user", Action: "list"},
+		{Resource: "article", Action: "*"},
+	}
+
+	tests := []struct {
+		name    string
+		roles   []string
+		prepare func(m *mockRepository)
+		want    []string
+	}{
+		{
+			name:  "管理员获得全部权限",
+			roles: []string{constant.RoleAdmin},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
+			},
+			want: []string{"article:delete", "article:read", "article:update", "user:list"},
+		},
+		{
+			name:  "拒绝策略同样约束管理员",
+			roles: []string{constant.RoleAdmin},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{{Permission: "article:delete", Effect: EffectDeny}}, nil)
+			},
+			want: []string{"article:read", "article:update", "user:list"},
+		},
+		{
+			name:  "继承自管理员的角色获得全部权限",
+			roles: []string{"OPS"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{Name: "OPS"}, {Name: constant.RoleAdmin}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
+			},
+			want: []string{"article:delete", "article:read", "article:update", "user:list"},
+		},
+		{
+			name:  "通配授权展开",
+			roles: []string{"EDITOR"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{ID: roleIDs[0], Name: "EDITOR"}}, nil)
+				m.On("FindRolePermissionGrants", mock.Anything, roleIDs).Return([]PermissionGrant{{Permission: "article:*"}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
+			},
+			want: []string{"article:delete", "article:read", "article:update"},
+		},
+		{
+			name:  "拒绝策略剔除权限",
+			roles: []string{"EDITOR"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{ID: roleIDs[0], Name: "EDITOR"}}, nil)
+				m.On("FindRolePermissionGrants", mock.Anything, roleIDs).Return([]PermissionGrant{{Permission: "article:*"}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{
+					{Permission: "article:delete", Effect: EffectDeny},
+					{Permission: "user:list", Effect: EffectAllow, Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}}},
+				}, nil)
+			},
+			want: []string{"article:read", "article:update", "user:list"},
+		},
+		{
+			name:  "请求条件不成立的允许策略不计入",
+			roles: []string{"EDITOR"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{ID: roleIDs[0], Name: "EDITOR"}}, nil)
+				m.On("FindRolePermissionGrants", mock.Anything, roleIDs).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{
+					{Permission: "user:list", Effect: EffectAllow, Conditions: []Condition{{Field: "request.ip", Operator: OpExists}}},
+				}, nil)
+			},
+			want: []string{},
+		},
+		{
+			name:  "无任何授权",
+			roles: []string{"USER"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(nil, nil)
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			repo.On("ListPermissions", mock.Anything).Return(catalog, nil)
+			if tt.prepare != nil {
+				tt.prepare(repo)
+			}
+			svc := newMockService(repo)
+
+			result, err := svc.EffectiveUserPermissions(context.Background(), userID, tt.roles)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result.Permissions)
+			require.Equal(t, newUserPermissions(tt.want).Version, result.Version)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUserPermissionsVersion 验证版本号与权限顺序无关、随内容变化。
+func TestUserPermissionsVersion(t *testing.T) {
+	a := newUserPermissions([]string{"user:list", "article:read"})
+	b := newUserPermissions([]string{"article:read", "user:list"})
+	c := newUserPermissions([]string{"article:read"})
+
+	require.Equal(t, a.Version, b.Version)
+	require.NotEqual(t, a.Version, c.Version)
+	require.Len(t, a.Version, 16)
+}
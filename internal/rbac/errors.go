@@ -1,9 +1,27 @@
 package rbac
 
-import "github.com/Jayleonc/service/pkg/xerr"
+import (
+	"embed"
+	"net/http"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
 
 // RBAC 模块错误码范围：3000-3999
 var (
-	ErrResourceNotFound = xerr.New(3001, "resource not found")
-	ErrPermissionDenied = xerr.New(3002, "permission denied")
+	ErrResourceNotFound  = xerr.New(3001, "resource not found").WithStatus(http.StatusNotFound)
+	ErrPermissionDenied  = xerr.New(3002, "permission denied").WithStatus(http.StatusForbidden)
+	ErrInvalidPermission = xerr.New(3003, "invalid permission key").WithStatus(http.StatusBadRequest)
+	ErrRoleCycle         = xerr.New(3004, "role inheritance cycle detected").WithStatus(http.StatusConflict)
+	ErrInvalidAssignment = xerr.New(3005, "invalid role assignment window").WithStatus(http.StatusBadRequest)
+	ErrVersionConflict   = xerr.New(3006, "resource was modified by another request").WithStatus(http.StatusConflict)
 )
+
+// locales holds the translated messages for the RBAC error codes above.
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	xerr.MustRegisterCatalog(locales, "locales")
+}
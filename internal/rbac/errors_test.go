@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// TestErrorLocalize 验证错误消息按 Accept-Language 渲染，并在无法匹配时回退到英文。
+func TestErrorLocalize(t *testing.T) {
+	cases := []struct {
+		name           string
+		acceptLanguage string
+		err            *xerr.Error
+		want           string
+	}{
+		{name: "中文", acceptLanguage: "zh-CN,zh;q=0.9", err: ErrRoleCycle, want: "检测到角色继承环"},
+		{name: "按权重选择英文", acceptLanguage: "zh;q=0.5, en", err: ErrRoleCycle, want: "role inheritance cycle detected"},
+		{name: "不支持的语言回退英文", acceptLanguage: "fr", err: ErrPermissionDenied, want: "permission denied"},
+		{name: "定制消息原样返回", acceptLanguage: "zh", err: ErrResourceNotFound.WithMessage("role not found"), want: "role not found"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.err.Localize(tc.acceptLanguage))
+		})
+	}
+}
+
+// TestErrorMapping 验证 xerr.FromOr 的映射结果、推导出的 HTTP 状态码，以及包装后仍可识别底层原因。
+func TestErrorMapping(t *testing.T) {
+	cause := errors.New("connection reset")
+	cases := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus int
+		wantCause  error
+	}{
+		{name: "记录不存在", err: fmt.Errorf("find role: %w", gorm.ErrRecordNotFound), wantCode: xerr.ErrNotFound.Code, wantStatus: http.StatusNotFound, wantCause: gorm.ErrRecordNotFound},
+		{name: "唯一键冲突", err: gorm.ErrDuplicatedKey, wantCode: xerr.ErrConflict.Code, wantStatus: http.StatusConflict, wantCause: gorm.ErrDuplicatedKey},
+		{name: "模块错误保持原样", err: ErrRoleCycle, wantCode: ErrRoleCycle.Code, wantStatus: http.StatusConflict},
+		{name: "包装后的模块错误", err: fmt.Errorf("assign parents: %w", ErrInvalidPermission.Wrap(cause)), wantCode: ErrInvalidPermission.Code, wantStatus: http.StatusBadRequest, wantCause: cause},
+		{name: "未知错误使用兜底", err: cause, wantCode: ErrResourceNotFound.Code, wantStatus: http.StatusNotFound, wantCause: cause},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerr.FromOr(tc.err, ErrResourceNotFound)
+			require.Equal(t, tc.wantCode, got.Code)
+			require.Equal(t, tc.wantStatus, got.HTTPStatus())
+			if tc.wantCause != nil {
+				require.ErrorIs(t, got, tc.wantCause)
+			}
+		})
+	}
+
+	require.Nil(t, xerr.From(nil))
+	require.Equal(t, http.StatusInternalServerError, xerr.From(cause).HTTPStatus())
+	require.ErrorIs(t, ErrRoleCycle.Wrap(cause), ErrRoleCycle)
+	require.NotContains(t, ErrRoleCycle.Wrap(cause).Localize("en"), cause.Error())
+}
@@ -0,0 +1,224 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Jayleonc/service/internal/feature"
+)
+
+// Explanation describes how a route-level permission check for a user was decided.
+//
+// It mirrors NewPermissionMiddleware followed by CheckPermission: deny policies are checked
+// before the ADMIN bypass (which applies to assigned and inherited roles) and role grants, and
+// policy conditions are evaluated against the subject and the current request. Allow policies
+// whose conditions depend on the resource loaded by the handler are reported as Pending.
+type Explanation struct {
+	Decision
+	UserID     uuid.UUID `json:"userId"`
+	Permission string    `json:"permission"`
+	// AdminBypass reports whether the decision was made by the ADMIN bypass.
+	AdminBypass bool              `json:"adminBypass"`
+	Roles       []ExplainedRole   `json:"roles"`
+	Matched     []PermissionGrant `json:"matchedPermissions"`
+	Unmatched   []PermissionGrant `json:"unmatchedPermissions"`
+	Policies    []ExplainedPolicy `json:"policies"`
+}
+
+// ExplainedRole is a role held by the user, either directly or through inheritance.
+type ExplainedRole struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Inherited bool      `json:"inherited"`
+}
+
+// PermissionGrant is a role_permission row resolved to its role name and permission key.
+type PermissionGrant struct {
+	RoleID     uuid.UUID `json:"roleId"`
+	Role       string    `json:"role"`
+	Permission string    `json:"permission"`
+}
+
+// ExplainedPolicy is a policy attached to one of the user's roles.
+type ExplainedPolicy struct {
+	ID          uuid.UUID `json:"id"`
+	RoleID      uuid.UUID `json:"roleId"`
+	Permission  string    `json:"permission"`
+	Effect      string    `json:"effect"`
+	Conditional bool      `json:"conditional"`
+	Matched     bool      `json:"matched"`
+}
+
+// FindRolesByIDs returns the roles with the given identifiers.
+func (r *Repository) FindRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]Role, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var roles []Role
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// FindRolePermissionGrants returns every permission granted to the given roles.
+func (r *Repository) FindRolePermissionGrants(ctx context.Context, roleIDs []uuid.UUID) ([]PermissionGrant, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		RoleID   uuid.UUID
+		Role     string
+		Resource string
+		Action   string
+	}
+	err := r.db.WithContext(ctx).
+		Table("role_permission rp").
+		Select("rp.role_id AS role_id, role.name AS role, permission.resource AS resource, permission.action AS action").
+		Joins("JOIN role ON role.id = rp.role_id").
+		Joins("JOIN permission ON permission.id = rp.permission_id").
+		Where("rp.role_id IN ?", roleIDs).
+		Order("role.name ASC, permission.resource ASC, permission.action ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]PermissionGrant, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, PermissionGrant{
+			RoleID:     row.RoleID,
+			Role:       row.Role,
+			Permission: PermissionKey(row.Resource, row.Action),
+		})
+	}
+	return grants, nil
+}
+
+// Explain evaluates the permission for the user and returns the decision together with the
+// roles, permission rows and policies that were considered.
+func (s *Service) Explain(ctx context.Context, userID uuid.UUID, permission string) (*Explanation, error) {
+	required, ok := NormalizePermissionKey(permission)
+	if !ok {
+		return nil, ErrInvalidPermission
+	}
+
+	direct, err := s.repo.FindActiveUserRoleIDs(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	roleIDs, err := s.repo.FindUserRoleIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.repo.FindRolesByIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.repo.FindRolePermissionGrants(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.repo.FindPoliciesByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		UserID:     userID,
+		Permission: required,
+		Roles:      make([]ExplainedRole, 0, len(roles)),
+		Matched:    []PermissionGrant{},
+		Unmatched:  []PermissionGrant{},
+		Policies:   make([]ExplainedPolicy, 0, len(policies)),
+	}
+
+	directSet := make(map[uuid.UUID]struct{}, len(direct))
+	for _, id := range direct {
+		directSet[id] = struct{}{}
+	}
+	names := make([]string, 0, len(roles))
+	directNames := make([]string, 0, len(direct))
+	for _, role := range roles {
+		_, isDirect := directSet[role.ID]
+		explanation.Roles = append(explanation.Roles, ExplainedRole{ID: role.ID, Name: role.Name, Inherited: !isDirect})
+		names = append(names, role.Name)
+		if isDirect {
+			directNames = append(directNames, role.Name)
+		}
+	}
+
+	for _, grant := range grants {
+		if MatchPermission(grant.Permission, required) {
+			explanation.Matched = append(explanation.Matched, grant)
+		} else {
+			explanation.Unmatched = append(explanation.Unmatched, grant)
+		}
+	}
+
+	for i := range policies {
+		policy := &policies[i]
+		explanation.Policies = append(explanation.Policies, ExplainedPolicy{
+			ID:          policy.ID,
+			RoleID:      policy.RoleID,
+			Permission:  policy.Permission,
+			Effect:      policy.Effect,
+			Conditional: !policy.unconditional(),
+			Matched:     MatchPermission(policy.Permission, required),
+		})
+	}
+
+	subject := feature.AuthContext{UserID: userID, Roles: directNames}
+	match := routeMatcher(newPolicyEnv(ctx, subject, nil))
+	explanation.Decision, err = decide(names, required, policies, match, func() (bool, error) {
+		return len(explanation.Matched) > 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	explanation.AdminBypass = explanation.Reason == reasonAdminBypass
+	return explanation, nil
+}
+
+// Summary renders the explanation as a single line suitable for a response header or log entry.
+func (e *Explanation) Summary() string {
+	roles := make([]string, 0, len(e.Roles))
+	for _, role := range e.Roles {
+		if role.Inherited {
+			roles = append(roles, role.Name+"(inherited)")
+		} else {
+			roles = append(roles, role.Name)
+		}
+	}
+	sort.Strings(roles)
+
+	matched := make([]string, 0, len(e.Matched))
+	for _, grant := range e.Matched {
+		matched = append(matched, grant.Role+"="+grant.Permission)
+	}
+
+	decision := "deny"
+	if e.Allowed {
+		decision = "allow"
+	}
+
+	parts := []string{
+		"permission=" + e.Permission,
+		"decision=" + decision,
+		"reason=" + e.Reason,
+		"roles=" + strings.Join(roles, ","),
+		"matched=" + strings.Join(matched, ","),
+		fmt.Sprintf("policies=%d", len(e.Policies)),
+	}
+	if e.PolicyID != nil {
+		parts = append(parts, "policy="+e.PolicyID.String())
+	}
+	return strings.Join(parts, "; ")
+}
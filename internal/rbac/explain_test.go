@@ -0,0 +1,258 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+)
+
+// TestRepositoryFindRolePermissionGrants 验证权限行会解析出角色名与权限键。
+func TestRepositoryFindRolePermissionGrants(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		read := &Permission{ID: uuid.New(), Resource: "article", Action: "read"}
+		update := &Permission{ID: uuid.New(), Resource: "article", Action: "update"}
+		require.NoError(t, tx.WithContext(ctx).Create(read).Error)
+		require.NoError(t, tx.WithContext(ctx).Create(update).Error)
+
+		viewer := &Role{ID: uuid.New(), Name: "VIEWER", Permissions: []*Permission{read}}
+		editor := &Role{ID: uuid.New(), Name: "EDITOR", Permissions: []*Permission{update}}
+		require.NoError(t, tx.WithContext(ctx).Create(viewer).Error)
+		require.NoError(t, tx.WithContext(ctx).Create(editor).Error)
+
+		grants, err := repo.FindRolePermissionGrants(ctx, []uuid.UUID{viewer.ID, editor.ID})
+		require.NoError(t, err)
+		require.Equal(t, []PermissionGrant{
+			{RoleID: editor.ID, Role: "EDITOR", Permission: "article:update"},
+			{RoleID: viewer.ID, Role: "VIEWER", Permission: "article:read"},
+		}, grants)
+
+		roles, err := repo.FindRolesByIDs(ctx, []uuid.UUID{viewer.ID})
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		require.Equal(t, "VIEWER", roles[0].Name)
+	})
+}
+
+// TestServiceExplain 覆盖授权诊断的各类结论。
+func TestServiceExplain(t *testing.T) {
+	userID := uuid.New()
+	editor := Role{ID: uuid.New(), Name: "EDITOR"}
+	viewer := Role{ID: uuid.New(), Name: "VIEWER"}
+	admin := Role{ID: uuid.New(), Name: constant.RoleAdmin}
+	ops := Role{ID: uuid.New(), Name: "OPS"}
+	denyPolicy := Policy{ID: uuid.New(), RoleID: editor.ID, Permission: "article:*", Effect: EffectDeny}
+	allowPolicy := Policy{
+		ID:         uuid.New(),
+		RoleID:     editor.ID,
+		Permission: "article:update",
+		Effect:     EffectAllow,
+		Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}},
+	}
+
+	prepareRoles := func(m *mockRepository, direct []uuid.UUID, roles []Role) {
+		ids := make([]uuid.UUID, 0, len(roles))
+		for _, role := range roles {
+			ids = append(ids, role.ID)
+		}
+		m.On("FindActiveUserRoleIDs", mock.Anything, userID, mock.Anything).Return(direct, nil)
+		m.On("FindUserRoleIDs", mock.Anything, userID).Return(ids, nil)
+		m.On("FindRolesByIDs", mock.Anything, ids).Return(roles, nil)
+	}
+
+	tests := []struct {
+		name        string
+		permission  string
+		prepare     func(m *mockRepository)
+		wantErr     error
+		wantAllowed bool
+		wantReason  string
+		wantAdmin   bool
+		wantMatched int
+		wantPolicy  *uuid.UUID
+		wantPending bool
+	}{
+		{
+			name:       "继承角色授权",
+			permission: "article:read",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{editor.ID}, []Role{editor, viewer})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return([]PermissionGrant{
+					{RoleID: editor.ID, Role: editor.Name, Permission: "article:update"},
+					{RoleID: viewer.ID, Role: viewer.Name, Permission: "article:*"},
+				}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			wantAllowed: true,
+			wantReason:  "granted by role permission",
+			wantMatched: 1,
+		},
+		{
+			name:       "无匹配授权",
+			permission: "user:delete",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{viewer.ID}, []Role{viewer})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return([]PermissionGrant{
+					{RoleID: viewer.ID, Role: viewer.Name, Permission: "article:read"},
+				}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			wantReason: "no matching grant",
+		},
+		{
+			name:       "拒绝策略优先",
+			permission: "article:update",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{editor.ID}, []Role{editor})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return([]PermissionGrant{
+					{RoleID: editor.ID, Role: editor.Name, Permission: "article:update"},
+				}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return([]Policy{denyPolicy}, nil)
+			},
+			wantReason:  "denied by policy",
+			wantMatched: 1,
+			wantPolicy:  &denyPolicy.ID,
+		},
+		{
+			name:       "条件允许策略",
+			permission: "article:update",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{editor.ID}, []Role{editor})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return([]Policy{allowPolicy}, nil)
+			},
+			wantAllowed: true,
+			wantReason:  "allowed by policy pending resource check",
+			wantPolicy:  &allowPolicy.ID,
+			wantPending: true,
+		},
+		{
+			name:       "管理员放行",
+			permission: "article:delete",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{admin.ID}, []Role{admin})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			wantAllowed: true,
+			wantReason:  "admin bypass",
+			wantAdmin:   true,
+		},
+		{
+			name:       "拒绝策略优先于管理员",
+			permission: "article:delete",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{admin.ID}, []Role{admin})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return([]Policy{denyPolicy}, nil)
+			},
+			wantReason: "denied by policy",
+			wantPolicy: &denyPolicy.ID,
+		},
+		{
+			name:       "继承自管理员的角色放行",
+			permission: "article:delete",
+			prepare: func(m *mockRepository) {
+				prepareRoles(m, []uuid.UUID{ops.ID}, []Role{ops, admin})
+				m.On("FindRolePermissionGrants", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			wantAllowed: true,
+			wantReason:  "admin bypass",
+			wantAdmin:   true,
+		},
+		{
+			name:       "非法权限键",
+			permission: "invalid",
+			prepare:    func(*mockRepository) {},
+			wantErr:    ErrInvalidPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			tt.prepare(repo)
+			svc := newMockService(repo)
+
+			explanation, err := svc.Explain(context.Background(), userID, tt.permission)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowed, explanation.Allowed)
+			require.Equal(t, tt.wantReason, explanation.Reason)
+			require.Equal(t, tt.wantAdmin, explanation.AdminBypass)
+			require.Len(t, explanation.Matched, tt.wantMatched)
+			require.Equal(t, tt.wantPolicy, explanation.PolicyID)
+			require.Equal(t, tt.wantPending, explanation.Pending)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// stubExplainer 返回固定的诊断结果。
+type stubExplainer struct {
+	explanation *Explanation
+}
+
+func (s stubExplainer) Explain(context.Context, uuid.UUID, string) (*Explanation, error) {
+	return s.explanation, nil
+}
+
+// TestDebugPermissionMiddleware 验证开启诊断后拒绝响应会附带诊断头。
+func TestDebugPermissionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	permissionKey := "system:view"
+	explanation := &Explanation{
+		Decision:   Decision{Reason: "no matching grant"},
+		Permission: permissionKey,
+		Roles:      []ExplainedRole{{Name: "USER"}},
+	}
+
+	cases := []struct {
+		name       string
+		explainer  Explainer
+		wantHeader string
+	}{
+		{name: "开启诊断", explainer: stubExplainer{explanation: explanation}, wantHeader: explanation.Summary()},
+		{name: "关闭诊断", explainer: nil, wantHeader: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := &mockPermissionChecker{}
+			checker.On("CheckPermission", mock.Anything, mock.Anything, permissionKey).Return(Decision{}, nil)
+
+			factory := NewDebugPermissionMiddleware(checker, tc.explainer)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				feature.SetAuthContext(c, feature.AuthContext{UserID: uuid.New(), Roles: []string{"user"}})
+			})
+			router.GET("/protected", factory(permissionKey), func(c *gin.Context) {
+				response.Success(c, gin.H{"ok": true})
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/protected", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Equal(t, tc.wantHeader, recorder.Header().Get(DebugHeader))
+		})
+	}
+}
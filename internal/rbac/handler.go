@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/ginx/etag"
 	"github.com/Jayleonc/service/pkg/ginx/response"
 	"github.com/Jayleonc/service/pkg/xerr"
 )
@@ -27,10 +28,16 @@ type ServiceContract interface {
 	ListRoles(ctx context.Context) ([]Role, error)
 	AssignPermissions(ctx context.Context, input AssignRolePermissionsInput) (*Role, error)
 	GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error)
+	AssignParents(ctx context.Context, input AssignRoleParentsInput) (*Role, error)
+	GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error)
 	CreatePermission(ctx context.Context, input CreatePermissionInput) (*Permission, error)
 	UpdatePermission(ctx context.Context, input UpdatePermissionInput) (*Permission, error)
 	DeletePermission(ctx context.Context, input DeletePermissionInput) error
 	ListPermissions(ctx context.Context) ([]Permission, error)
+	CreatePolicy(ctx context.Context, input CreatePolicyInput) (*Policy, error)
+	DeletePolicy(ctx context.Context, input DeletePolicyInput) error
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	Explain(ctx context.Context, userID uuid.UUID, permission string) (*Explanation, error)
 }
 
 // NewHandler constructs a handler with the provided service dependency.
@@ -50,10 +57,16 @@ func (h *Handler) GetRoutes() feature.ModuleRoutes {
 			{Path: "role/list", Handler: h.listRoles, RequiredPermission: PermissionKey(ResourceRBACRole, ActionList)},
 			{Path: "role/assign_permissions", Handler: h.assignRolePermissions, RequiredPermission: PermissionKey(ResourceRBACRole, ActionAssignPermissions)},
 			{Path: "role/get_permissions", Handler: h.getRolePermissions, RequiredPermission: PermissionKey(ResourceRBACRole, ActionViewPermissions)},
+			{Path: "role/assign_parents", Handler: h.assignRoleParents, RequiredPermission: PermissionKey(ResourceRBACRole, ActionAssignParents)},
+			{Path: "role/get_effective_permissions", Handler: h.getEffectivePermissions, RequiredPermission: PermissionKey(ResourceRBACRole, ActionViewPermissions)},
 			{Path: "permission/create", Handler: h.createPermission, RequiredPermission: PermissionKey(ResourceRBACPermission, ActionCreate)},
 			{Path: "permission/update", Handler: h.updatePermission, RequiredPermission: PermissionKey(ResourceRBACPermission, ActionUpdate)},
 			{Path: "permission/delete", Handler: h.deletePermission, RequiredPermission: PermissionKey(ResourceRBACPermission, ActionDelete)},
 			{Path: "permission/list", Handler: h.listPermissions, RequiredPermission: PermissionKey(ResourceRBACPermission, ActionList)},
+			{Path: "policy/create", Handler: h.createPolicy, RequiredPermission: PermissionKey(ResourceRBACPolicy, ActionCreate)},
+			{Path: "policy/delete", Handler: h.deletePolicy, RequiredPermission: PermissionKey(ResourceRBACPolicy, ActionDelete)},
+			{Path: "policy/list", Handler: h.listPolicies, RequiredPermission: PermissionKey(ResourceRBACPolicy, ActionList)},
+			{Path: "explain", Handler: h.explain, RequiredPermission: PermissionKey(ResourceRBACPermission, ActionExplain)},
 		},
 	}
 }
@@ -61,13 +74,13 @@ func (h *Handler) GetRoutes() feature.ModuleRoutes {
 func (h *Handler) createRole(c *gin.Context) {
 	var req CreateRoleInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	role, err := h.svc.CreateRole(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
 		return
 	}
 
@@ -79,28 +92,33 @@ func (h *Handler) updateRole(c *gin.Context) {
 		ID          string `json:"id" binding:"required"`
 		Name        string `json:"name"`
 		Description string `json:"description"`
+		Version     int64  `json:"version"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	roleID, err := uuid.Parse(req.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
 		return
 	}
 
-	updated, err := h.svc.UpdateRole(c.Request.Context(), UpdateRoleInput{ID: roleID, Name: req.Name, Description: req.Description})
+	version, fromHeader, err := etag.Expected(c, req.Version)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("role not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		response.Fail(c, err)
 		return
 	}
 
+	updated, err := h.svc.UpdateRole(c.Request.Context(), UpdateRoleInput{ID: roleID, Name: req.Name, Description: req.Description, Version: version})
+	if err != nil {
+		err = etag.Conflict(err, ErrVersionConflict, fromHeader)
+		fail(c, err)
+		return
+	}
+
+	etag.Set(c, updated.Version)
 	response.Success(c, updated)
 }
 
@@ -109,22 +127,18 @@ func (h *Handler) deleteRole(c *gin.Context) {
 		ID string `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	roleID, err := uuid.Parse(req.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
 		return
 	}
 
 	if err := h.svc.DeleteRole(c.Request.Context(), DeleteRoleInput{ID: roleID}); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("role not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
 		return
 	}
 
@@ -134,7 +148,7 @@ func (h *Handler) deleteRole(c *gin.Context) {
 func (h *Handler) listRoles(c *gin.Context) {
 	roles, err := h.svc.ListRoles(c.Request.Context())
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err)
+		fail(c, err)
 		return
 	}
 	response.Success(c, roles)
@@ -146,23 +160,19 @@ func (h *Handler) assignRolePermissions(c *gin.Context) {
 		Permissions []string `json:"permissions" binding:"required,min=1,dive,required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	roleID, err := uuid.Parse(req.RoleID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
 		return
 	}
 
 	role, err := h.svc.AssignPermissions(c.Request.Context(), AssignRolePermissionsInput{RoleID: roleID, Permissions: req.Permissions})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("role not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
 		return
 	}
 
@@ -174,23 +184,68 @@ func (h *Handler) getRolePermissions(c *gin.Context) {
 		RoleID string `json:"roleId" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	roleID, err := uuid.Parse(req.RoleID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
 		return
 	}
 
 	permissions, err := h.svc.GetRolePermissions(c.Request.Context(), roleID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("role not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"roleId": roleID, "permissions": permissions})
+}
+
+func (h *Handler) assignRoleParents(c *gin.Context) {
+	var req struct {
+		RoleID  string   `json:"roleId" binding:"required"`
+		Parents []string `json:"parents" binding:"omitempty,dive,required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		return
+	}
+
+	role, err := h.svc.AssignParents(c.Request.Context(), AssignRoleParentsInput{RoleID: roleID, Parents: req.Parents})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	response.Success(c, role)
+}
+
+func (h *Handler) getEffectivePermissions(c *gin.Context) {
+	var req struct {
+		RoleID string `json:"roleId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		return
+	}
+
+	permissions, err := h.svc.GetEffectivePermissions(c.Request.Context(), roleID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
@@ -200,13 +255,13 @@ func (h *Handler) getRolePermissions(c *gin.Context) {
 func (h *Handler) createPermission(c *gin.Context) {
 	var req CreatePermissionInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	permission, err := h.svc.CreatePermission(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
 		return
 	}
 
@@ -219,28 +274,33 @@ func (h *Handler) updatePermission(c *gin.Context) {
 		Resource    string `json:"resource"`
 		Action      string `json:"action"`
 		Description string `json:"description"`
+		Version     int64  `json:"version"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid permission id"))
+		response.BindError(c, err)
 		return
 	}
 
 	permissionID, err := uuid.Parse(req.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid permission id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid permission id"))
+		return
+	}
+
+	version, fromHeader, err := etag.Expected(c, req.Version)
+	if err != nil {
+		response.Fail(c, err)
 		return
 	}
 
-	updated, err := h.svc.UpdatePermission(c.Request.Context(), UpdatePermissionInput{ID: permissionID, Resource: req.Resource, Action: req.Action, Description: req.Description})
+	updated, err := h.svc.UpdatePermission(c.Request.Context(), UpdatePermissionInput{ID: permissionID, Resource: req.Resource, Action: req.Action, Description: req.Description, Version: version})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("permission not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		err = etag.Conflict(err, ErrVersionConflict, fromHeader)
+		fail(c, err)
 		return
 	}
 
+	etag.Set(c, updated.Version)
 	response.Success(c, updated)
 }
 
@@ -249,22 +309,18 @@ func (h *Handler) deletePermission(c *gin.Context) {
 		ID string `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	permissionID, err := uuid.Parse(req.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid permission id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid permission id"))
 		return
 	}
 
 	if err := h.svc.DeletePermission(c.Request.Context(), DeletePermissionInput{ID: permissionID}); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, http.StatusNotFound, ErrResourceNotFound.WithMessage("permission not found"))
-			return
-		}
-		response.Error(c, http.StatusBadRequest, err)
+		fail(c, err)
 		return
 	}
 
@@ -274,8 +330,108 @@ func (h *Handler) deletePermission(c *gin.Context) {
 func (h *Handler) listPermissions(c *gin.Context) {
 	permissions, err := h.svc.ListPermissions(c.Request.Context())
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err)
+		fail(c, err)
 		return
 	}
 	response.Success(c, permissions)
 }
+
+func (h *Handler) createPolicy(c *gin.Context) {
+	var req struct {
+		RoleID      string      `json:"roleId" binding:"required"`
+		Permission  string      `json:"permission" binding:"required"`
+		Effect      string      `json:"effect" binding:"required,oneof=allow deny"`
+		Conditions  []Condition `json:"conditions"`
+		Description string      `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid role id"))
+		return
+	}
+
+	policy, err := h.svc.CreatePolicy(c.Request.Context(), CreatePolicyInput{
+		RoleID:      roleID,
+		Permission:  req.Permission,
+		Effect:      req.Effect,
+		Conditions:  req.Conditions,
+		Description: req.Description,
+	})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, policy)
+}
+
+func (h *Handler) deletePolicy(c *gin.Context) {
+	var req struct {
+		ID string `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	policyID, err := uuid.Parse(req.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid policy id"))
+		return
+	}
+
+	if err := h.svc.DeletePolicy(c.Request.Context(), DeletePolicyInput{ID: policyID}); err != nil {
+		fail(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"id": policyID})
+}
+
+func (h *Handler) listPolicies(c *gin.Context) {
+	policies, err := h.svc.ListPolicies(c.Request.Context())
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	response.Success(c, policies)
+}
+
+func (h *Handler) explain(c *gin.Context) {
+	var req struct {
+		UserID     string `json:"userId" binding:"required"`
+		Permission string `json:"permission" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	explanation, err := h.svc.Explain(c.Request.Context(), userID, req.Permission)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	response.Success(c, explanation)
+}
+
+// fail reports a service error, treating missing records as ErrResourceNotFound so
+// the response keeps the module's own error code; the status comes from the error itself.
+func fail(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) && !errors.As(err, new(*xerr.Error)) {
+		err = ErrResourceNotFound.Wrap(err)
+	}
+	response.Fail(c, err)
+}
@@ -19,6 +19,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/Jayleonc/service/pkg/ginx/response"
+	"github.com/Jayleonc/service/pkg/xerr"
 )
 
 // mockService 模拟服务层响应，便于验证 Handler 行为。
@@ -61,6 +62,18 @@ func (m *mockService) GetRolePermissions(ctx context.Context, roleID uuid.UUID)
 	return perms, args.Error(1)
 }
 
+func (m *mockService) AssignParents(ctx context.Context, input AssignRoleParentsInput) (*Role, error) {
+	args := m.Called(ctx, input)
+	role, _ := args.Get(0).(*Role)
+	return role, args.Error(1)
+}
+
+func (m *mockService) GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, roleID)
+	perms, _ := args.Get(0).([]string)
+	return perms, args.Error(1)
+}
+
 func (m *mockService) CreatePermission(ctx context.Context, input CreatePermissionInput) (*Permission, error) {
 	args := m.Called(ctx, input)
 	permission, _ := args.Get(0).(*Permission)
@@ -84,6 +97,29 @@ func (m *mockService) ListPermissions(ctx context.Context) ([]Permission, error)
 	return permissions, args.Error(1)
 }
 
+func (m *mockService) CreatePolicy(ctx context.Context, input CreatePolicyInput) (*Policy, error) {
+	args := m.Called(ctx, input)
+	policy, _ := args.Get(0).(*Policy)
+	return policy, args.Error(1)
+}
+
+func (m *mockService) DeletePolicy(ctx context.Context, input DeletePolicyInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *mockService) ListPolicies(ctx context.Context) ([]Policy, error) {
+	args := m.Called(ctx)
+	policies, _ := args.Get(0).([]Policy)
+	return policies, args.Error(1)
+}
+
+func (m *mockService) Explain(ctx context.Context, userID uuid.UUID, permission string) (*Explanation, error) {
+	args := m.Called(ctx, userID, permission)
+	explanation, _ := args.Get(0).(*Explanation)
+	return explanation, args.Error(1)
+}
+
 func newTestRouter(svc ServiceContract) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -103,10 +139,16 @@ func newTestRouter(svc ServiceContract) *gin.Engine {
 	router.POST("/v1/rbac/role/list", handler.listRoles)
 	router.POST("/v1/rbac/role/assign_permissions", handler.assignRolePermissions)
 	router.POST("/v1/rbac/role/get_permissions", handler.getRolePermissions)
+	router.POST("/v1/rbac/role/assign_parents", handler.assignRoleParents)
+	router.POST("/v1/rbac/role/get_effective_permissions", handler.getEffectivePermissions)
 	router.POST("/v1/rbac/permission/create", handler.createPermission)
 	router.POST("/v1/rbac/permission/update", handler.updatePermission)
 	router.POST("/v1/rbac/permission/delete", handler.deletePermission)
 	router.POST("/v1/rbac/permission/list", handler.listPermissions)
+	router.POST("/v1/rbac/policy/create", handler.createPolicy)
+	router.POST("/v1/rbac/policy/delete", handler.deletePolicy)
+	router.POST("/v1/rbac/policy/list", handler.listPolicies)
+	router.POST("/v1/rbac/explain", handler.explain)
 	return router
 }
 
@@ -165,10 +207,18 @@ func TestHandlerCreateRole(t *testing.T) {
 			name:    "业务错误",
 			payload: gin.H{"name": "editor"},
 			prepare: func(m *mockService) {
-				m.On("CreateRole", mock.Anything, CreateRoleInput{Name: "editor", Description: ""}).Return(nil, errors.New("failed"))
+				m.On("CreateRole", mock.Anything, CreateRoleInput{Name: "editor", Description: ""}).Return(nil, xerr.ErrBadRequest.WithMessage("role name is required"))
 			},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:    "未知错误",
+			payload: gin.H{"name": "editor"},
+			prepare: func(m *mockService) {
+				m.On("CreateRole", mock.Anything, CreateRoleInput{Name: "editor", Description: ""}).Return(nil, errors.New("failed"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tc := range cases {
@@ -232,7 +282,7 @@ func TestHandlerUpdateRole(t *testing.T) {
 			payload: gin.H{"id": roleID.String()},
 			prepare: func(m *mockService) {
 				input := UpdateRoleInput{ID: roleID}
-				m.On("UpdateRole", mock.Anything, input).Return(nil, errors.New("failed"))
+				m.On("UpdateRole", mock.Anything, input).Return(nil, xerr.ErrBadRequest.WithMessage("role name cannot be empty"))
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -392,6 +442,112 @@ func TestHandlerAssignRolePermissions(t *testing.T) {
 	}
 }
 
+// TestHandlerAssignRoleParents 检查角色继承关系维护接口的错误映射。
+func TestHandlerAssignRoleParents(t *testing.T) {
+	roleID := uuid.New()
+	cases := []struct {
+		name       string
+		payload    any
+		prepare    func(*mockService)
+		wantStatus int
+	}{
+		{
+			name:    "设置成功",
+			payload: gin.H{"roleId": roleID.String(), "parents": []string{"support"}},
+			prepare: func(m *mockService) {
+				input := AssignRoleParentsInput{RoleID: roleID, Parents: []string{"support"}}
+				m.On("AssignParents", mock.Anything, input).Return(&Role{ID: roleID}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "清空父角色",
+			payload: gin.H{"roleId": roleID.String(), "parents": []string{}},
+			prepare: func(m *mockService) {
+				input := AssignRoleParentsInput{RoleID: roleID, Parents: []string{}}
+				m.On("AssignParents", mock.Anything, input).Return(&Role{ID: roleID}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "非法角色 ID",
+			payload:    gin.H{"roleId": "bad", "parents": []string{"support"}},
+			prepare:    func(m *mockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "出现循环继承",
+			payload: gin.H{"roleId": roleID.String(), "parents": []string{"manager"}},
+			prepare: func(m *mockService) {
+				input := AssignRoleParentsInput{RoleID: roleID, Parents: []string{"manager"}}
+				m.On("AssignParents", mock.Anything, input).Return(nil, ErrRoleCycle)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:    "角色缺失",
+			payload: gin.H{"roleId": roleID.String(), "parents": []string{"support"}},
+			prepare: func(m *mockService) {
+				input := AssignRoleParentsInput{RoleID: roleID, Parents: []string{"support"}}
+				m.On("AssignParents", mock.Anything, input).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockService{}
+			tc.prepare(svc)
+			router := newTestRouter(svc)
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/role/assign_parents", tc.payload)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandlerGetEffectivePermissions 覆盖角色有效权限查询接口。
+func TestHandlerGetEffectivePermissions(t *testing.T) {
+	roleID := uuid.New()
+	cases := []struct {
+		name       string
+		payload    any
+		prepare    func(*mockService)
+		wantStatus int
+	}{
+		{
+			name:    "查询成功",
+			payload: gin.H{"roleId": roleID.String()},
+			prepare: func(m *mockService) {
+				m.On("GetEffectivePermissions", mock.Anything, roleID).Return([]string{"user:read", "user:update"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "角色缺失",
+			payload: gin.H{"roleId": roleID.String()},
+			prepare: func(m *mockService) {
+				m.On("GetEffectivePermissions", mock.Anything, roleID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockService{}
+			tc.prepare(svc)
+			router := newTestRouter(svc)
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/role/get_effective_permissions", tc.payload)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
 // TestHandlerGetRolePermissions 覆盖角色权限查询接口。
 func TestHandlerGetRolePermissions(t *testing.T) {
 	roleID := uuid.New()
@@ -466,7 +622,7 @@ func TestHandlerCreatePermission(t *testing.T) {
 			payload: gin.H{"resource": "system", "action": "view"},
 			prepare: func(m *mockService) {
 				input := CreatePermissionInput{Resource: "system", Action: "view"}
-				m.On("CreatePermission", mock.Anything, input).Return(nil, errors.New("failed"))
+				m.On("CreatePermission", mock.Anything, input).Return(nil, ErrInvalidPermission)
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -625,3 +781,176 @@ func TestHandlerListPermissions(t *testing.T) {
 		})
 	}
 }
+
+// TestHandlerCreatePolicy 覆盖策略创建接口。
+func TestHandlerCreatePolicy(t *testing.T) {
+	roleID := uuid.New()
+	conditions := []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}}
+	cases := []struct {
+		name       string
+		payload    any
+		prepare    func(*mockService)
+		wantStatus int
+	}{
+		{
+			name:    "创建成功",
+			payload: gin.H{"roleId": roleID.String(), "permission": "user:update", "effect": "allow", "conditions": conditions},
+			prepare: func(m *mockService) {
+				m.On("CreatePolicy", mock.Anything, CreatePolicyInput{RoleID: roleID, Permission: "user:update", Effect: "allow", Conditions: conditions}).
+					Return(&Policy{ID: uuid.New(), RoleID: roleID, Permission: "user:update", Effect: EffectAllow}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "效果无效",
+			payload:    gin.H{"roleId": roleID.String(), "permission": "user:update", "effect": "maybe"},
+			prepare:    func(*mockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "角色不存在",
+			payload: gin.H{"roleId": roleID.String(), "permission": "user:update", "effect": "deny"},
+			prepare: func(m *mockService) {
+				m.On("CreatePolicy", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockService{}
+			tc.prepare(svc)
+			router := newTestRouter(svc)
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/policy/create", tc.payload)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandlerCreatePolicyInvalidCondition 验证服务层拒绝的非法条件以 400 返回，而不是内部错误。
+func TestHandlerCreatePolicyInvalidCondition(t *testing.T) {
+	roleID := uuid.New()
+	cases := []struct {
+		name      string
+		condition gin.H
+	}{
+		{name: "属性路径缺少命名空间", condition: gin.H{"field": "ownerId", "op": "eq", "value": "x"}},
+		{name: "不支持的操作符", condition: gin.H{"field": "resource.ownerId", "op": "like", "value": "x"}},
+		{name: "exists 携带取值", condition: gin.H{"field": "resource.ownerId", "op": "exists", "value": "x"}},
+		{name: "同时缺少 value 与 ref", condition: gin.H{"field": "resource.ownerId", "op": "eq"}},
+		{name: "非法引用路径", condition: gin.H{"field": "resource.ownerId", "op": "eq", "ref": "owner"}},
+		{name: "in 的取值不是列表", condition: gin.H{"field": "request.ip", "op": "in", "value": "127.0.0.1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			router := newTestRouter(newMockService(repo))
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/policy/create", gin.H{
+				"roleId":     roleID.String(),
+				"permission": "article:update",
+				"effect":     "allow",
+				"conditions": []gin.H{tc.condition},
+			})
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			require.Equal(t, xerr.ErrBadRequest.Code, decodeResponse(t, recorder).Code)
+			repo.AssertNotCalled(t, "CreatePolicy", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestHandlerDeletePolicy 覆盖策略删除接口。
+func TestHandlerDeletePolicy(t *testing.T) {
+	policyID := uuid.New()
+	cases := []struct {
+		name       string
+		payload    any
+		prepare    func(*mockService)
+		wantStatus int
+	}{
+		{
+			name:    "删除成功",
+			payload: gin.H{"id": policyID.String()},
+			prepare: func(m *mockService) {
+				m.On("DeletePolicy", mock.Anything, DeletePolicyInput{ID: policyID}).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "无效ID",
+			payload:    gin.H{"id": "bad"},
+			prepare:    func(*mockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "策略不存在",
+			payload: gin.H{"id": policyID.String()},
+			prepare: func(m *mockService) {
+				m.On("DeletePolicy", mock.Anything, DeletePolicyInput{ID: policyID}).Return(gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockService{}
+			tc.prepare(svc)
+			router := newTestRouter(svc)
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/policy/delete", tc.payload)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandlerExplain 覆盖授权诊断接口的响应行为。
+func TestHandlerExplain(t *testing.T) {
+	userID := uuid.New()
+	cases := []struct {
+		name       string
+		payload    any
+		prepare    func(*mockService)
+		wantStatus int
+	}{
+		{
+			name:    "诊断成功",
+			payload: gin.H{"userId": userID.String(), "permission": "user:update"},
+			prepare: func(m *mockService) {
+				m.On("Explain", mock.Anything, userID, "user:update").Return(&Explanation{UserID: userID, Permission: "user:update"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "无效用户ID",
+			payload:    gin.H{"userId": "bad", "permission": "user:update"},
+			prepare:    func(*mockService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "非法权限键",
+			payload: gin.H{"userId": userID.String(), "permission": "bad"},
+			prepare: func(m *mockService) {
+				m.On("Explain", mock.Anything, userID, "bad").Return(nil, ErrInvalidPermission)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockService{}
+			tc.prepare(svc)
+			router := newTestRouter(svc)
+
+			recorder := performJSONRequest(t, router, http.MethodPost, "/v1/rbac/explain", tc.payload)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
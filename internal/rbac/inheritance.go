@@ -0,0 +1,90 @@
+package rbac
+
+import "github.com/google/uuid"
+
+// roleGraph indexes roles and their declared parents for inheritance resolution.
+type roleGraph struct {
+	roles   map[uuid.UUID]*Role
+	parents map[uuid.UUID][]uuid.UUID
+}
+
+func newRoleGraph(roles []Role) *roleGraph {
+	graph := &roleGraph{
+		roles:   make(map[uuid.UUID]*Role, len(roles)),
+		parents: make(map[uuid.UUID][]uuid.UUID, len(roles)),
+	}
+	for i := range roles {
+		role := &roles[i]
+		graph.roles[role.ID] = role
+		ids := make([]uuid.UUID, 0, len(role.Parents))
+		for _, parent := range role.Parents {
+			if parent == nil {
+				continue
+			}
+			ids = append(ids, parent.ID)
+		}
+		graph.parents[role.ID] = ids
+	}
+	return graph
+}
+
+// closure returns the starting roles followed by every ancestor, each visited once.
+func (g *roleGraph) closure(start ...uuid.UUID) []*Role {
+	seen := make(map[uuid.UUID]struct{}, len(start))
+	queue := append([]uuid.UUID{}, start...)
+	result := make([]*Role, 0, len(start))
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if role, ok := g.roles[id]; ok {
+			result = append(result, role)
+		}
+		queue = append(queue, g.parents[id]...)
+	}
+	return result
+}
+
+// wouldCycle reports whether assigning parents to roleID makes roleID its own ancestor.
+func (g *roleGraph) wouldCycle(roleID uuid.UUID, parents []uuid.UUID) bool {
+	seen := make(map[uuid.UUID]struct{}, len(g.roles))
+	stack := append([]uuid.UUID{}, parents...)
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == roleID {
+			return true
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		stack = append(stack, g.parents[id]...)
+	}
+	return false
+}
+
+// effectivePermissionKeys collects the permission keys granted to the roles and all their ancestors.
+func (g *roleGraph) effectivePermissionKeys(start ...uuid.UUID) []string {
+	seen := make(map[string]struct{})
+	keys := make([]string, 0)
+	for _, role := range g.closure(start...) {
+		for _, permission := range role.Permissions {
+			if permission == nil {
+				continue
+			}
+			key := PermissionKey(permission.Resource, permission.Action)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,113 @@
+package rbac
+
+import "strings"
+
+const (
+	// Wildcard 匹配任意资源或操作。
+	Wildcard = "*"
+
+	resourceSeparator = "."
+)
+
+// MatchPermission reports whether a granted permission pattern covers the required permission key.
+//
+// Resources form a dotted hierarchy: a grant on "rbac" also covers "rbac.role" and "rbac.permission".
+// A "*" segment matches exactly one resource segment, while a trailing "*" matches one or more
+// descendants, so "rbac.*" covers "rbac.role" but not "rbac" itself. A bare "*" matches every
+// resource. Actions are flat and only support the "*" wildcard.
+func MatchPermission(granted, required string) bool {
+	grantedResource, grantedAction, ok := ParsePermissionKey(granted)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := ParsePermissionKey(required)
+	if !ok {
+		return false
+	}
+	return matchAction(grantedAction, requiredAction) && matchResource(grantedResource, requiredResource)
+}
+
+// MatchAnyPermission reports whether any of the granted patterns covers the required permission key.
+func MatchAnyPermission(granted []string, required string) bool {
+	for _, pattern := range granted {
+		if MatchPermission(pattern, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWildcardPermission reports whether the permission key contains a wildcard segment.
+func IsWildcardPermission(key string) bool {
+	resource, action, ok := ParsePermissionKey(key)
+	if !ok {
+		return false
+	}
+	if action == Wildcard {
+		return true
+	}
+	for _, segment := range strings.Split(resource, resourceSeparator) {
+		if segment == Wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAction(pattern, action string) bool {
+	return pattern == Wildcard || pattern == action
+}
+
+func matchResource(pattern, resource string) bool {
+	if pattern == Wildcard {
+		return true
+	}
+
+	patternSegments := strings.Split(pattern, resourceSeparator)
+	resourceSegments := strings.Split(resource, resourceSeparator)
+	last := len(patternSegments) - 1
+
+	for i, segment := range patternSegments {
+		if i >= len(resourceSegments) {
+			return false
+		}
+		if segment == Wildcard {
+			if i == last {
+				return true
+			}
+			continue
+		}
+		if segment != resourceSegments[i] {
+			return false
+		}
+	}
+
+	// 模式是资源的前缀：父级资源授权覆盖全部子资源。
+	return true
+}
+
+func validResourcePattern(resource string) bool {
+	if resource == "" {
+		return false
+	}
+	for _, segment := range strings.Split(resource, resourceSeparator) {
+		if !validSegment(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+func validActionPattern(action string) bool {
+	return !strings.Contains(action, resourceSeparator) && validSegment(action)
+}
+
+func validSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if segment == Wildcard {
+		return true
+	}
+	return !strings.ContainsAny(segment, "*: \t\r\n")
+}
@@ -0,0 +1,121 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePermissionKey 校验权限键的解析与规范化规则。
+func TestParsePermissionKey(t *testing.T) {
+	cases := []struct {
+		name         string
+		key          string
+		wantResource string
+		wantAction   string
+		wantOK       bool
+	}{
+		{name: "标准权限键", key: "user:read", wantResource: "user", wantAction: "read", wantOK: true},
+		{name: "大小写与空白规范化", key: "  RBAC.Role : Create ", wantResource: "rbac.role", wantAction: "create", wantOK: true},
+		{name: "操作通配符", key: "user:*", wantResource: "user", wantAction: "*", wantOK: true},
+		{name: "资源通配符", key: "*:read", wantResource: "*", wantAction: "read", wantOK: true},
+		{name: "层级通配符", key: "rbac.*:*", wantResource: "rbac.*", wantAction: "*", wantOK: true},
+		{name: "中间段通配符", key: "*.role:list", wantResource: "*.role", wantAction: "list", wantOK: true},
+		{name: "空字符串", key: "   ", wantOK: false},
+		{name: "缺少操作", key: "user", wantOK: false},
+		{name: "操作为空", key: "user:", wantOK: false},
+		{name: "资源为空", key: ":read", wantOK: false},
+		{name: "空资源段", key: "rbac..role:read", wantOK: false},
+		{name: "资源尾部分隔符", key: "rbac.:read", wantOK: false},
+		{name: "部分通配符", key: "us*er:read", wantOK: false},
+		{name: "操作包含层级", key: "user:read.all", wantOK: false},
+		{name: "多余的冒号", key: "user:read:all", wantOK: false},
+		{name: "资源包含空白", key: "user profile:read", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource, action, ok := ParsePermissionKey(tc.key)
+			require.Equal(t, tc.wantOK, ok)
+			if !tc.wantOK {
+				return
+			}
+			require.Equal(t, tc.wantResource, resource)
+			require.Equal(t, tc.wantAction, action)
+		})
+	}
+}
+
+// TestMatchPermission 覆盖通配符与层级资源的匹配矩阵。
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		name     string
+		granted  string
+		required string
+		want     bool
+	}{
+		{name: "精确匹配", granted: "user:read", required: "user:read", want: true},
+		{name: "大小写不敏感", granted: "USER:Read", required: "user:READ", want: true},
+		{name: "操作不同", granted: "user:read", required: "user:update", want: false},
+		{name: "资源不同", granted: "user:read", required: "system:read", want: false},
+		{name: "操作通配符", granted: "user:*", required: "user:delete", want: true},
+		{name: "操作通配符不跨资源", granted: "user:*", required: "system:delete", want: false},
+		{name: "资源通配符", granted: "*:read", required: "rbac.role:read", want: true},
+		{name: "资源通配符限定操作", granted: "*:read", required: "user:update", want: false},
+		{name: "全局通配符", granted: "*:*", required: "rbac.permission:delete", want: true},
+		{name: "父资源覆盖子资源", granted: "rbac:list", required: "rbac.role:list", want: true},
+		{name: "父资源覆盖多级子资源", granted: "rbac:list", required: "rbac.role.member:list", want: true},
+		{name: "父资源覆盖自身", granted: "rbac:list", required: "rbac:list", want: true},
+		{name: "子资源不覆盖父资源", granted: "rbac.role:list", required: "rbac:list", want: false},
+		{name: "子资源不覆盖兄弟资源", granted: "rbac.role:list", required: "rbac.permission:list", want: false},
+		{name: "前缀相同但非层级", granted: "rbac:list", required: "rbacx:list", want: false},
+		{name: "尾部通配符覆盖子资源", granted: "rbac.*:*", required: "rbac.role:create", want: true},
+		{name: "尾部通配符覆盖多级子资源", granted: "rbac.*:*", required: "rbac.role.member:create", want: true},
+		{name: "尾部通配符不覆盖父资源", granted: "rbac.*:*", required: "rbac:create", want: false},
+		{name: "尾部通配符不跨层级根", granted: "rbac.*:*", required: "user:create", want: false},
+		{name: "中间通配符匹配单段", granted: "*.role:list", required: "rbac.role:list", want: true},
+		{name: "中间通配符要求后续段", granted: "*.role:list", required: "rbac.permission:list", want: false},
+		{name: "中间通配符要求足够层级", granted: "*.role:list", required: "rbac:list", want: false},
+		{name: "具体授权不满足通配符需求", granted: "user:read", required: "user:*", want: false},
+		{name: "通配符授权满足通配符需求", granted: "user:*", required: "user:*", want: true},
+		{name: "无效授权", granted: "user", required: "user:read", want: false},
+		{name: "无效需求", granted: "*:*", required: "user", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, MatchPermission(tc.granted, tc.required))
+		})
+	}
+}
+
+// TestMatchAnyPermission 校验任意授权命中即放行。
+func TestMatchAnyPermission(t *testing.T) {
+	granted := []string{"user:read", "rbac.*:list"}
+
+	require.True(t, MatchAnyPermission(granted, "user:read"))
+	require.True(t, MatchAnyPermission(granted, "rbac.role:list"))
+	require.False(t, MatchAnyPermission(granted, "rbac:list"))
+	require.False(t, MatchAnyPermission(nil, "user:read"))
+}
+
+// TestIsWildcardPermission 校验通配符权限识别。
+func TestIsWildcardPermission(t *testing.T) {
+	cases := []struct {
+		key  string
+		want bool
+	}{
+		{key: "user:read", want: false},
+		{key: "rbac.role:list", want: false},
+		{key: "user:*", want: true},
+		{key: "*:read", want: true},
+		{key: "rbac.*:list", want: true},
+		{key: "invalid", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.key, func(t *testing.T) {
+			require.Equal(t, tc.want, IsWildcardPermission(tc.key))
+		})
+	}
+}
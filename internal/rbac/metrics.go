@@ -0,0 +1,17 @@
+package rbac
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Jayleonc/service/pkg/observe/metrics"
+)
+
+// permissionDenialsTotal counts requests rejected by the permission middleware. Permission keys come
+// from route declarations, so the label set stays bounded.
+var permissionDenialsTotal = metrics.Register(prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "rbac_permission_denials_total",
+		Help: "Requests denied by the permission middleware, by permission key.",
+	},
+	[]string{"permission"},
+))
@@ -2,24 +2,47 @@ package rbac
 
 import (
 	"context"
-	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Jayleonc/service/internal/feature"
-	"github.com/Jayleonc/service/pkg/constant"
 	"github.com/Jayleonc/service/pkg/ginx/response"
 	"github.com/Jayleonc/service/pkg/xerr"
 )
 
-// PermissionChecker describes the ability to validate whether a user possesses a permission.
+// tracer creates the permission check spans; they are no-ops unless tracing is enabled.
+var tracer = otel.Tracer("github.com/Jayleonc/service/internal/rbac")
+
+// PermissionChecker computes route-level permission decisions; Service.CheckPermission is the
+// production implementation.
 type PermissionChecker interface {
-	HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (Decision, error)
+}
+
+// Explainer produces an authorization trace for a user and permission.
+type Explainer interface {
+	Explain(ctx context.Context, userID uuid.UUID, permission string) (*Explanation, error)
 }
 
+// DebugHeader carries the authorization trace on denied responses when debug output is enabled.
+const DebugHeader = "X-RBAC-Debug"
+
 // NewPermissionMiddleware returns a factory that produces permission enforcement middlewares.
 func NewPermissionMiddleware(checker PermissionChecker) func(string) gin.HandlerFunc {
+	return NewDebugPermissionMiddleware(checker, nil)
+}
+
+// NewDebugPermissionMiddleware behaves like NewPermissionMiddleware and, when explainer is not
+// nil, attaches Explanation.Summary to denied responses through DebugHeader. It is meant for
+// development only because the header reveals the caller's roles and grants.
+func NewDebugPermissionMiddleware(checker PermissionChecker, explainer Explainer) func(string) gin.HandlerFunc {
 	if checker == nil {
 		return nil
 	}
@@ -33,31 +56,94 @@ func NewPermissionMiddleware(checker PermissionChecker) func(string) gin.Handler
 
 			session, ok := feature.GetAuthContext(c)
 			if !ok {
-				response.Error(c, http.StatusUnauthorized, xerr.ErrUnauthorized.WithMessage("missing authorization context"))
+				response.Fail(c, xerr.ErrUnauthorized.WithMessage("missing authorization context"))
 				c.Abort()
 				return
 			}
 
-			for _, role := range session.Roles {
-				if NormalizeRoleName(role) == NormalizeRoleName(constant.RoleAdmin) {
-					c.Next()
-					return
-				}
-			}
+			// 暴露请求属性，供资源级策略在 handler 中通过 Authorize 引用。
+			c.Request = c.Request.WithContext(WithRequestAttributes(c.Request.Context(), Attributes{
+				"ip":     c.ClientIP(),
+				"method": c.Request.Method,
+				"path":   c.FullPath(),
+			}))
 
-			allowed, err := checker.HasPermission(c.Request.Context(), session.UserID, permission)
+			ctx, span := tracer.Start(c.Request.Context(), "rbac.check_permission", trace.WithAttributes(
+				semconv.EnduserID(session.UserID.String()),
+				attribute.String("rbac.permission", permission),
+			))
+			decision, err := checker.CheckPermission(ctx, session.UserID, permission)
+			allowed := decision.Allowed
+			span.SetAttributes(attribute.Bool("rbac.allowed", allowed), attribute.Bool("rbac.pending", decision.Pending))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
 			if err != nil {
-				response.Error(c, http.StatusInternalServerError, err)
+				response.Fail(c, err)
 				c.Abort()
 				return
 			}
 			if !allowed {
-				response.Error(c, http.StatusForbidden, ErrPermissionDenied)
+				permissionDenialsTotal.WithLabelValues(permission).Inc()
+				log.Debug(c.Request.Context(), "permission denied", "permission", permission, "userId", session.UserID.String(), "path", c.FullPath())
+				if explainer != nil {
+					if explanation, err := explainer.Explain(c.Request.Context(), session.UserID, permission); err == nil {
+						c.Header(DebugHeader, explanation.Summary())
+					}
+				}
+				response.Fail(c, ErrPermissionDenied)
 				c.Abort()
 				return
 			}
 
+			if !decision.Pending {
+				c.Next()
+				return
+			}
+
+			// 仅凭依赖资源属性的条件策略放行时，只有声明了资源级校验的路由才交给 handler 调用 Authorize，
+			// 其余路由无法确认资源条件，按拒绝处理。
+			if !feature.AuthorizesResource(c) {
+				permissionDenialsTotal.WithLabelValues(permission).Inc()
+				log.Warn(c.Request.Context(), "conditional policy references the resource but the route does not call Authorize", "permission", permission, "userId", session.UserID.String(), "path", c.FullPath())
+				response.Fail(c, ErrPermissionDenied)
+				c.Abort()
+				return
+			}
+			key, _ := NormalizePermissionKey(permission)
+			pending := &pendingAuthorization{permission: key}
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), pendingAuthorizationKey{}, pending))
 			c.Next()
+			if !pending.resolved.Load() {
+				log.Warn(c.Request.Context(), "route admitted by conditional policy but Authorize was not called", "permission", permission, "path", c.FullPath())
+			}
 		}
 	}
 }
+
+type pendingAuthorizationKey struct{}
+
+// pendingAuthorization tracks a route admitted by a conditional allow until Authorize runs.
+type pendingAuthorization struct {
+	permission string
+	resolved   atomic.Bool
+}
+
+// RequiresAuthorize reports whether the route was admitted by an allow policy whose conditions
+// reference the resource and the handler has not called Authorize for that permission yet.
+func RequiresAuthorize(ctx context.Context) bool {
+	pending, ok := ctx.Value(pendingAuthorizationKey{}).(*pendingAuthorization)
+	return ok && !pending.resolved.Load()
+}
+
+func resolvePendingAuthorization(ctx context.Context, permission string) {
+	pending, ok := ctx.Value(pendingAuthorizationKey{}).(*pendingAuthorization)
+	if !ok {
+		return
+	}
+	if required, valid := NormalizePermissionKey(permission); valid && required == pending.permission {
+		pending.resolved.Store(true)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -21,10 +22,10 @@ type mockPermissionChecker struct {
 	mock.Mock
 }
 
-func (m *mockPermissionChecker) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+func (m *mockPermissionChecker) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (Decision, error) {
 	args := m.Called(ctx, userID, permission)
-	allowed, _ := args.Get(0).(bool)
-	return allowed, args.Error(1)
+	decision, _ := args.Get(0).(Decision)
+	return decision, args.Error(1)
 }
 
 // TestPermissionMiddleware 验证权限中间件的三种关键场景。
@@ -46,7 +47,7 @@ func TestPermissionMiddleware(t *testing.T) {
 				Roles:  []string{"user"},
 			},
 			prepare: func(m *mockPermissionChecker) {
-				m.On("HasPermission", mock.Anything, mock.Anything, permissionKey).Return(true, nil)
+				m.On("CheckPermission", mock.Anything, mock.Anything, permissionKey).Return(Decision{Allowed: true}, nil)
 			},
 			wantStatus: http.StatusOK,
 			expectCall: true,
@@ -58,19 +59,22 @@ func TestPermissionMiddleware(t *testing.T) {
 				Roles:  []string{"user"},
 			},
 			prepare: func(m *mockPermissionChecker) {
-				m.On("HasPermission", mock.Anything, mock.Anything, permissionKey).Return(false, nil)
+				m.On("CheckPermission", mock.Anything, mock.Anything, permissionKey).Return(Decision{}, nil)
 			},
 			wantStatus: http.StatusForbidden,
 			expectCall: true,
 		},
 		{
-			name: "管理员直接放行",
+			name: "管理员同样经过权限校验",
 			session: &feature.AuthContext{
 				UserID: uuid.New(),
 				Roles:  []string{constant.RoleAdmin},
 			},
-			prepare:    func(m *mockPermissionChecker) {},
-			wantStatus: http.StatusOK,
+			prepare: func(m *mockPermissionChecker) {
+				m.On("CheckPermission", mock.Anything, mock.Anything, permissionKey).Return(Decision{Reason: "denied by policy"}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+			expectCall: true,
 		},
 	}
 
@@ -99,10 +103,149 @@ func TestPermissionMiddleware(t *testing.T) {
 
 			require.Equal(t, tc.wantStatus, recorder.Code)
 			if tc.expectCall {
-				checker.AssertCalled(t, "HasPermission", mock.Anything, tc.session.UserID, permissionKey)
+				checker.AssertCalled(t, "CheckPermission", mock.Anything, tc.session.UserID, permissionKey)
 			} else {
-				checker.AssertNotCalled(t, "HasPermission", mock.Anything, mock.Anything, permissionKey)
+				checker.AssertNotCalled(t, "CheckPermission", mock.Anything, mock.Anything, permissionKey)
 			}
 		})
 	}
 }
+
+// TestPermissionMiddlewareWithService 通过真实的 Service 验证路由级校验的判定顺序。
+func TestPermissionMiddlewareWithService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	permissionKey := "article:update"
+	roleIDs := []uuid.UUID{uuid.New()}
+
+	cases := []struct {
+		name          string
+		roles         []string
+		inherited     []string
+		policies      []Policy
+		granted       bool
+		declared      bool
+		authorize     Attributes
+		wantStatus    int
+		wantPending   bool
+		wantGrantCall bool
+	}{
+		{
+			name:       "管理员命中拒绝策略",
+			roles:      []string{constant.RoleAdmin},
+			policies:   []Policy{{ID: uuid.New(), Permission: "article:*", Effect: EffectDeny}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "管理员命中条件成立的拒绝策略",
+			roles:      []string{constant.RoleAdmin},
+			policies:   []Policy{{ID: uuid.New(), Permission: "article:*", Effect: EffectDeny, Conditions: []Condition{{Field: "request.method", Operator: OpEq, Value: http.MethodGet}}}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "管理员未命中拒绝策略",
+			roles:      []string{constant.RoleAdmin},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "继承自管理员的角色同样绕过校验",
+			roles:      []string{"OPS"},
+			inherited:  []string{constant.RoleAdmin},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "继承自管理员的角色命中拒绝策略",
+			roles:      []string{"OPS"},
+			inherited:  []string{constant.RoleAdmin},
+			policies:   []Policy{{ID: uuid.New(), Permission: "article:*", Effect: EffectDeny}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:          "条件不成立的允许策略不放行",
+			roles:         []string{"EDITOR"},
+			policies:      []Policy{{ID: uuid.New(), Permission: permissionKey, Effect: EffectAllow, Conditions: []Condition{{Field: "request.method", Operator: OpEq, Value: http.MethodPost}}}},
+			wantStatus:    http.StatusForbidden,
+			wantGrantCall: true,
+		},
+		{
+			name:          "条件成立的允许策略放行",
+			roles:         []string{"EDITOR"},
+			policies:      []Policy{{ID: uuid.New(), Permission: permissionKey, Effect: EffectAllow, Conditions: []Condition{{Field: "request.method", Operator: OpEq, Value: http.MethodGet}}}},
+			wantStatus:    http.StatusOK,
+			wantGrantCall: true,
+		},
+		{
+			name:          "依赖资源的允许策略待 Authorize 确认",
+			roles:         []string{"EDITOR"},
+			policies:      []Policy{{ID: uuid.New(), Permission: permissionKey, Effect: EffectAllow, Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}}}},
+			declared:      true,
+			wantStatus:    http.StatusOK,
+			wantPending:   true,
+			wantGrantCall: true,
+		},
+		{
+			name:          "Authorize 拒绝依赖资源的允许策略",
+			roles:         []string{"EDITOR"},
+			policies:      []Policy{{ID: uuid.New(), Permission: permissionKey, Effect: EffectAllow, Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}}}},
+			declared:      true,
+			authorize:     Attributes{"ownerId": uuid.New().String()},
+			wantStatus:    http.StatusForbidden,
+			wantPending:   true,
+			wantGrantCall: true,
+		},
+		{
+			name:          "未声明资源级校验的路由拒绝依赖资源的允许策略",
+			roles:         []string{"EDITOR"},
+			policies:      []Policy{{ID: uuid.New(), Permission: permissionKey, Effect: EffectAllow, Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}}}},
+			wantStatus:    http.StatusForbidden,
+			wantGrantCall: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID := uuid.New()
+			repo := &mockRepository{}
+			repo.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+			repo.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(tc.policies, nil)
+			if !slices.Contains(tc.roles, constant.RoleAdmin) {
+				var stored []Role
+				for _, name := range append(slices.Clone(tc.roles), tc.inherited...) {
+					stored = append(stored, Role{Name: name})
+				}
+				repo.On("FindRolesByIDs", mock.Anything, roleIDs).Return(stored, nil)
+			}
+			if tc.wantGrantCall {
+				repo.On("UserHasPermission", mock.Anything, userID, permissionKey).Return(tc.granted, nil)
+			}
+			svc := newMockService(repo)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				feature.SetAuthContext(c, feature.AuthContext{UserID: userID, Roles: tc.roles})
+			})
+			var pending bool
+			handlers := []gin.HandlerFunc{NewPermissionMiddleware(svc)(permissionKey)}
+			if tc.declared {
+				handlers = append([]gin.HandlerFunc{feature.MarkAuthorizesResource}, handlers...)
+			}
+			handlers = append(handlers, func(c *gin.Context) {
+				pending = RequiresAuthorize(c.Request.Context())
+				if tc.authorize != nil {
+					if err := svc.Authorize(c.Request.Context(), permissionKey, tc.authorize); err != nil {
+						response.Fail(c, err)
+						return
+					}
+				}
+				response.Success(c, gin.H{"ok": true})
+			})
+			router.GET("/articles/:id", handlers...)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/articles/1", nil))
+
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			require.Equal(t, tc.wantPending, pending)
+			repo.AssertExpectations(t)
+		})
+	}
+}
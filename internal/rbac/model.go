@@ -12,6 +12,7 @@ type Permission struct {
 	Resource    string    `gorm:"size:255;index:idx_permissions_resource_action,unique"`
 	Action      string    `gorm:"size:255;index:idx_permissions_resource_action,unique"`
 	Description string    `gorm:"size:512"`
+	model.Versioned
 	model.Base
 }
 
@@ -26,6 +27,9 @@ type Role struct {
 	Name        string        `gorm:"size:255;uniqueIndex"`
 	Description string        `gorm:"size:512"`
 	Permissions []*Permission `gorm:"many2many:role_permission;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// Parents lists the roles whose permissions this role inherits.
+	Parents []*Role `gorm:"many2many:role_parent;joinForeignKey:RoleID;joinReferences:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	model.Versioned
 	model.Base
 }
 
@@ -33,3 +37,14 @@ type Role struct {
 func (Role) TableName() string {
 	return "role"
 }
+
+// RoleParent records that RoleID inherits every permission granted to ParentID.
+type RoleParent struct {
+	RoleID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ParentID uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
+
+// TableName overrides the default gorm table name.
+func (RoleParent) TableName() string {
+	return "role_parent"
+}
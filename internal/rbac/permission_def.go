@@ -5,6 +5,7 @@ const (
 	ResourceUser           = "user"
 	ResourceRBACRole       = "rbac.role"
 	ResourceRBACPermission = "rbac.permission"
+	ResourceRBACPolicy     = "rbac.policy"
 	ResourceSystem         = "system"
 )
 
@@ -17,8 +18,16 @@ const (
 	ActionList              = "list"
 	ActionAssignRoles       = "assign_roles"
 	ActionAssignPermissions = "assign_permissions"
+	ActionAssignParents     = "assign_parents"
 	ActionViewPermissions   = "view_permissions"
 	ActionAdmin             = "admin"
+	ActionExplain           = "explain"
+	ActionRestore           = "restore"
+	ActionPurge             = "purge"
+	ActionChangeStatus      = "change_status"
+	ActionInvite            = "invite"
+	ActionImport            = "import"
+	ActionExport            = "export"
 )
 
 // PermissionKey 将资源与操作组合为权限键。
@@ -0,0 +1,436 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/model"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// Policy effects.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Condition operators.
+const (
+	OpEq        = "eq"
+	OpNe        = "ne"
+	OpIn        = "in"
+	OpNotIn     = "not_in"
+	OpContains  = "contains"
+	OpExists    = "exists"
+	OpNotExists = "not_exists"
+	OpGt        = "gt"
+	OpGte       = "gte"
+	OpLt        = "lt"
+	OpLte       = "lte"
+)
+
+// Attribute namespaces available to policy conditions.
+const (
+	NamespaceSubject  = "subject"
+	NamespaceResource = "resource"
+	NamespaceRequest  = "request"
+)
+
+// Policy attaches a conditional allow or deny rule for a permission pattern to a role.
+// Unlike plain role permissions, a policy only applies when all of its conditions hold.
+type Policy struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	RoleID      uuid.UUID   `gorm:"type:uuid;index"`
+	Permission  string      `gorm:"size:255;index"`
+	Effect      string      `gorm:"size:16"`
+	Conditions  []Condition `gorm:"serializer:json"`
+	Description string      `gorm:"size:512"`
+	model.Base
+}
+
+// TableName overrides the default gorm table name.
+func (Policy) TableName() string {
+	return "policy"
+}
+
+// Condition compares an attribute against a literal value or another attribute.
+//
+// Field and Ref are dotted attribute paths such as "subject.userId", "resource.ownerId"
+// or "request.ip". Exactly one of Value or Ref is used as the right-hand side, except for
+// the exists/not_exists operators which take neither.
+type Condition struct {
+	Field    string `json:"field"`
+	Operator string `json:"op"`
+	Value    any    `json:"value,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+}
+
+// Attributes is a bag of named values describing a subject, resource or request.
+type Attributes map[string]any
+
+// Decision describes the outcome of a policy evaluation.
+type Decision struct {
+	Allowed  bool       `json:"allowed"`
+	Reason   string     `json:"reason"`
+	PolicyID *uuid.UUID `json:"policyId,omitempty"`
+	// Pending marks a route-level allow that relies on conditions over resource attributes; the
+	// handler must confirm it with Authorize once the resource is loaded. The permission
+	// middleware denies it on routes that do not declare feature.RouteDefinition.AuthorizesResource.
+	Pending bool `json:"pending,omitempty"`
+}
+
+type subjectAttributesKey struct{}
+
+type requestAttributesKey struct{}
+
+// WithSubjectAttributes stores extra subject attributes (for example a department loaded from the
+// user profile) that policies can reference through the "subject." namespace.
+func WithSubjectAttributes(ctx context.Context, attrs Attributes) context.Context {
+	return context.WithValue(ctx, subjectAttributesKey{}, mergeAttributes(subjectAttributesFromContext(ctx), attrs))
+}
+
+// WithRequestAttributes stores request attributes referenced through the "request." namespace.
+func WithRequestAttributes(ctx context.Context, attrs Attributes) context.Context {
+	return context.WithValue(ctx, requestAttributesKey{}, mergeAttributes(requestAttributesFromContext(ctx), attrs))
+}
+
+func subjectAttributesFromContext(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(subjectAttributesKey{}).(Attributes)
+	return attrs
+}
+
+func requestAttributesFromContext(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(requestAttributesKey{}).(Attributes)
+	return attrs
+}
+
+func mergeAttributes(base, extra Attributes) Attributes {
+	merged := make(Attributes, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// policyEnv holds the attribute namespaces a condition is evaluated against.
+type policyEnv map[string]Attributes
+
+func newPolicyEnv(ctx context.Context, subject feature.AuthContext, resource Attributes) policyEnv {
+	subjectAttrs := mergeAttributes(subjectAttributesFromContext(ctx), Attributes{
+		"userId":    subject.UserID.String(),
+		"roles":     UniqueNormalized(subject.Roles),
+		"sessionId": subject.SessionID,
+	})
+	requestAttrs := mergeAttributes(Attributes{"time": time.Now().UTC()}, requestAttributesFromContext(ctx))
+
+	return policyEnv{
+		NamespaceSubject:  subjectAttrs,
+		NamespaceResource: resource,
+		NamespaceRequest:  requestAttrs,
+	}
+}
+
+// lookup resolves a dotted attribute path, descending into nested maps when needed.
+func (e policyEnv) lookup(path string) (any, bool) {
+	namespace, rest, ok := strings.Cut(strings.TrimSpace(path), ".")
+	if !ok || rest == "" {
+		return nil, false
+	}
+	attrs, ok := e[namespace]
+	if !ok || attrs == nil {
+		return nil, false
+	}
+
+	var current any = map[string]any(attrs)
+	for _, segment := range strings.Split(rest, ".") {
+		switch typed := current.(type) {
+		case map[string]any:
+			value, ok := typed[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case Attributes:
+			value, ok := typed[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// matches reports whether the policy applies to the required permission in the given environment.
+func (p *Policy) matches(required string, env policyEnv) bool {
+	if !MatchPermission(p.Permission, required) {
+		return false
+	}
+	for _, condition := range p.Conditions {
+		if !condition.evaluate(env) {
+			return false
+		}
+	}
+	return true
+}
+
+// routeMatch reports whether the policy applies at route level, before the handler has loaded
+// the resource. Conditions on subject and request attributes are evaluated; conditions that
+// reference the resource namespace cannot be decided yet and are reported through pending.
+func (p *Policy) routeMatch(required string, env policyEnv) (matched, pending bool) {
+	if !MatchPermission(p.Permission, required) {
+		return false, false
+	}
+	for _, condition := range p.Conditions {
+		if condition.referencesResource() {
+			pending = true
+			continue
+		}
+		if !condition.evaluate(env) {
+			return false, false
+		}
+	}
+	return true, pending
+}
+
+// unconditional reports whether the policy carries no conditions.
+func (p *Policy) unconditional() bool {
+	return len(p.Conditions) == 0
+}
+
+func (c Condition) evaluate(env policyEnv) bool {
+	left, found := env.lookup(c.Field)
+
+	switch c.Operator {
+	case OpExists:
+		return found && left != nil
+	case OpNotExists:
+		return !found || left == nil
+	}
+	if !found {
+		return false
+	}
+
+	right := c.Value
+	if c.Ref != "" {
+		value, ok := env.lookup(c.Ref)
+		if !ok {
+			return false
+		}
+		right = value
+	}
+
+	switch c.Operator {
+	case OpEq:
+		return equalValues(left, right)
+	case OpNe:
+		return !equalValues(left, right)
+	case OpIn:
+		return containsValue(right, left)
+	case OpNotIn:
+		return !containsValue(right, left)
+	case OpContains:
+		return containsValue(left, right)
+	case OpGt, OpGte, OpLt, OpLte:
+		cmp, ok := compareValues(left, right)
+		if !ok {
+			return false
+		}
+		switch c.Operator {
+		case OpGt:
+			return cmp > 0
+		case OpGte:
+			return cmp >= 0
+		case OpLt:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	default:
+		return false
+	}
+}
+
+// referencesResource reports whether the condition reads an attribute of the resource namespace.
+func (c Condition) referencesResource() bool {
+	for _, path := range []string{c.Field, c.Ref} {
+		if namespace, _, ok := strings.Cut(strings.TrimSpace(path), "."); ok && namespace == NamespaceResource {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that the condition is well formed before it is persisted. Failures are
+// reported as xerr.ErrBadRequest because conditions come from API input.
+func (c Condition) validate() error {
+	if !validAttributePath(c.Field) {
+		return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("invalid condition field %q", c.Field))
+	}
+
+	switch c.Operator {
+	case OpExists, OpNotExists:
+		if c.Value != nil || c.Ref != "" {
+			return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("operator %q does not take a value", c.Operator))
+		}
+		return nil
+	case OpEq, OpNe, OpIn, OpNotIn, OpContains, OpGt, OpGte, OpLt, OpLte:
+	default:
+		return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("unsupported condition operator %q", c.Operator))
+	}
+
+	if (c.Value == nil) == (c.Ref == "") {
+		return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("condition on %q requires exactly one of value or ref", c.Field))
+	}
+	if c.Ref != "" && !validAttributePath(c.Ref) {
+		return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("invalid condition ref %q", c.Ref))
+	}
+	if c.Value != nil && (c.Operator == OpIn || c.Operator == OpNotIn) {
+		if kind := reflect.ValueOf(c.Value).Kind(); kind != reflect.Slice && kind != reflect.Array {
+			return xerr.ErrBadRequest.WithMessage(fmt.Sprintf("operator %q requires a list value", c.Operator))
+		}
+	}
+	return nil
+}
+
+func validAttributePath(path string) bool {
+	namespace, rest, ok := strings.Cut(strings.TrimSpace(path), ".")
+	if !ok || rest == "" {
+		return false
+	}
+	switch namespace {
+	case NamespaceSubject, NamespaceResource, NamespaceRequest:
+	default:
+		return false
+	}
+	for _, segment := range strings.Split(rest, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues compares scalars by their canonical string form so that uuid.UUID, strings and
+// JSON-decoded numbers compare naturally.
+func equalValues(left, right any) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			return l == r
+		}
+	}
+	return scalarString(left) == scalarString(right)
+}
+
+// containsValue reports whether the list contains the needle.
+func containsValue(list, needle any) bool {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < value.Len(); i++ {
+		if equalValues(value.Index(i).Interface(), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareValues(left, right any) (int, bool) {
+	if l, ok := toFloat(left); ok {
+		r, ok := toFloat(right)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	if l, ok := toTime(left); ok {
+		r, ok := toTime(right)
+		if !ok {
+			return 0, false
+		}
+		return l.Compare(r), true
+	}
+	return 0, false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return parsed, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
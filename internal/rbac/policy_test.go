@@ -0,0 +1,80 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/internal/feature"
+)
+
+// TestConditionEvaluate 使用表驱动测试各类条件操作符。
+func TestConditionEvaluate(t *testing.T) {
+	userID := uuid.New()
+	ctx := WithSubjectAttributes(context.Background(), Attributes{"department": "sales", "level": 3})
+	ctx = WithRequestAttributes(ctx, Attributes{"ip": "10.0.0.8"})
+	env := newPolicyEnv(ctx, feature.AuthContext{UserID: userID, Roles: []string{"editor"}}, Attributes{
+		"ownerId":   userID.String(),
+		"tags":      []any{"public", "draft"},
+		"createdAt": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		"meta":      map[string]any{"department": "sales"},
+		"size":      42,
+	})
+
+	cases := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{name: "引用相等", condition: Condition{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}, want: true},
+		{name: "引用不等", condition: Condition{Field: "resource.ownerId", Operator: OpNe, Ref: "subject.userId"}, want: false},
+		{name: "嵌套字段", condition: Condition{Field: "resource.meta.department", Operator: OpEq, Ref: "subject.department"}, want: true},
+		{name: "角色包含", condition: Condition{Field: "subject.roles", Operator: OpContains, Value: "EDITOR"}, want: true},
+		{name: "集合内", condition: Condition{Field: "request.ip", Operator: OpIn, Value: []any{"10.0.0.8", "10.0.0.9"}}, want: true},
+		{name: "集合外", condition: Condition{Field: "subject.department", Operator: OpNotIn, Value: []any{"hr"}}, want: true},
+		{name: "数值比较", condition: Condition{Field: "resource.size", Operator: OpGt, Value: 40}, want: true},
+		{name: "跨类型数值", condition: Condition{Field: "subject.level", Operator: OpLte, Value: 3.0}, want: true},
+		{name: "时间比较", condition: Condition{Field: "resource.createdAt", Operator: OpLt, Ref: "request.time"}, want: true},
+		{name: "字段存在", condition: Condition{Field: "resource.tags", Operator: OpExists}, want: true},
+		{name: "字段不存在", condition: Condition{Field: "resource.archived", Operator: OpNotExists}, want: true},
+		{name: "缺失字段不匹配", condition: Condition{Field: "resource.archived", Operator: OpEq, Value: true}, want: false},
+		{name: "未知操作符", condition: Condition{Field: "resource.size", Operator: "like", Value: 1}, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.condition.evaluate(env))
+		})
+	}
+}
+
+// TestConditionValidate 验证条件在持久化前的格式校验。
+func TestConditionValidate(t *testing.T) {
+	cases := []struct {
+		name      string
+		condition Condition
+		wantErr   bool
+	}{
+		{name: "合法引用", condition: Condition{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}},
+		{name: "合法存在判断", condition: Condition{Field: "resource.ownerId", Operator: OpExists}},
+		{name: "未知命名空间", condition: Condition{Field: "session.id", Operator: OpEq, Value: "x"}, wantErr: true},
+		{name: "缺少右值", condition: Condition{Field: "resource.ownerId", Operator: OpEq}, wantErr: true},
+		{name: "同时提供值与引用", condition: Condition{Field: "resource.ownerId", Operator: OpEq, Value: "x", Ref: "subject.userId"}, wantErr: true},
+		{name: "集合操作符需要列表", condition: Condition{Field: "request.ip", Operator: OpIn, Value: "10.0.0.1"}, wantErr: true},
+		{name: "存在判断不接受值", condition: Condition{Field: "resource.ownerId", Operator: OpExists, Value: 1}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.condition.validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
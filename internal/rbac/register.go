@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/observe/logger"
 )
 
+// log is the RBAC module logger; set it to debug to trace denied permission checks.
+var log = logger.Module("rbac")
+
 // Register initialises the RBAC feature in a structured/DI fashion.
 func Register(ctx context.Context, deps *feature.Dependencies) error {
 	if err := deps.Require("DB", "Router"); err != nil {
@@ -19,7 +25,13 @@ func Register(ctx context.Context, deps *feature.Dependencies) error {
 		return fmt.Errorf("ensure rbac service: %w", err)
 	}
 
-	factory := NewPermissionMiddleware(svc)
+	// 仅在开发模式下且显式开启时，才在拒绝响应上附带授权诊断信息。
+	var explainer Explainer
+	if deps.Config.RBAC.DebugHeader && gin.IsDebugging() {
+		explainer = svc
+	}
+
+	factory := NewDebugPermissionMiddleware(svc, explainer)
 	if factory != nil {
 		deps.PermissionEnforcer = factory
 		deps.Router.SetPermissionEnforcerFactory(factory)
@@ -35,6 +47,12 @@ func Register(ctx context.Context, deps *feature.Dependencies) error {
 		return fmt.Errorf("ensure permissions: %w", err)
 	}
 
+	if path := deps.Config.RBAC.PolicyFile; path != "" {
+		if err := applyPolicyFile(ctx, svc, deps, path); err != nil {
+			return fmt.Errorf("apply rbac policy file: %w", err)
+		}
+	}
+
 	if err := svc.EnsureAdminHasAllPermissions(ctx); err != nil {
 		return fmt.Errorf("sync admin permissions: %w", err)
 	}
@@ -45,3 +63,25 @@ func Register(ctx context.Context, deps *feature.Dependencies) error {
 
 	return nil
 }
+
+// applyPolicyFile reconciles the database with the declarative policy file configured via rbac.policy_file.
+func applyPolicyFile(ctx context.Context, svc *Service, deps *feature.Dependencies, path string) error {
+	doc, err := LoadPolicyDocument(path)
+	if err != nil {
+		return err
+	}
+
+	plan, err := svc.ApplyPolicyDocument(ctx, doc, ApplyOptions{Prune: deps.Config.RBAC.Prune})
+	if err != nil {
+		return err
+	}
+
+	if deps.Logger != nil {
+		if plan.Empty() {
+			deps.Logger.Info("rbac policy file up to date", "file", path)
+		} else {
+			deps.Logger.Info("rbac policy file applied", "file", path, "new_permissions", len(plan.NewPermissions), "role_changes", len(plan.Changes), "plan", plan.String())
+		}
+	}
+	return nil
+}
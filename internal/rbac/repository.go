@@ -3,9 +3,13 @@ package rbac
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Jayleonc/service/pkg/model"
 )
 
 // Repository provides database access for RBAC entities.
@@ -20,7 +24,11 @@ func NewRepository(db *gorm.DB) *Repository {
 
 // Migrate ensures the required RBAC tables exist.
 func (r *Repository) Migrate(ctx context.Context) error {
-	if err := r.db.WithContext(ctx).AutoMigrate(&Permission{}, &Role{}); err != nil {
+	db := r.db.WithContext(ctx)
+	if err := db.SetupJoinTable(&Role{}, "Parents", &RoleParent{}); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&Permission{}, &Role{}, &Policy{}); err != nil {
 		return err
 	}
 	return nil
@@ -31,9 +39,10 @@ func (r *Repository) CreateRole(ctx context.Context, role *Role) error {
 	return r.db.WithContext(ctx).Create(role).Error
 }
 
-// UpdateRole updates an existing role record.
+// UpdateRole updates an existing role record, failing with model.ErrVersionConflict
+// when the stored version no longer matches role.Version.
 func (r *Repository) UpdateRole(ctx context.Context, role *Role) error {
-	return r.db.WithContext(ctx).Save(role).Error
+	return model.SaveVersioned(r.db.WithContext(ctx), role, &role.Versioned)
 }
 
 // DeleteRole deletes a role by ID.
@@ -43,6 +52,12 @@ func (r *Repository) DeleteRole(ctx context.Context, id uuid.UUID) error {
 		if err := tx.WithContext(ctx).Model(&role).Association("Permissions").Clear(); err != nil {
 			return err
 		}
+		if err := tx.WithContext(ctx).Where("role_id = ? OR parent_id = ?", id, id).Delete(&RoleParent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.WithContext(ctx).Where("role_id = ?", id).Delete(&Policy{}).Error; err != nil {
+			return err
+		}
 		return tx.WithContext(ctx).Delete(&Role{}, "id = ?", id).Error
 	})
 }
@@ -50,7 +65,7 @@ func (r *Repository) DeleteRole(ctx context.Context, id uuid.UUID) error {
 // ListRoles returns all roles ordered by creation time.
 func (r *Repository) ListRoles(ctx context.Context) ([]Role, error) {
 	var roles []Role
-	if err := r.db.WithContext(ctx).Preload("Permissions").Order("created_at ASC").Find(&roles).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Permissions").Preload("Parents").Order("created_at ASC").Find(&roles).Error; err != nil {
 		return nil, err
 	}
 	return roles, nil
@@ -59,7 +74,7 @@ func (r *Repository) ListRoles(ctx context.Context) ([]Role, error) {
 // FindRoleByID returns a role by its identifier.
 func (r *Repository) FindRoleByID(ctx context.Context, id uuid.UUID) (*Role, error) {
 	var role Role
-	if err := r.db.WithContext(ctx).Preload("Permissions").First(&role, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Permissions").Preload("Parents").First(&role, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
@@ -93,9 +108,10 @@ func (r *Repository) CreatePermission(ctx context.Context, permission *Permissio
 	return r.db.WithContext(ctx).Create(permission).Error
 }
 
-// UpdatePermission updates an existing permission record.
+// UpdatePermission updates an existing permission record, failing with model.ErrVersionConflict
+// when the stored version no longer matches permission.Version.
 func (r *Repository) UpdatePermission(ctx context.Context, permission *Permission) error {
-	return r.db.WithContext(ctx).Save(permission).Error
+	return model.SaveVersioned(r.db.WithContext(ctx), permission, &permission.Versioned)
 }
 
 // DeletePermission deletes a permission by ID.
@@ -157,32 +173,173 @@ func (r *Repository) ReplaceRolePermissions(ctx context.Context, role *Role, per
 	return r.db.WithContext(ctx).Model(role).Association("Permissions").Replace(permissions)
 }
 
-// UserHasPermission checks whether the user possesses the given permission key.
+// ReplaceRoleParents replaces the set of roles a role inherits from.
+func (r *Repository) ReplaceRoleParents(ctx context.Context, role *Role, parents []*Role) error {
+	return r.db.WithContext(ctx).Model(role).Association("Parents").Replace(parents)
+}
+
+// AssignRoleParents replaces the parents of a role after checking, inside the same transaction,
+// that the new edges do not make the role its own ancestor; it returns ErrRoleCycle otherwise.
+// Every role row is locked rather than just the role and its parents, because a cycle can close
+// through roles that neither of two concurrent assignments touches.
+func (r *Repository) AssignRoleParents(ctx context.Context, role *Role, parents []*Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		roles, err := lockRoles(tx)
+		if err != nil {
+			return err
+		}
+
+		parentIDs := make([]uuid.UUID, 0, len(parents))
+		for _, parent := range parents {
+			parentIDs = append(parentIDs, parent.ID)
+		}
+		if newRoleGraph(roles).wouldCycle(role.ID, parentIDs) {
+			return ErrRoleCycle
+		}
+		return tx.Model(role).Association("Parents").Replace(parents)
+	})
+}
+
+// Transaction runs fn with a repository whose operations all belong to one database transaction.
+// The transaction is rolled back when fn returns an error.
+func (r *Repository) Transaction(ctx context.Context, fn func(repo RepositoryContract) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
+// LockRoles locks every role row until the surrounding transaction ends, serialising the caller
+// with AssignRoleParents and other writers of the inheritance graph. It is only meaningful on a
+// repository obtained from Transaction.
+func (r *Repository) LockRoles(ctx context.Context) error {
+	_, err := lockRoles(r.db.WithContext(ctx))
+	return err
+}
+
+func lockRoles(tx *gorm.DB) ([]Role, error) {
+	var roles []Role
+	if err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Preload("Parents").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// UserHasPermission checks whether any permission granted to the user, directly or through
+// inherited roles, covers the given permission key. Wildcard and hierarchical grants are
+// resolved with MatchPermission.
 func (r *Repository) UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
-	resource, action, ok := ParsePermissionKey(permission)
+	required, ok := NormalizePermissionKey(permission)
 	if !ok {
 		return false, nil
 	}
 
-	var count int64
-	query := r.db.WithContext(ctx).
-		Table("permission").
+	roleIDs, err := r.FindUserRoleIDs(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	var granted []Permission
+	err = r.db.WithContext(ctx).
+		Model(&Permission{}).
+		Select("permission.resource", "permission.action").
 		Joins("JOIN role_permission rp ON rp.permission_id = permission.id").
-		Joins("JOIN user_role ur ON ur.role_id = rp.role_id").
-		Where("ur.user_id = ?", userID)
+		Where("rp.role_id IN ?", roleIDs).
+		Find(&granted).Error
+	if err != nil {
+		return false, err
+	}
 
-	if resource != "" {
-		query = query.Where("LOWER(permission.resource) = ?", strings.ToLower(resource))
+	for _, grant := range granted {
+		if MatchPermission(PermissionKey(grant.Resource, grant.Action), required) {
+			return true, nil
+		}
 	}
-	if action != "" {
-		query = query.Where("LOWER(permission.action) = ?", strings.ToLower(action))
+	return false, nil
+}
+
+// FindUserRoleIDs returns the identifiers of the roles currently assigned to the user together
+// with every role they inherit from. Assignments outside their validity window are ignored.
+func (r *Repository) FindUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	direct, err := r.FindActiveUserRoleIDs(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
 	}
+	return r.expandRoleAncestors(ctx, direct)
+}
 
-	if err := query.Count(&count).Error; err != nil {
-		return false, err
+// CreatePolicy persists a new policy.
+func (r *Repository) CreatePolicy(ctx context.Context, policy *Policy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// DeletePolicy deletes a policy by ID.
+func (r *Repository) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&Policy{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListPolicies returns all policies ordered by creation time.
+func (r *Repository) ListPolicies(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// FindPoliciesByRoleIDs returns the policies attached to any of the given roles.
+func (r *Repository) FindPoliciesByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) ([]Policy, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var policies []Policy
+	if err := r.db.WithContext(ctx).Where("role_id IN ?", roleIDs).Order("created_at ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// expandRoleAncestors returns the given roles together with every role they inherit from.
+func (r *Repository) expandRoleAncestors(ctx context.Context, roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]struct{}, len(roleIDs))
+	closure := make([]uuid.UUID, 0, len(roleIDs))
+	frontier := make([]uuid.UUID, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		closure = append(closure, id)
+		frontier = append(frontier, id)
+	}
+
+	for len(frontier) > 0 {
+		var parents []uuid.UUID
+		if err := r.db.WithContext(ctx).Model(&RoleParent{}).Where("role_id IN ?", frontier).Pluck("parent_id", &parents).Error; err != nil {
+			return nil, err
+		}
+		frontier = frontier[:0]
+		for _, id := range parents {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			closure = append(closure, id)
+			frontier = append(frontier, id)
+		}
 	}
 
-	return count > 0, nil
+	return closure, nil
 }
 
 func normalizeStrings(values []string) []string {
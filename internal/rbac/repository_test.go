@@ -8,17 +8,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-)
-
-// userRole 用于构造 user_roles 关联表，满足 UserHasPermission 查询需求。
-type userRole struct {
-	UserID uuid.UUID `gorm:"type:uuid"`
-	RoleID uuid.UUID `gorm:"type:uuid"`
-}
 
-func (userRole) TableName() string {
-	return "user_role"
-}
+	"github.com/Jayleonc/service/pkg/model"
+)
 
 // setupTestDB 创建独立的内存数据库并初始化基础结构。
 func setupTestDB(t *testing.T) *gorm.DB {
@@ -31,7 +23,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 	repo := NewRepository(db)
 	require.NoError(t, repo.Migrate(context.Background()))
-	require.NoError(t, db.AutoMigrate(&userRole{}))
+	// user_role 由用户模块维护，这里补建以满足 UserHasPermission 查询需求。
+	require.NoError(t, db.AutoMigrate(&UserRole{}))
 	return db
 }
 
@@ -78,6 +71,34 @@ func TestRepositoryUpdateRole(t *testing.T) {
 		var stored Role
 		require.NoError(t, tx.WithContext(ctx).First(&stored, "id = ?", original.ID).Error)
 		require.Equal(t, "高级用户", stored.Description)
+		require.Equal(t, int64(2), stored.Version)
+	})
+}
+
+// TestRepositoryUpdateRoleVersionConflict 验证基于过期版本号的更新会被拒绝且不覆盖已有修改。
+func TestRepositoryUpdateRoleVersionConflict(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		role := &Role{ID: uuid.New(), Name: "EDITOR", Description: "编辑"}
+		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+
+		first, err := repo.FindRoleByID(ctx, role.ID)
+		require.NoError(t, err)
+		second, err := repo.FindRoleByID(ctx, role.ID)
+		require.NoError(t, err)
+
+		first.Description = "第一次修改"
+		require.NoError(t, repo.UpdateRole(ctx, first))
+
+		second.Description = "第二次修改"
+		require.ErrorIs(t, repo.UpdateRole(ctx, second), model.ErrVersionConflict)
+		require.Equal(t, int64(1), second.Version)
+
+		var stored Role
+		require.NoError(t, tx.WithContext(ctx).First(&stored, "id = ?", role.ID).Error)
+		require.Equal(t, "第一次修改", stored.Description)
+		require.Equal(t, int64(2), stored.Version)
 	})
 }
 
@@ -99,7 +120,7 @@ func TestRepositoryDeleteRole(t *testing.T) {
 		require.Zero(t, count)
 
 		var relCount int64
-		require.NoError(t, tx.WithContext(ctx).Table("role_permissions").Where("role_id = ?", role.ID).Count(&relCount).Error)
+		require.NoError(t, tx.WithContext(ctx).Table("role_permission").Where("role_id = ?", role.ID).Count(&relCount).Error)
 		require.Zero(t, relCount)
 	})
 }
@@ -288,7 +309,7 @@ func TestRepositoryReplaceRolePermissions(t *testing.T) {
 		require.NoError(t, repo.ReplaceRolePermissions(ctx, role, []*Permission{newPermission}))
 
 		var relCount int64
-		require.NoError(t, tx.WithContext(ctx).Table("role_permissions").Where("role_id = ? AND permission_id = ?", role.ID, newPermission.ID).Count(&relCount).Error)
+		require.NoError(t, tx.WithContext(ctx).Table("role_permission").Where("role_id = ? AND permission_id = ?", role.ID, newPermission.ID).Count(&relCount).Error)
 		require.EqualValues(t, 1, relCount)
 	})
 }
@@ -304,7 +325,7 @@ func TestRepositoryUserHasPermission(t *testing.T) {
 		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
 		require.NoError(t, tx.WithContext(ctx).Create(permission).Error)
 		require.NoError(t, tx.WithContext(ctx).Model(role).Association("Permissions").Append(permission))
-		require.NoError(t, tx.WithContext(ctx).Create(&userRole{UserID: userID, RoleID: role.ID}).Error)
+		require.NoError(t, tx.WithContext(ctx).Create(&UserRole{UserID: userID, RoleID: role.ID}).Error)
 
 		allowed, err := repo.UserHasPermission(ctx, userID, PermissionKey("article", "approve"))
 		require.NoError(t, err)
@@ -315,3 +336,189 @@ func TestRepositoryUserHasPermission(t *testing.T) {
 		require.False(t, denied)
 	})
 }
+
+// TestRepositoryUserHasPermissionWildcard 验证通配符与层级授权在仓储查询中生效。
+func TestRepositoryUserHasPermissionWildcard(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		userID := uuid.New()
+		role := &Role{ID: uuid.New(), Name: "OPERATOR"}
+		grants := []*Permission{
+			{ID: uuid.New(), Resource: "user", Action: "*"},
+			{ID: uuid.New(), Resource: "rbac", Action: "list"},
+		}
+		require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+		for _, grant := range grants {
+			require.NoError(t, tx.WithContext(ctx).Create(grant).Error)
+		}
+		require.NoError(t, tx.WithContext(ctx).Model(role).Association("Permissions").Append(grants))
+		require.NoError(t, tx.WithContext(ctx).Create(&UserRole{UserID: userID, RoleID: role.ID}).Error)
+
+		cases := []struct {
+			permission string
+			want       bool
+		}{
+			{permission: "user:delete", want: true},
+			{permission: "USER:Assign_Roles", want: true},
+			{permission: "rbac.role:list", want: true},
+			{permission: "rbac.permission:list", want: true},
+			{permission: "rbac.role:create", want: false},
+			{permission: "system:admin", want: false},
+		}
+		for _, tc := range cases {
+			allowed, err := repo.UserHasPermission(ctx, userID, tc.permission)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, allowed, tc.permission)
+		}
+
+		other, err := repo.UserHasPermission(ctx, uuid.New(), "user:delete")
+		require.NoError(t, err)
+		require.False(t, other)
+	})
+}
+
+// TestRepositoryUserHasPermissionInherited 验证通过父角色继承的权限可被识别。
+func TestRepositoryUserHasPermissionInherited(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		userID := uuid.New()
+		viewer := &Role{ID: uuid.New(), Name: "VIEWER"}
+		support := &Role{ID: uuid.New(), Name: "SUPPORT"}
+		manager := &Role{ID: uuid.New(), Name: "MANAGER"}
+		read := &Permission{ID: uuid.New(), Resource: "ticket", Action: "read"}
+		reply := &Permission{ID: uuid.New(), Resource: "ticket", Action: "reply"}
+		for _, role := range []*Role{viewer, support, manager} {
+			require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+		}
+		require.NoError(t, tx.WithContext(ctx).Create(read).Error)
+		require.NoError(t, tx.WithContext(ctx).Create(reply).Error)
+		require.NoError(t, tx.WithContext(ctx).Model(viewer).Association("Permissions").Append(read))
+		require.NoError(t, tx.WithContext(ctx).Model(support).Association("Permissions").Append(reply))
+
+		require.NoError(t, repo.ReplaceRoleParents(ctx, support, []*Role{viewer}))
+		require.NoError(t, repo.ReplaceRoleParents(ctx, manager, []*Role{support}))
+		require.NoError(t, tx.WithContext(ctx).Create(&UserRole{UserID: userID, RoleID: manager.ID}).Error)
+
+		allowed, err := repo.UserHasPermission(ctx, userID, "ticket:read")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = repo.UserHasPermission(ctx, userID, "ticket:reply")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = repo.UserHasPermission(ctx, userID, "ticket:close")
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		stored, err := repo.FindRoleByID(ctx, manager.ID)
+		require.NoError(t, err)
+		require.Len(t, stored.Parents, 1)
+		require.Equal(t, support.ID, stored.Parents[0].ID)
+
+		require.NoError(t, repo.DeleteRole(ctx, support.ID))
+		var links int64
+		require.NoError(t, tx.WithContext(ctx).Model(&RoleParent{}).Where("role_id = ? OR parent_id = ?", support.ID, support.ID).Count(&links).Error)
+		require.Zero(t, links)
+
+		allowed, err = repo.UserHasPermission(ctx, userID, "ticket:read")
+		require.NoError(t, err)
+		require.False(t, allowed)
+	})
+}
+
+// TestRepositoryAssignRoleParents 验证父角色在写入事务内基于最新的继承关系做循环检测。
+func TestRepositoryAssignRoleParents(t *testing.T) {
+	cases := []struct {
+		name        string
+		role        string
+		parents     []string
+		wantErr     error
+		wantParents []string
+	}{
+		{name: "追加祖先以外的父角色", role: "MANAGER", parents: []string{"SUPPORT", "AUDITOR"}, wantParents: []string{"AUDITOR", "SUPPORT"}},
+		{name: "清空父角色", role: "SUPPORT", wantParents: []string{}},
+		{name: "继承自身", role: "VIEWER", parents: []string{"VIEWER"}, wantErr: ErrRoleCycle, wantParents: []string{}},
+		{name: "间接循环", role: "VIEWER", parents: []string{"MANAGER"}, wantErr: ErrRoleCycle, wantParents: []string{}},
+		{name: "拒绝后保留原有父角色", role: "SUPPORT", parents: []string{"AUDITOR", "MANAGER"}, wantErr: ErrRoleCycle, wantParents: []string{"VIEWER"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupTestDB(t)
+
+			runInTransaction(t, db, func(ctx context.Context, repo *Repository, _ *gorm.DB) {
+				// 继承链：MANAGER -> SUPPORT -> VIEWER，AUDITOR 独立。
+				roles := make(map[string]*Role)
+				for _, name := range []string{"VIEWER", "SUPPORT", "MANAGER", "AUDITOR"} {
+					roles[name] = &Role{ID: uuid.New(), Name: name}
+					require.NoError(t, repo.CreateRole(ctx, roles[name]))
+				}
+				require.NoError(t, repo.ReplaceRoleParents(ctx, roles["SUPPORT"], []*Role{roles["VIEWER"]}))
+				require.NoError(t, repo.ReplaceRoleParents(ctx, roles["MANAGER"], []*Role{roles["SUPPORT"]}))
+
+				parents := make([]*Role, 0, len(tc.parents))
+				for _, name := range tc.parents {
+					parents = append(parents, roles[name])
+				}
+				role := &Role{ID: roles[tc.role].ID, Name: tc.role}
+				err := repo.AssignRoleParents(ctx, role, parents)
+				if tc.wantErr != nil {
+					require.ErrorIs(t, err, tc.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+
+				stored, err := repo.FindRoleByID(ctx, role.ID)
+				require.NoError(t, err)
+				names := make([]string, 0, len(stored.Parents))
+				for _, parent := range stored.Parents {
+					names = append(names, parent.Name)
+				}
+				require.ElementsMatch(t, tc.wantParents, names)
+			})
+		})
+	}
+}
+
+// TestRepositoryPolicies 验证策略的增删查以及按角色闭包加载。
+func TestRepositoryPolicies(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		userID := uuid.New()
+		base := &Role{ID: uuid.New(), Name: "BASE"}
+		editor := &Role{ID: uuid.New(), Name: "EDITOR"}
+		require.NoError(t, tx.WithContext(ctx).Create(base).Error)
+		require.NoError(t, tx.WithContext(ctx).Create(editor).Error)
+		require.NoError(t, repo.ReplaceRoleParents(ctx, editor, []*Role{base}))
+		require.NoError(t, tx.WithContext(ctx).Create(&UserRole{UserID: userID, RoleID: editor.ID}).Error)
+
+		policy := &Policy{
+			ID:         uuid.New(),
+			RoleID:     base.ID,
+			Permission: "article:update",
+			Effect:     EffectAllow,
+			Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}},
+		}
+		require.NoError(t, repo.CreatePolicy(ctx, policy))
+
+		roleIDs, err := repo.FindUserRoleIDs(ctx, userID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{editor.ID, base.ID}, roleIDs)
+
+		policies, err := repo.FindPoliciesByRoleIDs(ctx, roleIDs)
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		require.Equal(t, policy.Conditions, policies[0].Conditions)
+
+		all, err := repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+
+		require.NoError(t, repo.DeletePolicy(ctx, policy.ID))
+		require.ErrorIs(t, repo.DeletePolicy(ctx, policy.ID), gorm.ErrRecordNotFound)
+	})
+}
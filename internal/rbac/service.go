@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/model"
+	"github.com/Jayleonc/service/pkg/xerr"
 )
 
 // RepositoryContract 定义了 Service 赖以运作的仓储能力。
@@ -30,7 +34,22 @@ type RepositoryContract interface {
 	FindPermissionByID(ctx context.Context, id uuid.UUID) (*Permission, error)
 	FindPermissionsByKeys(ctx context.Context, keys []string) ([]*Permission, error)
 	ReplaceRolePermissions(ctx context.Context, role *Role, permissions []*Permission) error
+	AssignRoleParents(ctx context.Context, role *Role, parents []*Role) error
+	Transaction(ctx context.Context, fn func(repo RepositoryContract) error) error
+	LockRoles(ctx context.Context) error
 	UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	FindUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	FindActiveUserRoleIDs(ctx context.Context, userID uuid.UUID, now time.Time) ([]uuid.UUID, error)
+	FindRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]Role, error)
+	FindRolePermissionGrants(ctx context.Context, roleIDs []uuid.UUID) ([]PermissionGrant, error)
+	CreatePolicy(ctx context.Context, policy *Policy) error
+	DeletePolicy(ctx context.Context, id uuid.UUID) error
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	FindPoliciesByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) ([]Policy, error)
+	SaveUserRole(ctx context.Context, assignment *UserRole) error
+	DeleteUserRole(ctx context.Context, userID, roleID uuid.UUID) error
+	ListExpiringUserRoles(ctx context.Context, from, until time.Time) ([]RoleAssignment, error)
+	DeleteExpiredUserRoles(ctx context.Context, now time.Time) ([]UserRole, error)
 }
 
 // Service orchestrates RBAC operations.
@@ -101,6 +120,8 @@ type UpdateRoleInput struct {
 	ID          uuid.UUID `json:"id" validate:"required"`
 	Name        string    `json:"name" validate:"omitempty"`
 	Description string    `json:"description" validate:"omitempty"`
+	// Version is the role version the caller last read; zero skips the check.
+	Version int64 `json:"version" validate:"omitempty"`
 }
 
 // DeleteRoleInput defines the payload required to delete a role.
@@ -121,6 +142,8 @@ type UpdatePermissionInput struct {
 	Resource    string    `json:"resource" validate:"omitempty"`
 	Action      string    `json:"action" validate:"omitempty"`
 	Description string    `json:"description" validate:"omitempty"`
+	// Version is the permission version the caller last read; zero skips the check.
+	Version int64 `json:"version" validate:"omitempty"`
 }
 
 // DeletePermissionInput defines the payload for deleting a permission.
@@ -134,11 +157,31 @@ type AssignRolePermissionsInput struct {
 	Permissions []string  `json:"permissions" validate:"required,min=1,dive,required"`
 }
 
+// AssignRoleParentsInput defines the payload for replacing the parents a role inherits from.
+type AssignRoleParentsInput struct {
+	RoleID  uuid.UUID `json:"roleId" validate:"required"`
+	Parents []string  `json:"parents" validate:"omitempty,dive,required"`
+}
+
+// CreatePolicyInput defines the payload for attaching a conditional policy to a role.
+type CreatePolicyInput struct {
+	RoleID      uuid.UUID   `json:"roleId" validate:"required"`
+	Permission  string      `json:"permission" validate:"required"`
+	Effect      string      `json:"effect" validate:"required,oneof=allow deny"`
+	Conditions  []Condition `json:"conditions" validate:"omitempty"`
+	Description string      `json:"description" validate:"omitempty"`
+}
+
+// DeletePolicyInput defines the payload for deleting a policy.
+type DeletePolicyInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
 // CreateRole creates a new role record.
 func (s *Service) CreateRole(ctx context.Context, input CreateRoleInput) (*Role, error) {
 	name := NormalizeRoleName(input.Name)
 	if name == "" {
-		return nil, fmt.Errorf("role name is required")
+		return nil, xerr.ErrBadRequest.WithMessage("role name is required")
 	}
 
 	role := &Role{
@@ -159,11 +202,14 @@ func (s *Service) UpdateRole(ctx context.Context, input UpdateRoleInput) (*Role,
 	if err != nil {
 		return nil, err
 	}
+	if input.Version != 0 && role.Version != input.Version {
+		return nil, ErrVersionConflict
+	}
 
 	if input.Name != "" {
 		normalized := NormalizeRoleName(input.Name)
 		if normalized == "" {
-			return nil, fmt.Errorf("role name cannot be empty")
+			return nil, xerr.ErrBadRequest.WithMessage("role name cannot be empty")
 		}
 		role.Name = normalized
 	}
@@ -172,6 +218,9 @@ func (s *Service) UpdateRole(ctx context.Context, input UpdateRoleInput) (*Role,
 	}
 
 	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
 		return nil, err
 	}
 	return role, nil
@@ -189,10 +238,12 @@ func (s *Service) ListRoles(ctx context.Context) ([]Role, error) {
 
 // CreatePermission creates a new permission.
 func (s *Service) CreatePermission(ctx context.Context, input CreatePermissionInput) (*Permission, error) {
-	resource := strings.ToLower(strings.TrimSpace(input.Resource))
-	action := strings.ToLower(strings.TrimSpace(input.Action))
-	if resource == "" || action == "" {
-		return nil, fmt.Errorf("resource and action are required")
+	if strings.TrimSpace(input.Resource) == "" || strings.TrimSpace(input.Action) == "" {
+		return nil, xerr.ErrBadRequest.WithMessage("resource and action are required")
+	}
+	resource, action, ok := ParsePermissionKey(PermissionKey(input.Resource, input.Action))
+	if !ok {
+		return nil, ErrInvalidPermission
 	}
 
 	permission := &Permission{
@@ -214,26 +265,35 @@ func (s *Service) UpdatePermission(ctx context.Context, input UpdatePermissionIn
 	if err != nil {
 		return nil, err
 	}
+	if input.Version != 0 && permission.Version != input.Version {
+		return nil, ErrVersionConflict
+	}
 
 	if input.Resource != "" {
 		resource := strings.ToLower(strings.TrimSpace(input.Resource))
 		if resource == "" {
-			return nil, fmt.Errorf("resource cannot be empty")
+			return nil, xerr.ErrBadRequest.WithMessage("resource cannot be empty")
 		}
 		permission.Resource = resource
 	}
 	if input.Action != "" {
 		action := strings.ToLower(strings.TrimSpace(input.Action))
 		if action == "" {
-			return nil, fmt.Errorf("action cannot be empty")
+			return nil, xerr.ErrBadRequest.WithMessage("action cannot be empty")
 		}
 		permission.Action = action
 	}
+	if _, _, ok := ParsePermissionKey(PermissionKey(permission.Resource, permission.Action)); !ok {
+		return nil, ErrInvalidPermission
+	}
 	if input.Description != "" {
 		permission.Description = strings.TrimSpace(input.Description)
 	}
 
 	if err := s.repo.UpdatePermission(ctx, permission); err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
 		return nil, err
 	}
 	return permission, nil
@@ -251,22 +311,22 @@ func (s *Service) ListPermissions(ctx context.Context) ([]Permission, error) {
 
 // AssignPermissions assigns permissions to a role based on permission keys.
 func (s *Service) AssignPermissions(ctx context.Context, input AssignRolePermissionsInput) (*Role, error) {
-	role, err := s.repo.FindRoleByID(ctx, input.RoleID)
-	if err != nil {
-		return nil, err
-	}
-
 	keys := make([]string, 0, len(input.Permissions))
 	for _, key := range input.Permissions {
-		resource, action, ok := ParsePermissionKey(key)
+		normalized, ok := NormalizePermissionKey(key)
 		if !ok {
 			continue
 		}
-		keys = append(keys, PermissionKey(resource, action))
+		keys = append(keys, normalized)
 	}
 
 	if len(keys) == 0 {
-		return nil, fmt.Errorf("no valid permissions provided")
+		return nil, xerr.ErrBadRequest.WithMessage("no valid permissions provided")
+	}
+
+	role, err := s.repo.FindRoleByID(ctx, input.RoleID)
+	if err != nil {
+		return nil, err
 	}
 
 	permissions, err := s.repo.FindPermissionsByKeys(ctx, keys)
@@ -274,7 +334,7 @@ func (s *Service) AssignPermissions(ctx context.Context, input AssignRolePermiss
 		return nil, err
 	}
 	if len(permissions) == 0 {
-		return nil, fmt.Errorf("permissions not found")
+		return nil, ErrResourceNotFound.WithMessage("permissions not found")
 	}
 
 	if err := s.repo.ReplaceRolePermissions(ctx, role, permissions); err != nil {
@@ -301,6 +361,48 @@ func (s *Service) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]s
 	return keys, nil
 }
 
+// AssignParents replaces the parent roles of a role. An empty list removes every parent.
+// The update is rejected when it would make the role inherit from itself.
+func (s *Service) AssignParents(ctx context.Context, input AssignRoleParentsInput) (*Role, error) {
+	role, err := s.repo.FindRoleByID(ctx, input.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := UniqueNormalized(input.Parents)
+	var parents []*Role
+	if len(names) > 0 {
+		parents, err = s.repo.FindRolesByNames(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+		if len(parents) != len(names) {
+			return nil, ErrResourceNotFound
+		}
+	}
+
+	if err := s.repo.AssignRoleParents(ctx, role, parents); err != nil {
+		return nil, err
+	}
+	role.Parents = parents
+	return role, nil
+}
+
+// GetEffectivePermissions returns the permission keys of a role including everything inherited
+// from its ancestors.
+func (s *Service) GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := newRoleGraph(roles)
+	if _, ok := graph.roles[roleID]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return graph.effectivePermissionKeys(roleID), nil
+}
+
 // GetRolesByNames returns roles for the provided names.
 func (s *Service) GetRolesByNames(ctx context.Context, names []string) ([]*Role, error) {
 	normalized := UniqueNormalized(names)
@@ -409,9 +511,244 @@ func (s *Service) EnsureAdminHasAllPermissions(ctx context.Context) error {
 	return s.repo.ReplaceRolePermissions(ctx, adminRole, permissions)
 }
 
-// HasPermission checks whether the given user owns the permission key.
+// HasPermission reports whether CheckPermission admits the user to a route guarded by permission.
+// An allow that still depends on resource attributes cannot be confirmed without the resource and
+// is reported as not allowed.
 func (s *Service) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
-	return s.repo.UserHasPermission(ctx, userID, permission)
+	decision, err := s.CheckPermission(ctx, userID, permission)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allowed && !decision.Pending, nil
+}
+
+// CheckPermission computes the route-level decision for the user, before the handler has loaded
+// the resource. It follows the same order as Evaluate; policy conditions on subject and request
+// attributes are evaluated, while an allow policy that depends on resource attributes admits the
+// route with Decision.Pending set so the handler must still call Authorize; routes that do not
+// declare feature.RouteDefinition.AuthorizesResource reject such decisions. Deny policies that
+// depend on resource attributes are left to Authorize.
+//
+// The subject is taken from ctx when it belongs to userID. The ADMIN bypass applies when the
+// session roles or the user's active roles, including inherited ones, contain ADMIN.
+func (s *Service) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (Decision, error) {
+	required, ok := NormalizePermissionKey(permission)
+	if !ok {
+		return Decision{}, ErrInvalidPermission
+	}
+
+	subject, ok := feature.AuthContextFromContext(ctx)
+	if !ok || subject.UserID != userID {
+		subject = feature.AuthContext{UserID: userID}
+	}
+
+	roles, policies, err := s.userAccess(ctx, userID, subject.Roles)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	env := newPolicyEnv(ctx, subject, nil)
+	return decide(roles, required, policies, routeMatcher(env), func() (bool, error) {
+		return s.repo.UserHasPermission(ctx, userID, required)
+	})
+}
+
+// Authorize evaluates the permission for the subject stored in ctx against the resource
+// attributes loaded by the handler. It returns ErrPermissionDenied when access is not allowed.
+func (s *Service) Authorize(ctx context.Context, permission string, resource Attributes) error {
+	decision, err := s.Evaluate(ctx, permission, resource)
+	if err != nil {
+		return err
+	}
+	resolvePendingAuthorization(ctx, permission)
+	if !decision.Allowed {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// Evaluate computes the authorization decision for the subject stored in ctx.
+//
+// Matching deny policies are checked first and override every grant, including the ADMIN
+// bypass. Otherwise the request is allowed for ADMIN (held directly or through role
+// inheritance), for plain role permissions, or for allow policies whose conditions hold.
+func (s *Service) Evaluate(ctx context.Context, permission string, resource Attributes) (Decision, error) {
+	subject, ok := feature.AuthContextFromContext(ctx)
+	if !ok {
+		return Decision{Reason: "missing subject"}, nil
+	}
+
+	required, ok := NormalizePermissionKey(permission)
+	if !ok {
+		return Decision{}, ErrInvalidPermission
+	}
+
+	roles, policies, err := s.userAccess(ctx, subject.UserID, subject.Roles)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	env := newPolicyEnv(ctx, subject, resource)
+	return decide(roles, required, policies, resourceMatcher(env), func() (bool, error) {
+		return s.repo.UserHasPermission(ctx, subject.UserID, required)
+	})
+}
+
+// policyMatcher reports whether a policy applies to the required permission and whether the
+// match still depends on resource attributes.
+type policyMatcher func(policy *Policy, required string) (matched, pending bool)
+
+// resourceMatcher evaluates every condition against an environment that includes the resource.
+func resourceMatcher(env policyEnv) policyMatcher {
+	return func(policy *Policy, required string) (bool, bool) {
+		return policy.matches(required, env), false
+	}
+}
+
+// routeMatcher defers conditions on resource attributes, see Policy.routeMatch.
+func routeMatcher(env policyEnv) policyMatcher {
+	return func(policy *Policy, required string) (bool, bool) {
+		return policy.routeMatch(required, env)
+	}
+}
+
+// reasonAdminBypass is the Decision.Reason of requests admitted by the ADMIN bypass.
+const reasonAdminBypass = "admin bypass"
+
+// decide is the single evaluation order shared by route-level checks, Authorize and Explain:
+// deny policies, then the ADMIN bypass, then role permissions, then allow policies. An allow
+// that still depends on resource attributes is only used when nothing else grants access.
+func decide(roles []string, required string, policies []Policy, match policyMatcher, granted func() (bool, error)) (Decision, error) {
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Effect != EffectDeny {
+			continue
+		}
+		if matched, pending := match(policy, required); matched && !pending {
+			return Decision{Reason: "denied by policy", PolicyID: &policy.ID}, nil
+		}
+	}
+
+	if HasAdminRole(roles) {
+		return Decision{Allowed: true, Reason: reasonAdminBypass}, nil
+	}
+
+	ok, err := granted()
+	if err != nil {
+		return Decision{}, err
+	}
+	if ok {
+		return Decision{Allowed: true, Reason: "granted by role permission"}, nil
+	}
+
+	var pendingBy *uuid.UUID
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Effect != EffectAllow {
+			continue
+		}
+		matched, pending := match(policy, required)
+		switch {
+		case matched && !pending:
+			return Decision{Allowed: true, Reason: "allowed by policy", PolicyID: &policy.ID}, nil
+		case matched && pendingBy == nil:
+			pendingBy = &policy.ID
+		}
+	}
+	if pendingBy != nil {
+		return Decision{Allowed: true, Reason: "allowed by policy pending resource check", PolicyID: pendingBy, Pending: true}, nil
+	}
+
+	return Decision{Reason: "no matching grant"}, nil
+}
+
+// CreatePolicy validates and persists a conditional policy.
+func (s *Service) CreatePolicy(ctx context.Context, input CreatePolicyInput) (*Policy, error) {
+	permission, ok := NormalizePermissionKey(input.Permission)
+	if !ok {
+		return nil, ErrInvalidPermission
+	}
+
+	effect := strings.ToLower(strings.TrimSpace(input.Effect))
+	if effect != EffectAllow && effect != EffectDeny {
+		return nil, xerr.ErrBadRequest.WithMessage(fmt.Sprintf("unsupported policy effect %q", input.Effect))
+	}
+
+	conditions := make([]Condition, 0, len(input.Conditions))
+	for _, condition := range input.Conditions {
+		condition.Field = strings.TrimSpace(condition.Field)
+		condition.Ref = strings.TrimSpace(condition.Ref)
+		condition.Operator = strings.ToLower(strings.TrimSpace(condition.Operator))
+		if err := condition.validate(); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	role, err := s.repo.FindRoleByID(ctx, input.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		ID:          uuid.Must(uuid.NewV7()),
+		RoleID:      role.ID,
+		Permission:  permission,
+		Effect:      effect,
+		Conditions:  conditions,
+		Description: strings.TrimSpace(input.Description),
+	}
+
+	if err := s.repo.CreatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeletePolicy removes a policy.
+func (s *Service) DeletePolicy(ctx context.Context, input DeletePolicyInput) error {
+	return s.repo.DeletePolicy(ctx, input.ID)
+}
+
+// ListPolicies returns all policies.
+func (s *Service) ListPolicies(ctx context.Context) ([]Policy, error) {
+	return s.repo.ListPolicies(ctx)
+}
+
+// userAccess loads the policies attached to the user's roles and the role names used for the
+// ADMIN bypass: the session roles together with every active role of the user and the roles
+// they inherit from, so a role inheriting from ADMIN bypasses like ADMIN itself.
+func (s *Service) userAccess(ctx context.Context, userID uuid.UUID, sessionRoles []string) ([]string, []Policy, error) {
+	roleIDs, err := s.repo.FindUserRoleIDs(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, err := s.bypassRoles(ctx, roleIDs, sessionRoles)
+	if err != nil {
+		return nil, nil, err
+	}
+	policies, err := s.repo.FindPoliciesByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roles, policies, nil
+}
+
+// bypassRoles appends the names of the expanded role set to the session roles. The lookup is
+// skipped when the session already carries ADMIN.
+func (s *Service) bypassRoles(ctx context.Context, roleIDs []uuid.UUID, sessionRoles []string) ([]string, error) {
+	if HasAdminRole(sessionRoles) || len(roleIDs) == 0 {
+		return sessionRoles, nil
+	}
+	expanded, err := s.repo.FindRolesByIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	roles := append(make([]string, 0, len(sessionRoles)+len(expanded)), sessionRoles...)
+	for _, role := range expanded {
+		roles = append(roles, role.Name)
+	}
+	return roles, nil
 }
 
 func (s *Service) ensureBaselineRoles(ctx context.Context) error {
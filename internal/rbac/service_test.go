@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/model"
 )
 
 // mockRepository 使用 testify 模拟仓储层行为。
@@ -100,12 +103,94 @@ func (m *mockRepository) ReplaceRolePermissions(ctx context.Context, role *Role,
 	return args.Error(0)
 }
 
+func (m *mockRepository) AssignRoleParents(ctx context.Context, role *Role, parents []*Role) error {
+	args := m.Called(ctx, role, parents)
+	return args.Error(0)
+}
+
+func (m *mockRepository) Transaction(ctx context.Context, fn func(repo RepositoryContract) error) error {
+	return fn(m)
+}
+
+func (m *mockRepository) LockRoles(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *mockRepository) UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
 	args := m.Called(ctx, userID, permission)
 	allowed, _ := args.Get(0).(bool)
 	return allowed, args.Error(1)
 }
 
+func (m *mockRepository) FindUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	ids, _ := args.Get(0).([]uuid.UUID)
+	return ids, args.Error(1)
+}
+
+func (m *mockRepository) CreatePolicy(ctx context.Context, policy *Policy) error {
+	args := m.Called(ctx, policy)
+	return args.Error(0)
+}
+
+func (m *mockRepository) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRepository) ListPolicies(ctx context.Context) ([]Policy, error) {
+	args := m.Called(ctx)
+	policies, _ := args.Get(0).([]Policy)
+	return policies, args.Error(1)
+}
+
+func (m *mockRepository) FindPoliciesByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) ([]Policy, error) {
+	args := m.Called(ctx, roleIDs)
+	policies, _ := args.Get(0).([]Policy)
+	return policies, args.Error(1)
+}
+
+func (m *mockRepository) FindActiveUserRoleIDs(ctx context.Context, userID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, now)
+	ids, _ := args.Get(0).([]uuid.UUID)
+	return ids, args.Error(1)
+}
+
+func (m *mockRepository) FindRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]Role, error) {
+	args := m.Called(ctx, ids)
+	roles, _ := args.Get(0).([]Role)
+	return roles, args.Error(1)
+}
+
+func (m *mockRepository) FindRolePermissionGrants(ctx context.Context, roleIDs []uuid.UUID) ([]PermissionGrant, error) {
+	args := m.Called(ctx, roleIDs)
+	grants, _ := args.Get(0).([]PermissionGrant)
+	return grants, args.Error(1)
+}
+
+func (m *mockRepository) SaveUserRole(ctx context.Context, assignment *UserRole) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *mockRepository) DeleteUserRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	args := m.Called(ctx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *mockRepository) ListExpiringUserRoles(ctx context.Context, from, until time.Time) ([]RoleAssignment, error) {
+	args := m.Called(ctx, from, until)
+	assignments, _ := args.Get(0).([]RoleAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *mockRepository) DeleteExpiredUserRoles(ctx context.Context, now time.Time) ([]UserRole, error) {
+	args := m.Called(ctx, now)
+	expired, _ := args.Get(0).([]UserRole)
+	return expired, args.Error(1)
+}
+
 func newMockService(repo *mockRepository) *Service {
 	return &Service{repo: repo}
 }
@@ -173,6 +258,7 @@ func TestServiceUpdateRole(t *testing.T) {
 		input   UpdateRoleInput
 		prepare func(*mockRepository)
 		wantErr bool
+		errIs   error
 	}{
 		{
 			name: "成功更新字段",
@@ -206,6 +292,29 @@ func TestServiceUpdateRole(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:  "版本号过期",
+			input: UpdateRoleInput{ID: uuid.New(), Name: "leader", Version: 1},
+			prepare: func(m *mockRepository) {
+				existing := &Role{ID: uuid.New(), Name: "OLD"}
+				existing.Version = 2
+				m.On("FindRoleByID", mock.Anything, mock.Anything).Return(existing, nil)
+			},
+			wantErr: true,
+			errIs:   ErrVersionConflict,
+		},
+		{
+			name:  "并发写入导致版本冲突",
+			input: UpdateRoleInput{ID: uuid.New(), Version: 2},
+			prepare: func(m *mockRepository) {
+				existing := &Role{ID: uuid.New(), Name: "OLD"}
+				existing.Version = 2
+				m.On("FindRoleByID", mock.Anything, mock.Anything).Return(existing, nil)
+				m.On("UpdateRole", mock.Anything, mock.AnythingOfType("*rbac.Role")).Return(model.ErrVersionConflict)
+			},
+			wantErr: true,
+			errIs:   ErrVersionConflict,
+		},
 		{
 			name:  "保存失败",
 			input: UpdateRoleInput{ID: uuid.New()},
@@ -228,6 +337,9 @@ func TestServiceUpdateRole(t *testing.T) {
 			if tc.wantErr {
 				require.Error(t, err)
 				require.Nil(t, role)
+				if tc.errIs != nil {
+					require.ErrorIs(t, err, tc.errIs)
+				}
 			} else {
 				require.NoError(t, err)
 				require.NotNil(t, role)
@@ -340,6 +452,21 @@ func TestServiceCreatePermission(t *testing.T) {
 			prepare: func(m *mockRepository) {},
 			wantErr: true,
 		},
+		{
+			name:  "允许通配符权限",
+			input: CreatePermissionInput{Resource: "rbac.*", Action: "*"},
+			prepare: func(m *mockRepository) {
+				m.On("CreatePermission", mock.Anything, mock.MatchedBy(func(p *Permission) bool {
+					return p.Resource == "rbac.*" && p.Action == "*"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "非法通配符格式",
+			input:   CreatePermissionInput{Resource: "rb*ac", Action: "read"},
+			prepare: func(m *mockRepository) {},
+			wantErr: true,
+		},
 		{
 			name:  "仓储错误",
 			input: CreatePermissionInput{Resource: "system", Action: "edit"},
@@ -603,6 +730,127 @@ func TestServiceAssignPermissions(t *testing.T) {
 	}
 }
 
+// TestServiceAssignParents 覆盖角色继承设置，循环检测由 TestRepositoryAssignRoleParents 覆盖。
+func TestServiceAssignParents(t *testing.T) {
+	// 继承链：MANAGER -> SUPPORT -> VIEWER
+	viewer := Role{ID: uuid.New(), Name: "VIEWER"}
+	support := Role{ID: uuid.New(), Name: "SUPPORT", Parents: []*Role{&viewer}}
+	manager := Role{ID: uuid.New(), Name: "MANAGER", Parents: []*Role{&support}}
+
+	cases := []struct {
+		name    string
+		input   AssignRoleParentsInput
+		prepare func(*mockRepository)
+		wantErr error
+	}{
+		{
+			name:  "成功设置父角色",
+			input: AssignRoleParentsInput{RoleID: manager.ID, Parents: []string{" viewer ", "VIEWER"}},
+			prepare: func(m *mockRepository) {
+				role := &Role{ID: manager.ID, Name: manager.Name}
+				m.On("FindRoleByID", mock.Anything, manager.ID).Return(role, nil)
+				m.On("FindRolesByNames", mock.Anything, []string{"VIEWER"}).Return([]*Role{&viewer}, nil)
+				m.On("AssignRoleParents", mock.Anything, role, []*Role{&viewer}).Return(nil)
+			},
+		},
+		{
+			name:  "清空父角色",
+			input: AssignRoleParentsInput{RoleID: support.ID},
+			prepare: func(m *mockRepository) {
+				role := &Role{ID: support.ID, Name: support.Name}
+				m.On("FindRoleByID", mock.Anything, support.ID).Return(role, nil)
+				m.On("AssignRoleParents", mock.Anything, role, []*Role(nil)).Return(nil)
+			},
+		},
+		{
+			name:  "形成循环",
+			input: AssignRoleParentsInput{RoleID: viewer.ID, Parents: []string{"manager"}},
+			prepare: func(m *mockRepository) {
+				role := &Role{ID: viewer.ID, Name: viewer.Name}
+				m.On("FindRoleByID", mock.Anything, viewer.ID).Return(role, nil)
+				m.On("FindRolesByNames", mock.Anything, []string{"MANAGER"}).Return([]*Role{&manager}, nil)
+				m.On("AssignRoleParents", mock.Anything, role, []*Role{&manager}).Return(ErrRoleCycle)
+			},
+			wantErr: ErrRoleCycle,
+		},
+		{
+			name:  "父角色不存在",
+			input: AssignRoleParentsInput{RoleID: manager.ID, Parents: []string{"ghost"}},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByID", mock.Anything, manager.ID).Return(&Role{ID: manager.ID}, nil)
+				m.On("FindRolesByNames", mock.Anything, []string{"GHOST"}).Return(nil, nil)
+			},
+			wantErr: ErrResourceNotFound,
+		},
+		{
+			name:  "角色不存在",
+			input: AssignRoleParentsInput{RoleID: manager.ID, Parents: []string{"viewer"}},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByID", mock.Anything, manager.ID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &mockRepository{}
+			tc.prepare(mockRepo)
+			svc := newMockService(mockRepo)
+
+			role, err := svc.AssignParents(context.Background(), tc.input)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Nil(t, role)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, role)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestServiceGetEffectivePermissions 验证有效权限包含全部祖先角色的授权。
+func TestServiceGetEffectivePermissions(t *testing.T) {
+	read := &Permission{Resource: "user", Action: "read"}
+	update := &Permission{Resource: "user", Action: "update"}
+	deleteAll := &Permission{Resource: "user", Action: "*"}
+
+	viewer := Role{ID: uuid.New(), Name: "VIEWER", Permissions: []*Permission{read}}
+	support := Role{ID: uuid.New(), Name: "SUPPORT", Permissions: []*Permission{update, read}, Parents: []*Role{&viewer}}
+	manager := Role{ID: uuid.New(), Name: "MANAGER", Permissions: []*Permission{deleteAll}, Parents: []*Role{&support, &viewer}}
+	graph := []Role{viewer, support, manager}
+
+	cases := []struct {
+		name    string
+		roleID  uuid.UUID
+		want    []string
+		wantErr bool
+	}{
+		{name: "叶子角色", roleID: viewer.ID, want: []string{"user:read"}},
+		{name: "单层继承", roleID: support.ID, want: []string{"user:update", "user:read"}},
+		{name: "多层继承去重", roleID: manager.ID, want: []string{"user:*", "user:update", "user:read"}},
+		{name: "角色不存在", roleID: uuid.New(), wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &mockRepository{}
+			mockRepo.On("ListRoles", mock.Anything).Return(graph, nil)
+			svc := newMockService(mockRepo)
+
+			keys, err := svc.GetEffectivePermissions(context.Background(), tc.roleID)
+			if tc.wantErr {
+				require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, keys)
+		})
+	}
+}
+
 // TestServiceGetRolePermissions 验证角色权限读取逻辑。
 func TestServiceGetRolePermissions(t *testing.T) {
 	roleID := uuid.New()
@@ -810,9 +1058,10 @@ func TestServiceEnsureAdminHasAllPermissions(t *testing.T) {
 	}
 }
 
-// TestServiceHasPermission 确认权限检查会透传到底层仓储。
+// TestServiceHasPermission 确认权限检查会结合角色授权与策略。
 func TestServiceHasPermission(t *testing.T) {
 	userID := uuid.New()
+	roleIDs := []uuid.UUID{uuid.New()}
 	cases := []struct {
 		name    string
 		prepare func(*mockRepository)
@@ -823,22 +1072,65 @@ func TestServiceHasPermission(t *testing.T) {
 			name: "拥有权限",
 			prepare: func(m *mockRepository) {
 				m.On("UserHasPermission", mock.Anything, userID, "system:view").Return(true, nil)
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
+			},
+			allowed: true,
+		},
+		{
+			name: "依赖资源的条件允许策略无法确认",
+			prepare: func(m *mockRepository) {
+				m.On("UserHasPermission", mock.Anything, userID, "system:view").Return(false, nil)
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{{
+					Permission: "system:view",
+					Effect:     EffectAllow,
+					Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}},
+				}}, nil)
+			},
+			allowed: false,
+		},
+		{
+			name: "继承自管理员的角色绕过校验",
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{Name: "OPS"}, {Name: constant.RoleAdmin}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
 			},
 			allowed: true,
 		},
+		{
+			name: "无条件拒绝策略优先",
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{{Permission: "system:*", Effect: EffectDeny}}, nil)
+			},
+			allowed: false,
+		},
 		{
 			name: "查询错误",
 			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
 				m.On("UserHasPermission", mock.Anything, userID, "system:view").Return(false, errors.New("db"))
 			},
 			wantErr: true,
 		},
+		{
+			name: "角色查询错误",
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return(nil, errors.New("db"))
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := &mockRepository{}
 			tc.prepare(mockRepo)
+			mockRepo.On("FindRolesByIDs", mock.Anything, mock.Anything).Return([]Role(nil), nil).Maybe()
 			svc := newMockService(mockRepo)
 
 			allowed, err := svc.HasPermission(context.Background(), userID, "system:view")
@@ -852,3 +1144,309 @@ func TestServiceHasPermission(t *testing.T) {
 		})
 	}
 }
+
+// TestServiceEvaluate 覆盖策略判定的优先级与条件匹配。
+func TestServiceEvaluate(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+	roleIDs := []uuid.UUID{uuid.New()}
+	ownerPolicy := Policy{
+		ID:         uuid.New(),
+		Permission: "user:update",
+		Effect:     EffectAllow,
+		Conditions: []Condition{{Field: "resource.ownerId", Operator: OpEq, Ref: "subject.userId"}},
+	}
+	departmentPolicy := Policy{
+		ID:         uuid.New(),
+		Permission: "user:read",
+		Effect:     EffectAllow,
+		Conditions: []Condition{{Field: "resource.department", Operator: OpEq, Ref: "subject.department"}},
+	}
+	denyLockedPolicy := Policy{
+		ID:         uuid.New(),
+		Permission: "user:*",
+		Effect:     EffectDeny,
+		Conditions: []Condition{{Field: "resource.locked", Operator: OpEq, Value: true}},
+	}
+
+	cases := []struct {
+		name        string
+		ctx         func() context.Context
+		permission  string
+		resource    Attributes
+		prepare     func(*mockRepository)
+		wantErr     error
+		wantAllowed bool
+		wantPolicy  *uuid.UUID
+	}{
+		{
+			name:       "缺少主体",
+			ctx:        context.Background,
+			permission: "user:update",
+			prepare:    func(*mockRepository) {},
+		},
+		{
+			name: "无效权限键",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID})
+			},
+			permission: "user",
+			prepare:    func(*mockRepository) {},
+			wantErr:    ErrInvalidPermission,
+		},
+		{
+			name: "资源所有者允许",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID})
+			},
+			permission: "user:update",
+			resource:   Attributes{"ownerId": userID.String()},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{ownerPolicy}, nil)
+				m.On("UserHasPermission", mock.Anything, userID, "user:update").Return(false, nil)
+			},
+			wantAllowed: true,
+			wantPolicy:  &ownerPolicy.ID,
+		},
+		{
+			name: "非资源所有者拒绝",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID})
+			},
+			permission: "user:update",
+			resource:   Attributes{"ownerId": otherID.String()},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{ownerPolicy}, nil)
+				m.On("UserHasPermission", mock.Anything, userID, "user:update").Return(false, nil)
+			},
+		},
+		{
+			name: "同部门允许",
+			ctx: func() context.Context {
+				ctx := feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID})
+				return WithSubjectAttributes(ctx, Attributes{"department": "sales"})
+			},
+			permission: "user:read",
+			resource:   Attributes{"department": "sales"},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{departmentPolicy}, nil)
+				m.On("UserHasPermission", mock.Anything, userID, "user:read").Return(false, nil)
+			},
+			wantAllowed: true,
+			wantPolicy:  &departmentPolicy.ID,
+		},
+		{
+			name: "拒绝策略覆盖管理员",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID, Roles: []string{constant.RoleAdmin}})
+			},
+			permission: "user:update",
+			resource:   Attributes{"locked": true},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{denyLockedPolicy}, nil)
+			},
+			wantPolicy: &denyLockedPolicy.ID,
+		},
+		{
+			name: "管理员放行",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID, Roles: []string{constant.RoleAdmin}})
+			},
+			permission: "user:update",
+			resource:   Attributes{"locked": false},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{denyLockedPolicy}, nil)
+			},
+			wantAllowed: true,
+		},
+		{
+			name: "继承自管理员的角色放行",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID, Roles: []string{"OPS"}})
+			},
+			permission: "user:update",
+			resource:   Attributes{"locked": false},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{Name: "OPS"}, {Name: constant.RoleAdmin}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{denyLockedPolicy}, nil)
+			},
+			wantAllowed: true,
+		},
+		{
+			name: "拒绝策略覆盖继承自管理员的角色",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID, Roles: []string{"OPS"}})
+			},
+			permission: "user:update",
+			resource:   Attributes{"locked": true},
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindRolesByIDs", mock.Anything, roleIDs).Return([]Role{{Name: "OPS"}, {Name: constant.RoleAdmin}}, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return([]Policy{denyLockedPolicy}, nil)
+			},
+			wantPolicy: &denyLockedPolicy.ID,
+		},
+		{
+			name: "角色授权允许",
+			ctx: func() context.Context {
+				return feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: userID})
+			},
+			permission: "user:read",
+			prepare: func(m *mockRepository) {
+				m.On("FindUserRoleIDs", mock.Anything, userID).Return(roleIDs, nil)
+				m.On("FindPoliciesByRoleIDs", mock.Anything, roleIDs).Return(nil, nil)
+				m.On("UserHasPermission", mock.Anything, userID, "user:read").Return(true, nil)
+			},
+			wantAllowed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &mockRepository{}
+			tc.prepare(mockRepo)
+			mockRepo.On("FindRolesByIDs", mock.Anything, mock.Anything).Return([]Role(nil), nil).Maybe()
+			svc := newMockService(mockRepo)
+
+			decision, err := svc.Evaluate(tc.ctx(), tc.permission, tc.resource)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				mockRepo.AssertExpectations(t)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantAllowed, decision.Allowed)
+			require.Equal(t, tc.wantPolicy, decision.PolicyID)
+			mockRepo.AssertExpectations(t)
+
+			authErr := svc.Authorize(tc.ctx(), tc.permission, tc.resource)
+			if tc.wantAllowed {
+				require.NoError(t, authErr)
+			} else {
+				require.ErrorIs(t, authErr, ErrPermissionDenied)
+			}
+		})
+	}
+}
+
+// TestServiceCreatePolicy 覆盖策略创建时的校验逻辑。
+func TestServiceCreatePolicy(t *testing.T) {
+	roleID := uuid.New()
+	cases := []struct {
+		name    string
+		input   CreatePolicyInput
+		prepare func(*mockRepository)
+		wantErr bool
+	}{
+		{
+			name: "创建成功",
+			input: CreatePolicyInput{
+				RoleID:     roleID,
+				Permission: " User:Update ",
+				Effect:     "allow",
+				Conditions: []Condition{{Field: "resource.ownerId", Operator: " EQ ", Ref: "subject.userId"}},
+			},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByID", mock.Anything, roleID).Return(&Role{ID: roleID}, nil)
+				m.On("CreatePolicy", mock.Anything, mock.MatchedBy(func(p *Policy) bool {
+					return p.RoleID == roleID && p.Permission == "user:update" && p.Conditions[0].Operator == OpEq
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "无效权限键",
+			input:   CreatePolicyInput{RoleID: roleID, Permission: "user", Effect: "allow"},
+			prepare: func(*mockRepository) {},
+			wantErr: true,
+		},
+		{
+			name:    "无效效果",
+			input:   CreatePolicyInput{RoleID: roleID, Permission: "user:update", Effect: "maybe"},
+			prepare: func(*mockRepository) {},
+			wantErr: true,
+		},
+		{
+			name: "不支持的操作符",
+			input: CreatePolicyInput{
+				RoleID:     roleID,
+				Permission: "user:update",
+				Effect:     "deny",
+				Conditions: []Condition{{Field: "resource.ownerId", Operator: "like", Value: "x"}},
+			},
+			prepare: func(*mockRepository) {},
+			wantErr: true,
+		},
+		{
+			name:  "角色不存在",
+			input: CreatePolicyInput{RoleID: roleID, Permission: "user:update", Effect: "allow"},
+			prepare: func(m *mockRepository) {
+				m.On("FindRoleByID", mock.Anything, roleID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &mockRepository{}
+			tc.prepare(mockRepo)
+			svc := newMockService(mockRepo)
+
+			policy, err := svc.CreatePolicy(context.Background(), tc.input)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NotEqual(t, uuid.Nil, policy.ID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestServiceAdminBypassThroughInheritance 验证继承自 ADMIN 的角色与 ADMIN 本身一样绕过权限校验，会话中的角色名无需包含 ADMIN。
+func TestServiceAdminBypassThroughInheritance(t *testing.T) {
+	db := setupTestDB(t)
+
+	runInTransaction(t, db, func(ctx context.Context, repo *Repository, tx *gorm.DB) {
+		svc := NewService(repo)
+		admin := &Role{ID: uuid.New(), Name: constant.RoleAdmin}
+		ops := &Role{ID: uuid.New(), Name: "OPS"}
+		plain := &Role{ID: uuid.New(), Name: "PLAIN"}
+		for _, role := range []*Role{admin, ops, plain} {
+			require.NoError(t, tx.WithContext(ctx).Create(role).Error)
+		}
+		require.NoError(t, repo.AssignRoleParents(ctx, ops, []*Role{admin}))
+
+		cases := []struct {
+			name        string
+			role        *Role
+			wantAllowed bool
+		}{
+			{name: "继承自管理员", role: ops, wantAllowed: true},
+			{name: "普通角色", role: plain, wantAllowed: false},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				userID := uuid.New()
+				require.NoError(t, tx.WithContext(ctx).Create(&UserRole{UserID: userID, RoleID: tc.role.ID}).Error)
+				subjectCtx := feature.ContextWithAuthContext(ctx, feature.AuthContext{UserID: userID, Roles: []string{tc.role.Name}})
+
+				decision, err := svc.CheckPermission(subjectCtx, userID, "system:manage")
+				require.NoError(t, err)
+				require.Equal(t, tc.wantAllowed, decision.Allowed)
+
+				decision, err = svc.Evaluate(subjectCtx, "system:manage", nil)
+				require.NoError(t, err)
+				require.Equal(t, tc.wantAllowed, decision.Allowed)
+			})
+		}
+	})
+}
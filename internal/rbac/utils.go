@@ -1,8 +1,13 @@
 package rbac
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/Jayleonc/service/pkg/constant"
+)
 
 // ParsePermissionKey splits a composite permission key into resource and action parts.
+// Both parts are required; either of them may be a wildcard pattern (see MatchPermission).
 func ParsePermissionKey(key string) (resource, action string, ok bool) {
 	trimmed := strings.TrimSpace(key)
 	if trimmed == "" {
@@ -10,22 +15,42 @@ func ParsePermissionKey(key string) (resource, action string, ok bool) {
 	}
 
 	parts := strings.SplitN(trimmed, ":", 2)
-	if len(parts) == 1 {
-		return strings.ToLower(strings.TrimSpace(parts[0])), "", true
+	if len(parts) != 2 {
+		return "", "", false
 	}
 	resource = strings.ToLower(strings.TrimSpace(parts[0]))
 	action = strings.ToLower(strings.TrimSpace(parts[1]))
-	if resource == "" && action == "" {
+	if !validResourcePattern(resource) || !validActionPattern(action) {
 		return "", "", false
 	}
 	return resource, action, true
 }
 
+// NormalizePermissionKey parses and re-assembles a permission key in its canonical form.
+func NormalizePermissionKey(key string) (string, bool) {
+	resource, action, ok := ParsePermissionKey(key)
+	if !ok {
+		return "", false
+	}
+	return PermissionKey(resource, action), true
+}
+
 // NormalizeRoleName uppercases and trims a role identifier.
 func NormalizeRoleName(name string) string {
 	return strings.ToUpper(strings.TrimSpace(name))
 }
 
+// HasAdminRole reports whether the role names include the built-in ADMIN role.
+func HasAdminRole(roles []string) bool {
+	admin := NormalizeRoleName(constant.RoleAdmin)
+	for _, role := range roles {
+		if NormalizeRoleName(role) == admin {
+			return true
+		}
+	}
+	return false
+}
+
 // UniqueNormalized returns the deduplicated, normalized role names.
 func UniqueNormalized(names []string) []string {
 	if len(names) == 0 {
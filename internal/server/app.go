@@ -1,13 +1,22 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/Jayleonc/service/pkg/config"
 )
 
+// defaultShutdownTimeout 是未配置 server.shutdown_timeout 时等待进行中请求完成的时长。
+const defaultShutdownTimeout = 15 * time.Second
+
 // App 保存已初始化的 Gin 引擎以及服务级共享配置。
 type App struct {
 	Engine *gin.Engine
@@ -23,3 +32,51 @@ func NewApp(router *gin.Engine, cfg config.App, logger *slog.Logger) *App {
 		Logger: logger,
 	}
 }
+
+// Run 在配置的地址上启动 HTTP 服务并阻塞到 ctx 结束，随后停止接受新连接，
+// 并在 server.shutdown_timeout 内等待进行中的请求完成。
+func (a *App) Run(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", a.Config.Server.Host, a.Config.Server.Port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	return a.serve(ctx, ln)
+}
+
+func (a *App) serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: a.Engine.Handler()}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ln)
+	}()
+
+	log := a.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	log.Info("http server started", "addr", ln.Addr().String())
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := a.Config.Server.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	log.Info("shutting down http server", "timeout", timeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown http server: %w", err)
+	}
+	if err := <-served; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Info("http server stopped")
+	return nil
+}
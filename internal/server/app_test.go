@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/config"
+)
+
+// TestAppServeGracefulShutdown 验证 ctx 结束后服务停止接受新连接，但会等待进行中的请求完成后再返回。
+func TestAppServeGracefulShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	engine := gin.New()
+	engine.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String() + "/slow"
+
+	cfg := config.App{}
+	cfg.Server.ShutdownTimeout = 5 * time.Second
+	app := NewApp(engine, cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- app.serve(ctx, ln) }()
+
+	inFlight := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		inFlight <- resp.StatusCode
+	}()
+	<-started
+
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned before the in-flight request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	require.Equal(t, http.StatusNoContent, <-inFlight)
+	require.NoError(t, <-served)
+
+	_, err = net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+	require.Error(t, err)
+}
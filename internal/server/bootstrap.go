@@ -10,14 +10,18 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/internal/middleware"
 	"github.com/Jayleonc/service/internal/rbac"
+	servermiddleware "github.com/Jayleonc/service/internal/server/middleware"
 	"github.com/Jayleonc/service/pkg/auth"
 	"github.com/Jayleonc/service/pkg/cache"
 	"github.com/Jayleonc/service/pkg/config"
 	databasepkg "github.com/Jayleonc/service/pkg/database"
+	"github.com/Jayleonc/service/pkg/ginx/response"
 	"github.com/Jayleonc/service/pkg/observe/metrics"
 	"github.com/Jayleonc/service/pkg/observe/telemetry"
 	"github.com/Jayleonc/service/pkg/validation"
+	"github.com/Jayleonc/service/pkg/xerr"
 )
 
 // Bootstrap 负责初始化通用基础设施、注册全部业务模块并返回可运行的应用实例。
@@ -30,6 +34,11 @@ func Bootstrap(features []feature.Entry) (*App, error) {
 		return nil, fmt.Errorf("load config: %w", err)
 	}
 
+	// ======= 校验错误码消息目录 =======
+	if err := xerr.CheckCatalog(); err != nil {
+		return nil, fmt.Errorf("check error catalog: %w", err)
+	}
+
 	// ======= 配置运行模式 =======
 	switch strings.ToLower(strings.TrimSpace(cfg.Mode)) {
 	case "prod", "production", "pro":
@@ -44,13 +53,30 @@ func Bootstrap(features []feature.Entry) (*App, error) {
 		Level:     cfg.Logger.Level,
 		Pretty:    cfg.Logger.Pretty,
 		Directory: cfg.Logger.Directory,
+		Modules:   cfg.Logger.Modules,
+		Rotate: logger.RotateConfig{
+			Prefix:        cfg.Logger.FilePrefix,
+			MaxSize:       int64(cfg.Logger.MaxSizeMB) << 20,
+			Interval:      cfg.Logger.RotateInterval,
+			Compress:      cfg.Logger.Compress,
+			MaxAge:        cfg.Logger.MaxAge,
+			MaxTotalSize:  int64(cfg.Logger.MaxTotalSizeMB) << 20,
+			FlushInterval: cfg.Logger.FlushInterval,
+		},
+		AccessLog: cfg.Logger.AccessLog,
+		Redact: logger.RedactConfig{
+			Enabled:       cfg.Logger.Redact.Enabled,
+			Keys:          cfg.Logger.Redact.Keys,
+			Patterns:      cfg.Logger.Redact.Patterns,
+			MaskSQLParams: cfg.Logger.Redact.MaskSQLParams,
+		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("initialise logger: %w", err)
 	}
 
 	// ======= 初始化数据库 =======
-	gormLogger := databasepkg.NewLogger(logger.Level())
+	gormLogger := databasepkg.NewLogger()
 	db, err := databasepkg.Init(databasepkg.Config{
 		Driver:   cfg.Database.Driver,
 		Host:     cfg.Database.Host,
@@ -96,10 +122,42 @@ func Bootstrap(features []feature.Entry) (*App, error) {
 		ServiceName: cfg.Telemetry.ServiceName,
 		Endpoint:    cfg.Telemetry.Endpoint,
 		Enabled:     cfg.Telemetry.Enabled,
+		Exporter:    cfg.Telemetry.Exporter,
+		FilePath:    cfg.Telemetry.FilePath,
+		Insecure:    cfg.Telemetry.Insecure,
+		TLS: telemetry.TLSConfig{
+			CAFile:             cfg.Telemetry.TLS.CAFile,
+			CertFile:           cfg.Telemetry.TLS.CertFile,
+			KeyFile:            cfg.Telemetry.TLS.KeyFile,
+			ServerName:         cfg.Telemetry.TLS.ServerName,
+			InsecureSkipVerify: cfg.Telemetry.TLS.InsecureSkipVerify,
+		},
+		SampleRatio: cfg.Telemetry.SampleRatio,
+		ParentBased: cfg.Telemetry.ParentBased,
 	}); err != nil {
 		return nil, fmt.Errorf("setup telemetry: %w", err)
 	}
 
+	// ======= 初始化幂等中间件 =======
+	var idempotency gin.HandlerFunc
+	if cfg.Idempotency.Enabled {
+		var store middleware.IdempotencyStore
+		switch strings.ToLower(strings.TrimSpace(cfg.Idempotency.Store)) {
+		case "memory":
+			store = middleware.NewMemoryIdempotencyStore()
+		case "", "redis":
+			store = middleware.NewRedisIdempotencyStore(cacheClient)
+		default:
+			return nil, fmt.Errorf("unsupported idempotency store %q", cfg.Idempotency.Store)
+		}
+		idempotency = middleware.Idempotency(middleware.IdempotencyConfig{
+			Store:           store,
+			TTL:             cfg.Idempotency.TTL,
+			LockTTL:         cfg.Idempotency.LockTTL,
+			MaxRequestBytes: cfg.Idempotency.MaxRequestBytes,
+		})
+	}
+
 	// ======= 路由注册 =======
 	guards := &feature.RouteGuards{}
 	router := NewRouter(RouterConfig{
@@ -108,6 +166,14 @@ func Bootstrap(features []feature.Entry) (*App, error) {
 		TelemetryEnabled: cfg.Telemetry.Enabled,
 		TelemetryName:    cfg.Telemetry.ServiceName,
 		Guards:           guards,
+		Idempotency:      idempotency,
+		RequestID: servermiddleware.RequestIDConfig{
+			TrustInbound: cfg.Server.TrustRequestID,
+		},
+		Problem: response.ProblemConfig{
+			Enabled:  cfg.Server.ProblemDetails,
+			TypeBase: cfg.Server.ProblemTypeBase,
+		},
 	})
 
 	deps := &feature.Dependencies{
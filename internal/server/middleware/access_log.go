@@ -37,13 +37,14 @@ func AccessLogger() gin.HandlerFunc {
 		}
 
 		ctx := c.Request.Context()
+		log := applogger.Access(ctx)
 		switch {
 		case status >= http.StatusInternalServerError:
-			applogger.Error(ctx, "request failed", args...)
+			log.ErrorContext(ctx, "request failed", args...)
 		case status >= http.StatusBadRequest:
-			applogger.Warn(ctx, "request completed with client error", args...)
+			log.WarnContext(ctx, "request completed with client error", args...)
 		default:
-			applogger.Info(ctx, "request completed", args...)
+			log.InfoContext(ctx, "request completed", args...)
 		}
 	}
 }
@@ -1,14 +1,16 @@
 package middleware
 
 import (
-	"net/http"
+	"fmt"
 	"runtime/debug"
 
+	"github.com/Jayleonc/service/pkg/ginx/response"
 	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+	"github.com/Jayleonc/service/pkg/xerr"
 	"github.com/gin-gonic/gin"
 )
 
-// Recovery 捕获 panic 并记录详细错误信息。
+// Recovery 捕获 panic 并记录详细错误信息，响应与其他失败响应使用相同的格式。
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -21,10 +23,8 @@ func Recovery() gin.HandlerFunc {
 					"request_id", applogger.RequestIDFromContext(ctx),
 				}
 				applogger.Error(ctx, "panic recovered", args...)
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error":      "internal server error",
-					"request_id": applogger.RequestIDFromContext(ctx),
-				})
+				response.Fail(c, xerr.ErrInternalServer.Wrap(fmt.Errorf("panic: %v", rec)))
+				c.Abort()
 			}
 		}()
 
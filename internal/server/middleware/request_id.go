@@ -7,13 +7,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// RequestID 为每个请求生成唯一 ID 并注入带有该 ID 的日志器。
-func RequestID(base *slog.Logger) gin.HandlerFunc {
+// maxRequestIDLength 限制可信任的入站请求 ID 长度，避免超长的值污染日志。
+const maxRequestIDLength = 128
+
+// RequestIDConfig 控制请求 ID 的来源。
+type RequestIDConfig struct {
+	// TrustInbound 为 true 时沿用入站请求中合法的 X-Request-ID，仅应在服务部署于可信网关之后时开启。
+	TrustInbound bool
+}
+
+// RequestID 为每个请求确定唯一 ID 并注入带有该 ID 的日志器：开启 TrustInbound 且入站 ID 合法时沿用该值，否则重新生成。
+func RequestID(base *slog.Logger, cfg RequestIDConfig) gin.HandlerFunc {
 	if base == nil {
 		base = applogger.Default()
 	}
 	return func(c *gin.Context) {
-		id := applogger.GenerateRequestID()
+		id := c.GetHeader(applogger.RequestIDHeader)
+		if !cfg.TrustInbound || !validRequestID(id) {
+			id = applogger.GenerateRequestID()
+		}
 
 		requestLogger := base.With(slog.String("request_id", id))
 		ctx := applogger.WithContext(c.Request.Context(), requestLogger)
@@ -21,12 +33,28 @@ func RequestID(base *slog.Logger) gin.HandlerFunc {
 
 		c.Request = c.Request.WithContext(ctx)
 		c.Set("request_id", id)
-		c.Writer.Header().Set("X-Request-ID", id)
+		c.Writer.Header().Set(applogger.RequestIDHeader, id)
 
 		c.Next()
 	}
 }
 
+// validRequestID 只接受由字母、数字与 - _ . : 组成且不超过 maxRequestIDLength 的 ID。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // LoggerFromContext 返回上下文中的日志器（包含 request_id）。
 func LoggerFromContext(c *gin.Context) *slog.Logger {
 	if c == nil {
@@ -49,7 +77,7 @@ func RequestIDFromContext(c *gin.Context) string {
 
 // RequestIDHeader 返回用于响应的请求 ID 头名称。
 func RequestIDHeader() string {
-	return "X-Request-ID"
+	return applogger.RequestIDHeader
 }
 
 // AbortWithRequestID 生成包含 request_id 的 JSON 错误响应。
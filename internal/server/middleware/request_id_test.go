@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// TestValidRequestID 验证入站请求 ID 的字符集与长度限制。
+func TestValidRequestID(t *testing.T) {
+	cases := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "UUID", id: "0190b6a2-7c1e-7d4a-9b1f-3f2d5e6a7b8c", want: true},
+		{name: "允许的标点", id: "gw_1.trace:42", want: true},
+		{name: "最大长度", id: strings.Repeat("a", maxRequestIDLength), want: true},
+		{name: "空字符串"},
+		{name: "超过最大长度", id: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "包含空格", id: "req 1"},
+		{name: "包含换行", id: "req-1\nforged=true"},
+		{name: "包含引号", id: `req-"1"`},
+		{name: "非 ASCII 字符", id: "请求-1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, validRequestID(tc.id))
+		})
+	}
+}
+
+// TestRequestID 验证仅在开启 TrustInbound 且入站 ID 合法时沿用该值，否则重新生成；结果写入响应头与请求上下文。
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name    string
+		trust   bool
+		inbound string
+		want    string
+	}{
+		{name: "信任合法的入站 ID", trust: true, inbound: "gw-123", want: "gw-123"},
+		{name: "信任但入站 ID 非法", trust: true, inbound: "gw 123"},
+		{name: "信任但未携带", trust: true},
+		{name: "不信任入站 ID", inbound: "gw-123"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fromContext string
+			router := gin.New()
+			router.Use(RequestID(nil, RequestIDConfig{TrustInbound: tc.trust}))
+			router.GET("/", func(c *gin.Context) {
+				fromContext = RequestIDFromContext(c)
+				require.Equal(t, fromContext, c.GetString("request_id"))
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.inbound != "" {
+				req.Header.Set(applogger.RequestIDHeader, tc.inbound)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(applogger.RequestIDHeader)
+			require.Equal(t, got, fromContext)
+			require.True(t, validRequestID(got))
+			if tc.want != "" {
+				require.Equal(t, tc.want, got)
+			} else {
+				require.NotEqual(t, tc.inbound, got)
+			}
+		})
+	}
+}
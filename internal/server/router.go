@@ -16,7 +16,9 @@ import (
 	"github.com/Jayleonc/service/internal/feature"
 	sharedmiddleware "github.com/Jayleonc/service/internal/middleware"
 	servermiddleware "github.com/Jayleonc/service/internal/server/middleware"
+	"github.com/Jayleonc/service/pkg/ginx/response"
 	"github.com/Jayleonc/service/pkg/validation"
+	"github.com/Jayleonc/service/pkg/xerr"
 )
 
 // RouterConfig 定义所有模块共享的 HTTP 中间件配置。
@@ -26,6 +28,12 @@ type RouterConfig struct {
 	TelemetryEnabled bool
 	TelemetryName    string
 	Guards           *feature.RouteGuards
+	// Idempotency 为 /v1 下需要认证的路由提供 Idempotency-Key 支持，挂在认证中间件之后；为空时不启用。
+	Idempotency gin.HandlerFunc
+	// RequestID 控制是否信任入站请求携带的 X-Request-ID。
+	RequestID servermiddleware.RequestIDConfig
+	// Problem 控制失败响应是否使用 RFC 9457 问题详情格式。
+	Problem response.ProblemConfig
 }
 
 // Router 封装 Gin 引擎并提供面向功能模块的注册能力。
@@ -33,6 +41,7 @@ type Router struct {
 	engine             *gin.Engine
 	api                *gin.RouterGroup
 	guards             *feature.RouteGuards
+	idempotency        gin.HandlerFunc
 	permissionEnforcer func(string) gin.HandlerFunc
 	collected          map[string]struct{}
 }
@@ -46,9 +55,15 @@ func NewRouter(cfg RouterConfig) *Router {
 	} else {
 		validation.Init()
 	}
+	response.ConfigureProblemDetails(cfg.Problem)
 
 	r := gin.New()
-	r.Use(servermiddleware.RequestID(cfg.Logger))
+	r.HandleMethodNotAllowed = true
+	// otelgin 需要最先执行：后续中间件的日志与错误响应才能取到当前 span 的 trace_id。
+	if cfg.TelemetryEnabled {
+		r.Use(otelgin.Middleware(cfg.TelemetryName))
+	}
+	r.Use(servermiddleware.RequestID(cfg.Logger, cfg.RequestID))
 	r.Use(servermiddleware.AccessLogger())
 	r.Use(servermiddleware.Recovery())
 	if cfg.Registry != nil {
@@ -56,19 +71,26 @@ func NewRouter(cfg RouterConfig) *Router {
 		r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(cfg.Registry, promhttp.HandlerOpts{})))
 	}
 
-	if cfg.TelemetryEnabled {
-		r.Use(otelgin.Middleware(cfg.TelemetryName))
-	}
-
 	r.POST("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	// 未匹配的路径与方法同样使用统一的失败响应；405 的 Allow 头由 Gin 写入。
+	r.NoRoute(func(c *gin.Context) {
+		response.Fail(c, xerr.ErrNotFound)
+	})
+	r.NoMethod(func(c *gin.Context) {
+		response.Fail(c, xerr.ErrMethodNotAllowed)
+	})
+
+	api := r.Group("/v1")
+
 	return &Router{
-		engine:    r,
-		api:       r.Group("/v1"),
-		guards:    cfg.Guards,
-		collected: make(map[string]struct{}),
+		engine:      r,
+		api:         api,
+		guards:      cfg.Guards,
+		idempotency: cfg.Idempotency,
+		collected:   make(map[string]struct{}),
 	}
 }
 
@@ -88,7 +110,7 @@ func (r *Router) RegisterModule(pathPrefix string, routes feature.ModuleRoutes)
 		guards = *r.guards
 	}
 
-	register := func(defs []feature.RouteDefinition, middlewares []gin.HandlerFunc) {
+	register := func(defs []feature.RouteDefinition, middlewares []gin.HandlerFunc, authenticated bool) {
 		if len(defs) == 0 {
 			return
 		}
@@ -109,8 +131,15 @@ func (r *Router) RegisterModule(pathPrefix string, routes feature.ModuleRoutes)
 			}
 
 			handlers := make([]gin.HandlerFunc, 0, 1)
+			// 幂等键按认证主体隔离，只能在认证中间件之后生效；公开接口与声明跳过的路由不提供幂等支持。
+			if authenticated && r.idempotency != nil && !def.SkipIdempotency {
+				handlers = append(handlers, r.idempotency)
+			}
 			if def.RequiredPermission != "" {
 				r.collected[def.RequiredPermission] = struct{}{}
+				if def.AuthorizesResource {
+					handlers = append(handlers, feature.MarkAuthorizesResource)
+				}
 				if r.permissionEnforcer != nil {
 					handlers = append(handlers, r.permissionEnforcer(def.RequiredPermission))
 				}
@@ -120,9 +149,9 @@ func (r *Router) RegisterModule(pathPrefix string, routes feature.ModuleRoutes)
 		}
 	}
 
-	register(routes.PublicRoutes, guards.Public)
-	register(routes.AuthenticatedRoutes, guards.Authenticated)
-	register(routes.AdminRoutes, guards.Admin)
+	register(routes.PublicRoutes, guards.Public, false)
+	register(routes.AuthenticatedRoutes, guards.Authenticated, true)
+	register(routes.AdminRoutes, guards.Admin, true)
 }
 
 func sanitizePath(prefix, path string) string {
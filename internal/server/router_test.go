@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/internal/feature"
+	servermiddleware "github.com/Jayleonc/service/internal/server/middleware"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// TestRouterProblemDetails 验证未匹配的路径、方法与 panic 都以问题详情格式返回，并携带请求 ID。
+func TestRouterProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { response.ConfigureProblemDetails(response.ProblemConfig{}) })
+
+	router := NewRouter(RouterConfig{
+		RequestID: servermiddleware.RequestIDConfig{TrustInbound: true},
+		Problem:   response.ProblemConfig{Enabled: true, TypeBase: "https://errors.example.com"},
+	})
+	router.Engine().GET("/panic", func(*gin.Context) { panic("boom") })
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   int
+		wantAllow  string
+	}{
+		{name: "路径不存在", method: http.MethodGet, path: "/missing", wantStatus: http.StatusNotFound, wantCode: xerr.ErrNotFound.Code},
+		{name: "方法不允许", method: http.MethodGet, path: "/health", wantStatus: http.StatusMethodNotAllowed, wantCode: xerr.ErrMethodNotAllowed.Code, wantAllow: http.MethodPost},
+		{name: "panic", method: http.MethodGet, path: "/panic", wantStatus: http.StatusInternalServerError, wantCode: xerr.ErrInternalServer.Code},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set(applogger.RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			router.Engine().ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code)
+			require.Equal(t, response.ProblemContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, tc.wantAllow, w.Header().Get("Allow"))
+			require.NotContains(t, w.Body.String(), "boom")
+
+			var problem response.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, tc.wantStatus, problem.Status)
+			require.Equal(t, http.StatusText(tc.wantStatus), problem.Title)
+			require.Equal(t, tc.wantCode, problem.Code)
+			require.Equal(t, "https://errors.example.com/"+strconv.Itoa(tc.wantCode), problem.Type)
+			require.Equal(t, tc.path, problem.Instance)
+			require.Equal(t, "req-1", problem.RequestID)
+		})
+	}
+}
+
+// TestRouterAuthorizesResource 验证只有声明了资源级校验的路由在权限中间件中可见该标记。
+func TestRouterAuthorizesResource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := NewRouter(RouterConfig{})
+	seen := make(map[string]bool)
+	router.SetPermissionEnforcerFactory(func(permission string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			seen[c.FullPath()] = feature.AuthorizesResource(c)
+		}
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.RegisterModule("article", feature.ModuleRoutes{
+		AuthenticatedRoutes: []feature.RouteDefinition{
+			{Path: "/update", Handler: ok, RequiredPermission: "article:update", AuthorizesResource: true},
+			{Path: "/list", Handler: ok, RequiredPermission: "article:list"},
+		},
+	})
+
+	for _, path := range []string{"/v1/article/update", "/v1/article/list"} {
+		w := httptest.NewRecorder()
+		router.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusNoContent, w.Code, path)
+	}
+	require.Equal(t, map[string]bool{"/v1/article/update": true, "/v1/article/list": false}, seen)
+}
+
+// TestRouterSkipIdempotency 验证幂等中间件只挂在需要认证且未声明跳过的路由上。
+func TestRouterSkipIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := NewRouter(RouterConfig{
+		Idempotency: func(c *gin.Context) { c.Header("X-Idempotency", "on") },
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.RegisterModule("invitation", feature.ModuleRoutes{
+		PublicRoutes: []feature.RouteDefinition{{Path: "/accept", Handler: ok}},
+		AuthenticatedRoutes: []feature.RouteDefinition{
+			{Path: "/create", Handler: ok, SkipIdempotency: true},
+			{Path: "/revoke", Handler: ok},
+		},
+	})
+
+	cases := map[string]string{
+		"/v1/invitation/accept": "",
+		"/v1/invitation/create": "",
+		"/v1/invitation/revoke": "on",
+	}
+	for path, want := range cases {
+		w := httptest.NewRecorder()
+		router.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusNoContent, w.Code, path)
+		require.Equal(t, want, w.Header().Get("X-Idempotency"), path)
+	}
+}
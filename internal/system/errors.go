@@ -0,0 +1,23 @@
+package system
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// 系统模块错误码范围：5000-5999
+var (
+	ErrUnknownLogModule = xerr.New(5001, "unknown log module").WithStatus(http.StatusBadRequest)
+	ErrInvalidLogLevel  = xerr.New(5002, "invalid log level").WithStatus(http.StatusBadRequest)
+)
+
+// locales 保存系统模块错误码的中英文消息。
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	xerr.MustRegisterCatalog(locales, "locales")
+}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+	"github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// Handler 暴露运维相关的管理接口。
+type Handler struct{}
+
+// NewHandler 创建系统模块的处理器。
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// GetRoutes 声明系统模块的路由，全部仅限管理员访问。
+func (h *Handler) GetRoutes() feature.ModuleRoutes {
+	return feature.ModuleRoutes{
+		AdminRoutes: []feature.RouteDefinition{
+			{Path: "log/levels", Handler: h.logLevels},
+			{Path: "log/level/set", Handler: h.setLogLevel},
+		},
+	}
+}
+
+type setLogLevelRequest struct {
+	// Module 为模块名，root 表示全局级别。
+	Module string `json:"module" binding:"required"`
+	// Level 为 debug、info、warn、error，或 inherit（仅模块可用）。
+	Level string `json:"level" binding:"required"`
+}
+
+func (h *Handler) logLevels(c *gin.Context) {
+	response.Success(c, logger.Levels())
+}
+
+// setLogLevel 在运行时调整日志级别，调整结果只保存在内存中，重启后以配置为准。
+func (h *Handler) setLogLevel(c *gin.Context) {
+	var req setLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	previous, err := logger.SetLevel(req.Module, req.Level)
+	if err != nil {
+		switch {
+		case errors.Is(err, logger.ErrUnknownModule):
+			response.Fail(c, ErrUnknownLogModule.Wrap(err))
+		case errors.Is(err, logger.ErrInvalidLevel):
+			response.Fail(c, ErrInvalidLogLevel.Wrap(err))
+		default:
+			response.Fail(c, err)
+		}
+		return
+	}
+
+	session, _ := feature.GetAuthContext(c)
+	logger.Audit(c.Request.Context(), "log level changed",
+		"source", "api",
+		"actor", session.UserID.String(),
+		"module", req.Module,
+		"from", previous,
+		"to", req.Level,
+	)
+
+	response.Success(c, logger.Levels())
+}
@@ -0,0 +1,68 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// Register 注册系统运维接口，并监听配置文件中日志级别的变化。
+func Register(ctx context.Context, deps *feature.Dependencies) error {
+	if err := deps.Require("Router"); err != nil {
+		return fmt.Errorf("system feature dependencies: %w", err)
+	}
+
+	handler := NewHandler()
+	deps.Router.RegisterModule("system", handler.GetRoutes())
+
+	current := deps.Config.Logger
+	err := config.Watch(func(cfg config.App) {
+		reloadLogLevels(ctx, current, cfg.Logger)
+		current = cfg.Logger
+	}, func(err error) {
+		logger.Error(ctx, "reload config failed", "error", err)
+	})
+	if err != nil {
+		return fmt.Errorf("watch config: %w", err)
+	}
+
+	if deps.Logger != nil {
+		deps.Logger.Info("system feature initialised", "pattern", "structured")
+	}
+
+	return nil
+}
+
+// reloadLogLevels 只应用配置文件中发生变化的日志级别，未变化的模块保留通过接口做出的调整。
+// 从 logger.modules 中移除的模块恢复为跟随全局级别。
+func reloadLogLevels(ctx context.Context, previous, next config.LoggerConfig) {
+	changes := make(map[string]string)
+	if next.Level != nil && (previous.Level == nil || *previous.Level != *next.Level) {
+		changes[logger.RootModule] = *next.Level
+	}
+	for module, level := range next.Modules {
+		if previous.Modules[module] != level {
+			changes[module] = level
+		}
+	}
+	for module := range previous.Modules {
+		if _, ok := next.Modules[module]; !ok {
+			changes[module] = "inherit"
+		}
+	}
+
+	for _, module := range slices.Sorted(maps.Keys(changes)) {
+		level := changes[module]
+		from, err := logger.SetLevel(module, level)
+		if err != nil {
+			logger.Error(ctx, "apply log level from config failed", "module", module, "level", level, "error", err)
+			continue
+		}
+		logger.Audit(ctx, "log level changed", "source", "config", "module", module, "from", from, "to", level)
+	}
+}
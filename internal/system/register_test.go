@@ -0,0 +1,90 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// currentLevels 按模块返回当前级别，跟随全局级别的模块记为 inherit。
+func currentLevels(t *testing.T) map[string]string {
+	t.Helper()
+
+	levels := make(map[string]string)
+	for _, level := range logger.Levels() {
+		if level.Inherited {
+			levels[level.Module] = "inherit"
+		} else {
+			levels[level.Module] = level.Level
+		}
+	}
+	return levels
+}
+
+// TestReloadLogLevels 验证重新加载配置时只应用发生变化的级别：未变化的模块保留通过接口做出的调整，
+// 从配置中移除的模块恢复跟随全局级别，无效的级别被跳过而不影响其他模块。
+func TestReloadLogLevels(t *testing.T) {
+	names := []string{"reload-test-kept", "reload-test-changed", "reload-test-removed", "reload-test-added", "reload-test-invalid"}
+	for _, name := range names {
+		logger.Module(name)
+	}
+	originalRoot := logger.Level().String()
+	t.Cleanup(func() {
+		for _, name := range names {
+			_, _ = logger.SetLevel(name, "inherit")
+		}
+		_, _ = logger.SetLevel(logger.RootModule, originalRoot)
+	})
+
+	info := "info"
+	previous := config.LoggerConfig{
+		Level: &info,
+		Modules: map[string]string{
+			"reload-test-kept":    "warn",
+			"reload-test-changed": "warn",
+			"reload-test-removed": "debug",
+			"reload-test-invalid": "warn",
+		},
+	}
+	for module, level := range previous.Modules {
+		_, err := logger.SetLevel(module, level)
+		require.NoError(t, err)
+	}
+	// 通过接口做出的调整：全局级别与 kept 模块均未在配置中变化，重新加载后应保留。
+	_, err := logger.SetLevel(logger.RootModule, "error")
+	require.NoError(t, err)
+	_, err = logger.SetLevel("reload-test-kept", "debug")
+	require.NoError(t, err)
+
+	next := config.LoggerConfig{
+		Level: &info,
+		Modules: map[string]string{
+			"reload-test-kept":    "warn",
+			"reload-test-changed": "error",
+			"reload-test-added":   "debug",
+			"reload-test-invalid": "verbose",
+		},
+	}
+	reloadLogLevels(context.Background(), previous, next)
+
+	levels := currentLevels(t)
+	require.Equal(t, "error", levels[logger.RootModule])
+	require.Equal(t, "debug", levels["reload-test-kept"])
+	require.Equal(t, "error", levels["reload-test-changed"])
+	require.Equal(t, "inherit", levels["reload-test-removed"])
+	require.Equal(t, "debug", levels["reload-test-added"])
+	require.Equal(t, "warn", levels["reload-test-invalid"])
+
+	// 全局级别在配置中发生变化时才覆盖通过接口做出的调整。
+	warn := "warn"
+	changed := next
+	changed.Level = &warn
+	reloadLogLevels(context.Background(), next, changed)
+	levels = currentLevels(t)
+	require.Equal(t, "warn", levels[logger.RootModule])
+	require.Equal(t, "debug", levels["reload-test-kept"])
+}
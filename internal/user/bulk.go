@@ -0,0 +1,473 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// 批量导入导出支持的文件格式。
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// csvRoleSeparator 分隔 CSV 中 roles 列的多个角色名。
+const csvRoleSeparator = ";"
+
+// exportBatchSize 控制导出时每批从数据库读取的用户数量。
+const exportBatchSize = 500
+
+// maxJSONLLineSize 限制 JSONL 单行的最大长度。
+const maxJSONLLineSize = 1 << 20
+
+// csvExportColumns 是导出 CSV 的表头。
+var csvExportColumns = []string{"id", "name", "email", "phone", "status", "roles", "created_at"}
+
+// importValidator 使用 json 字段名校验导入行，使错误信息与文件中的列名一致。
+var importValidator = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}()
+
+// ImportRecord 描述导入文件中的一行用户数据，roles 为空时分配默认的 USER 角色。
+type ImportRecord struct {
+	Name     string   `json:"name" validate:"required,max=255"`
+	Email    string   `json:"email" validate:"required,email,max=255"`
+	Phone    string   `json:"phone" validate:"omitempty,max=64"`
+	Password string   `json:"password" validate:"required,min=8"`
+	Roles    []string `json:"roles" validate:"omitempty,dive,required"`
+}
+
+// ExportRecord 描述导出文件中的一行用户数据，不包含任何凭据信息。
+type ExportRecord struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Status    Status    `json:"status"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ImportOptions 控制批量导入的行为。
+type ImportOptions struct {
+	Format string
+	// DryRun 为 true 时只校验数据并生成报告，不写入数据库。
+	DryRun bool
+}
+
+// ImportRowError 记录导入失败的行，Row 为数据记录的序号（从 1 开始，不含表头与空行）。
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Email   string `json:"email,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport 汇总批量导入的结果；单行失败不会中断整个批次。
+type ImportReport struct {
+	DryRun bool `json:"dryRun"`
+	Total  int  `json:"total"`
+	// Succeeded 为成功创建的用户数，试运行时为通过校验的行数。
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// FormatFromPath 根据文件扩展名推断导入导出格式，无法识别时返回空字符串。
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	default:
+		return ""
+	}
+}
+
+// ParseFormat 校验并规范化格式名称。
+func ParseFormat(format string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(format)); normalized {
+	case FormatCSV, FormatJSONL:
+		return normalized, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// ContentType 返回格式对应的 HTTP Content-Type。
+func ContentType(format string) string {
+	if format == FormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// CreateWithRoles 在同一事务中创建用户并写入角色，保证导入的每一行要么完整成功要么不留痕迹。
+func (r *Repository) CreateWithRoles(ctx context.Context, user *User, roles []*rbac.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Model(user).Association("Roles").Replace(roles)
+	})
+}
+
+// ExportInBatches 按主键顺序分批读取用户及其当前生效的角色，避免一次性加载整张表。
+func (r *Repository) ExportInBatches(ctx context.Context, size int, fn func(users []User) error) error {
+	var batch []User
+	result := r.db.WithContext(ctx).
+		Model(&User{}).
+		Preload("Roles").
+		Order("id").
+		FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+			users := make([]*User, 0, len(batch))
+			for i := range batch {
+				users = append(users, &batch[i])
+			}
+			if err := r.FilterActiveRoles(ctx, users...); err != nil {
+				return err
+			}
+			return fn(batch)
+		})
+	return result.Error
+}
+
+// ImportUsers 逐行读取 CSV 或 JSONL 并创建用户，每行的错误记录在报告中而不会中断批次。
+func (s *Service) ImportUsers(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	format, err := ParseFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	var next func() (ImportRecord, error)
+	switch format {
+	case FormatCSV:
+		next, err = newCSVImportReader(r)
+	default:
+		next = newJSONLImportReader(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	importer := &userImporter{
+		svc:    s,
+		dryRun: opts.DryRun,
+		seen:   make(map[string]int),
+		roles:  make(map[string]*rbac.Role),
+	}
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportRowError{}}
+
+	for row := 1; ; row++ {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var rowErr *importRowError
+			if !errors.As(err, &rowErr) {
+				return nil, err
+			}
+		} else {
+			err = importer.importRow(ctx, row, record)
+		}
+
+		report.Total++
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			report.Failed++
+			report.Errors = append(report.Errors, ImportRowError{
+				Row:     row,
+				Email:   strings.ToLower(strings.TrimSpace(record.Email)),
+				Message: importErrorMessage(err),
+			})
+			continue
+		}
+		report.Succeeded++
+	}
+
+	log.Info(ctx, "users imported", "format", format, "dryRun", opts.DryRun, "total", report.Total, "succeeded", report.Succeeded, "failed", report.Failed)
+	return report, nil
+}
+
+// ExportUsers 以流式方式将未删除的用户及其角色写入 w。
+func (s *Service) ExportUsers(ctx context.Context, w io.Writer, format string) error {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	var write func(record ExportRecord) error
+	var flush func() error
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvExportColumns); err != nil {
+			return err
+		}
+		write = func(record ExportRecord) error {
+			return writer.Write([]string{
+				record.ID.String(),
+				record.Name,
+				record.Email,
+				record.Phone,
+				string(record.Status),
+				strings.Join(record.Roles, csvRoleSeparator),
+				record.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		encoder := json.NewEncoder(w)
+		write = func(record ExportRecord) error { return encoder.Encode(record) }
+		flush = func() error { return nil }
+	}
+
+	err = s.repo.ExportInBatches(ctx, exportBatchSize, func(users []User) error {
+		for _, u := range users {
+			record := ExportRecord{
+				ID:        u.ID,
+				Name:      u.Name,
+				Email:     u.Email,
+				Phone:     u.Phone,
+				Status:    u.Status,
+				Roles:     roleNames(u.Roles),
+				CreatedAt: u.CreatedAt,
+			}
+			if record.Roles == nil {
+				record.Roles = []string{}
+			}
+			if err := write(record); err != nil {
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// 没有任何用户时也需要输出 CSV 表头。
+	return flush()
+}
+
+// userImporter 保存一次导入过程中的状态：批内已出现的邮箱与角色查询缓存。
+type userImporter struct {
+	svc    *Service
+	dryRun bool
+	seen   map[string]int
+	roles  map[string]*rbac.Role
+}
+
+func (im *userImporter) importRow(ctx context.Context, row int, record ImportRecord) error {
+	record.Name = strings.TrimSpace(record.Name)
+	record.Email = strings.ToLower(strings.TrimSpace(record.Email))
+	record.Phone = strings.TrimSpace(record.Phone)
+	if err := importValidator.Struct(record); err != nil {
+		return err
+	}
+
+	if first, ok := im.seen[record.Email]; ok {
+		return &importRowError{message: fmt.Sprintf("duplicate email, first seen in row %d", first)}
+	}
+	im.seen[record.Email] = row
+
+	if _, err := im.svc.repo.GetByEmail(ctx, record.Email); err == nil {
+		return ErrEmailExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	roles, err := im.resolveRoles(ctx, record.Roles)
+	if err != nil {
+		return err
+	}
+	if im.dryRun {
+		return nil
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(record.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user := &User{
+		ID:           uuid.Must(uuid.NewV7()),
+		Name:         record.Name,
+		Email:        record.Email,
+		PasswordHash: string(passwordHash),
+		Phone:        record.Phone,
+		Status:       StatusActive,
+	}
+	if err := im.svc.repo.CreateWithRoles(ctx, user, roles); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrEmailExists
+		}
+		return err
+	}
+	return nil
+}
+
+// resolveRoles 按名称查询角色并缓存结果，同一批次中重复出现的角色只查询一次。
+func (im *userImporter) resolveRoles(ctx context.Context, names []string) ([]*rbac.Role, error) {
+	normalized := rbac.UniqueNormalized(names)
+	if len(normalized) == 0 {
+		normalized = []string{constant.RoleUser}
+	}
+
+	roles := make([]*rbac.Role, 0, len(normalized))
+	for _, name := range normalized {
+		role, ok := im.roles[name]
+		if !ok {
+			found, err := im.svc.rbacService.GetRolesByNames(ctx, []string{name})
+			if err != nil && !errors.Is(err, rbac.ErrResourceNotFound) {
+				return nil, err
+			}
+			if len(found) > 0 {
+				role = found[0]
+			}
+			im.roles[name] = role
+		}
+		if role == nil {
+			return nil, &importRowError{message: fmt.Sprintf("unknown role %q", name)}
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// importRowError 表示只影响当前行的错误，例如无法解析的行或未知角色。
+type importRowError struct {
+	message string
+}
+
+func (e *importRowError) Error() string { return e.message }
+
+// importErrorMessage 将导入行的错误转换为报告中可读的描述，内部错误不对外暴露细节。
+func importErrorMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	var rowErr *importRowError
+	switch {
+	case errors.As(err, &validationErrs):
+		messages := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			messages = append(messages, fmt.Sprintf("%s failed on '%s'", fe.Field(), fe.Tag()))
+		}
+		return strings.Join(messages, "; ")
+	case errors.As(err, &rowErr), errors.Is(err, ErrEmailExists), errors.Is(err, ErrRolesRequired):
+		return err.Error()
+	default:
+		return ErrCreateFailed.Error()
+	}
+}
+
+// newCSVImportReader 读取表头并按列名映射字段。可识别的列为 name、email、phone、password、roles，
+// 其余列会被忽略；email 列缺失时返回 ErrInvalidImportFile。
+func newCSVImportReader(r io.Reader) (func() (ImportRecord, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidImportFile.WithMessage("import file is empty")
+		}
+		return nil, ErrInvalidImportFile
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, ErrInvalidImportFile.WithMessage("import file is missing the email column")
+	}
+
+	return func() (ImportRecord, error) {
+		fields, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return ImportRecord{}, &importRowError{message: fmt.Sprintf("malformed csv row: %v", parseErr.Err)}
+			}
+			return ImportRecord{}, err
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(fields) {
+				return fields[i]
+			}
+			return ""
+		}
+		record := ImportRecord{
+			Name:     value("name"),
+			Email:    value("email"),
+			Phone:    value("phone"),
+			Password: value("password"),
+		}
+		if roles := strings.TrimSpace(value("roles")); roles != "" {
+			record.Roles = strings.Split(roles, csvRoleSeparator)
+		}
+		return record, nil
+	}, nil
+}
+
+// newJSONLImportReader 每行解析一个 JSON 对象，跳过空行。
+func newJSONLImportReader(r io.Reader) func() (ImportRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+
+	return func() (ImportRecord, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var record ImportRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				return ImportRecord{}, &importRowError{message: "malformed json line"}
+			}
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return ImportRecord{}, err
+		}
+		return ImportRecord{}, io.EOF
+	}
+}
@@ -0,0 +1,317 @@
+package user
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// importedEmails 返回数据库中未删除用户的邮箱，按字母排序。
+func importedEmails(t *testing.T, svc *Service) []string {
+	t.Helper()
+
+	var emails []string
+	require.NoError(t, svc.repo.Query(context.Background()).Order("email").Pluck("email", &emails).Error)
+	return emails
+}
+
+// TestImportUsers 验证逐行导入：无效行记录在报告中而不中断批次，文件内重复的邮箱指向首次出现的行，试运行不写入数据库。
+func TestImportUsers(t *testing.T) {
+	csvFile := strings.Join([]string{
+		"\ufeffName,Email,Password,Roles,Extra",
+		"Alice, Alice@Example.com ,password123,admin;user,ignored",
+		"Bob,bob@example.com,short,,",
+		"Carol,not-an-email,password123,,",
+		"Dave,alice@example.com,password123,,",
+		"Erin,existing@example.com,password123,,",
+		"Frank,frank@example.com,password123,AUDITOR,",
+		`Grace,"grace@example.com,password123,,`,
+	}, "\n")
+	jsonlFile := strings.Join([]string{
+		`{"name": "Alice", "email": "alice@example.com", "password": "password123", "roles": ["ADMIN"]}`,
+		``,
+		`{"name": "Bob", "email": "bob@example.com"`,
+		`{"name": "", "email": "ALICE@example.com", "password": "password123"}`,
+		`{"name": "Dave", "email": "dave@example.com", "password": "password123", "phone": " 123 "}`,
+	}, "\n")
+
+	cases := []struct {
+		name       string
+		format     string
+		file       string
+		dryRun     bool
+		wantReport ImportReport
+		wantEmails []string
+	}{
+		{
+			name:   "CSV",
+			format: "CSV",
+			file:   csvFile,
+			wantReport: ImportReport{
+				Total:     7,
+				Succeeded: 1,
+				Failed:    6,
+				Errors: []ImportRowError{
+					{Row: 2, Email: "bob@example.com", Message: "password failed on 'min'"},
+					{Row: 3, Email: "not-an-email", Message: "email failed on 'email'"},
+					{Row: 4, Email: "alice@example.com", Message: "duplicate email, first seen in row 1"},
+					{Row: 5, Email: "existing@example.com", Message: ErrEmailExists.Error()},
+					{Row: 6, Email: "frank@example.com", Message: `unknown role "AUDITOR"`},
+					{Row: 7, Message: `malformed csv row: extraneous or missing " in quoted-field`},
+				},
+			},
+			wantEmails: []string{"alice@example.com", "existing@example.com"},
+		},
+		{
+			name:   "JSONL",
+			format: "ndjson",
+			file:   jsonlFile,
+			wantReport: ImportReport{
+				Total:     4,
+				Succeeded: 2,
+				Failed:    2,
+				Errors: []ImportRowError{
+					{Row: 2, Message: "malformed json line"},
+					{Row: 3, Email: "alice@example.com", Message: "name failed on 'required'"},
+				},
+			},
+			wantEmails: []string{"alice@example.com", "dave@example.com", "existing@example.com"},
+		},
+		{
+			name:   "试运行",
+			format: "jsonl",
+			file:   jsonlFile,
+			dryRun: true,
+			wantReport: ImportReport{
+				DryRun:    true,
+				Total:     4,
+				Succeeded: 2,
+				Failed:    2,
+				Errors: []ImportRowError{
+					{Row: 2, Message: "malformed json line"},
+					{Row: 3, Email: "alice@example.com", Message: "name failed on 'required'"},
+				},
+			},
+			wantEmails: []string{"existing@example.com"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, db := newTestService(t, config.UserConfig{})
+			createTestUser(t, db, "existing@example.com", StatusActive)
+
+			report, err := svc.ImportUsers(context.Background(), strings.NewReader(tc.file), ImportOptions{Format: tc.format, DryRun: tc.dryRun})
+			require.NoError(t, err)
+			require.Equal(t, tc.wantReport, *report)
+			require.Equal(t, tc.wantEmails, importedEmails(t, svc))
+		})
+	}
+}
+
+// TestImportUsersRoles 验证导入的用户获得文件中指定的角色，未指定时分配 USER 角色。
+func TestImportUsersRoles(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t, config.UserConfig{})
+
+	file := "name,email,password,roles\n" +
+		"Alice,alice@example.com,password123,admin; user\n" +
+		"Bob,bob@example.com,password123,\n"
+	report, err := svc.ImportUsers(ctx, strings.NewReader(file), ImportOptions{Format: FormatCSV})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Succeeded)
+
+	cases := []struct {
+		email     string
+		wantRoles []string
+	}{
+		{email: "alice@example.com", wantRoles: []string{constant.RoleAdmin, constant.RoleUser}},
+		{email: "bob@example.com", wantRoles: []string{constant.RoleUser}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.email, func(t *testing.T) {
+			record, err := svc.repo.GetByEmail(ctx, tc.email)
+			require.NoError(t, err)
+			require.Equal(t, StatusActive, record.Status)
+			require.ElementsMatch(t, tc.wantRoles, roleNames(record.Roles))
+		})
+	}
+}
+
+// TestImportUsersInvalidFile 验证不支持的格式与无法读取表头的文件直接返回错误，而不是逐行报告。
+func TestImportUsersInvalidFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		format  string
+		file    string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "不支持的格式", format: "xlsx", file: "name,email\n", wantErr: ErrUnsupportedFormat},
+		{name: "未指定格式", file: "name,email\n", wantErr: ErrUnsupportedFormat},
+		{name: "空文件", format: FormatCSV, wantErr: ErrInvalidImportFile, wantMsg: "import file is empty"},
+		{name: "缺少 email 列", format: FormatCSV, file: "name,password\nAlice,password123\n", wantErr: ErrInvalidImportFile, wantMsg: "import file is missing the email column"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, _ := newTestService(t, config.UserConfig{})
+			report, err := svc.ImportUsers(context.Background(), strings.NewReader(tc.file), ImportOptions{Format: tc.format})
+			require.ErrorIs(t, err, tc.wantErr)
+			require.Nil(t, report)
+			if tc.wantMsg != "" {
+				require.EqualError(t, err, tc.wantMsg)
+			}
+		})
+	}
+
+	svc, _, _ := newTestService(t, config.UserConfig{})
+	require.ErrorIs(t, svc.ExportUsers(context.Background(), &bytes.Buffer{}, "xml"), ErrUnsupportedFormat)
+}
+
+// TestFormatFromPath 验证按扩展名推断文件格式。
+func TestFormatFromPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "users.csv", want: FormatCSV},
+		{path: "/tmp/USERS.CSV", want: FormatCSV},
+		{path: "users.jsonl", want: FormatJSONL},
+		{path: "users.ndjson", want: FormatJSONL},
+		{path: "users.json"},
+		{path: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			require.Equal(t, tc.want, FormatFromPath(tc.path))
+		})
+	}
+}
+
+// TestExportImportRoundTrip 验证导出文件包含导入的全部数据且不含凭据，JSONL 导出补充密码后可以重新导入得到相同的数据。
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := []ImportRecord{
+		{Name: "Alice", Email: "alice@example.com", Phone: "123", Password: "password123", Roles: []string{constant.RoleAdmin, constant.RoleUser}},
+		{Name: "Bob, Jr.", Email: "bob@example.com", Password: "password123"},
+		{Name: "Carol \"C\"", Email: "carol@example.com", Phone: "456", Password: "password123", Roles: []string{constant.RoleUser}},
+	}
+
+	var file bytes.Buffer
+	encoder := json.NewEncoder(&file)
+	for _, record := range source {
+		require.NoError(t, encoder.Encode(record))
+	}
+
+	svc, _, _ := newTestService(t, config.UserConfig{})
+	report, err := svc.ImportUsers(ctx, &file, ImportOptions{Format: FormatJSONL})
+	require.NoError(t, err)
+	require.Equal(t, len(source), report.Succeeded)
+
+	exported := exportJSONL(t, svc)
+	require.Len(t, exported, len(source))
+	for _, record := range exported {
+		require.Equal(t, StatusActive, record.Status)
+		require.NotZero(t, record.CreatedAt)
+	}
+	require.Equal(t, normalizeExport(exported), normalizeImport(source))
+
+	var csvOut bytes.Buffer
+	require.NoError(t, svc.ExportUsers(ctx, &csvOut, FormatCSV))
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, csvExportColumns, rows[0])
+	require.Len(t, rows, len(source)+1)
+	for i, row := range rows[1:] {
+		require.Equal(t, exported[i].ID.String(), row[0])
+		require.Equal(t, exported[i].Name, row[1])
+		require.Equal(t, exported[i].Email, row[2])
+		require.ElementsMatch(t, exported[i].Roles, strings.Split(row[5], csvRoleSeparator))
+	}
+	require.NotContains(t, csvOut.String(), "password")
+
+	// 导出的 JSONL 与导入使用相同的字段名，补充密码后即可导入到另一个环境。
+	var reimport bytes.Buffer
+	encoder = json.NewEncoder(&reimport)
+	for _, record := range exported {
+		line := ImportRecord{Name: record.Name, Email: record.Email, Phone: record.Phone, Roles: record.Roles, Password: "password123"}
+		require.NoError(t, encoder.Encode(line))
+	}
+
+	target, _, _ := newTestService(t, config.UserConfig{})
+	report, err = target.ImportUsers(ctx, &reimport, ImportOptions{Format: FormatJSONL})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+	require.Equal(t, normalizeExport(exported), normalizeExport(exportJSONL(t, target)))
+}
+
+// TestExportUsersEmpty 验证没有用户时 CSV 仍输出表头，JSONL 输出为空。
+func TestExportUsersEmpty(t *testing.T) {
+	svc, _, _ := newTestService(t, config.UserConfig{})
+
+	var csvOut bytes.Buffer
+	require.NoError(t, svc.ExportUsers(context.Background(), &csvOut, FormatCSV))
+	require.Equal(t, strings.Join(csvExportColumns, ",")+"\n", csvOut.String())
+
+	require.Empty(t, exportJSONL(t, svc))
+}
+
+func exportJSONL(t *testing.T, svc *Service) []ExportRecord {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, svc.ExportUsers(context.Background(), &out, FormatJSONL))
+
+	var records []ExportRecord
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record ExportRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+// roundTripRecord 是导入与导出共有、在往返过程中应保持不变的字段。
+type roundTripRecord struct {
+	Name  string
+	Email string
+	Phone string
+	Roles []string
+}
+
+func normalizeExport(records []ExportRecord) []roundTripRecord {
+	out := make([]roundTripRecord, 0, len(records))
+	for _, record := range records {
+		out = append(out, roundTripRecord{Name: record.Name, Email: record.Email, Phone: record.Phone, Roles: slices.Sorted(slices.Values(record.Roles))})
+	}
+	slices.SortFunc(out, func(a, b roundTripRecord) int { return strings.Compare(a.Email, b.Email) })
+	return out
+}
+
+func normalizeImport(records []ImportRecord) []roundTripRecord {
+	out := make([]roundTripRecord, 0, len(records))
+	for _, record := range records {
+		roles := record.Roles
+		if len(roles) == 0 {
+			roles = []string{constant.RoleUser}
+		}
+		out = append(out, roundTripRecord{Name: record.Name, Email: record.Email, Phone: record.Phone, Roles: slices.Sorted(slices.Values(roles))})
+	}
+	slices.SortFunc(out, func(a, b roundTripRecord) int { return strings.Compare(a.Email, b.Email) })
+	return out
+}
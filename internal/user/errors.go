@@ -1,19 +1,58 @@
 package user
 
-import "github.com/Jayleonc/service/pkg/xerr"
+import (
+	"embed"
+	"net/http"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
 
 // 用户模块错误码范围：2000-2999
 var (
-	ErrRegisterFailed      = xerr.New(2001, "failed to register user")
-	ErrEmailExists         = xerr.New(2002, "email already exists")
-	ErrLoginFailed         = xerr.New(2011, "failed to login user")
-	ErrInvalidCredentials  = xerr.New(2012, "invalid credentials")
-	ErrProfileLookupFailed = xerr.New(2021, "failed to load profile")
-	ErrUpdateProfileFailed = xerr.New(2022, "failed to update profile")
-	ErrRolesRequired       = xerr.New(2023, "at least one role must be assigned")
-	ErrCreateFailed        = xerr.New(2031, "failed to create user")
-	ErrUpdateUserFailed    = xerr.New(2032, "failed to update user")
-	ErrDeleteUserFailed    = xerr.New(2033, "failed to delete user")
-	ErrListUsersFailed     = xerr.New(2034, "failed to list users")
-	ErrAssignRolesFailed   = xerr.New(2035, "failed to assign roles")
+	ErrRegisterFailed          = xerr.New(2001, "failed to register user")
+	ErrEmailExists             = xerr.New(2002, "email already exists").WithStatus(http.StatusConflict)
+	ErrRegistrationClosed      = xerr.New(2003, "registration is closed").WithStatus(http.StatusForbidden)
+	ErrRegistrationInviteOnly  = xerr.New(2004, "registration requires an invitation").WithStatus(http.StatusForbidden)
+	ErrEmailDomainNotAllowed   = xerr.New(2005, "email domain is not allowed to register").WithStatus(http.StatusForbidden)
+	ErrLoginFailed             = xerr.New(2011, "failed to login user")
+	ErrInvalidCredentials      = xerr.New(2012, "invalid credentials").WithStatus(http.StatusUnauthorized)
+	ErrProfileLookupFailed     = xerr.New(2021, "failed to load profile")
+	ErrUpdateProfileFailed     = xerr.New(2022, "failed to update profile")
+	ErrRolesRequired           = xerr.New(2023, "at least one role must be assigned").WithStatus(http.StatusBadRequest)
+	ErrUserNotFound            = xerr.New(2024, "user not found").WithStatus(http.StatusNotFound)
+	ErrCreateFailed            = xerr.New(2031, "failed to create user")
+	ErrUpdateUserFailed        = xerr.New(2032, "failed to update user")
+	ErrDeleteUserFailed        = xerr.New(2033, "failed to delete user")
+	ErrListUsersFailed         = xerr.New(2034, "failed to list users")
+	ErrAssignRolesFailed       = xerr.New(2035, "failed to assign roles")
+	ErrGrantRoleFailed         = xerr.New(2036, "failed to grant role")
+	ErrRevokeRoleFailed        = xerr.New(2037, "failed to revoke role")
+	ErrListExpiringFailed      = xerr.New(2038, "failed to list expiring roles")
+	ErrPermissionsFailed       = xerr.New(2039, "failed to resolve permissions")
+	ErrRestoreUserFailed       = xerr.New(2040, "failed to restore user")
+	ErrPurgeUserFailed         = xerr.New(2041, "failed to purge user")
+	ErrVersionConflict         = xerr.New(2042, "user was modified by another request").WithStatus(http.StatusConflict)
+	ErrInvalidStatus           = xerr.New(2051, "invalid account status").WithStatus(http.StatusBadRequest)
+	ErrInvalidStatusTransition = xerr.New(2052, "account status transition not allowed").WithStatus(http.StatusConflict)
+	ErrChangeStatusFailed      = xerr.New(2053, "failed to change account status")
+	ErrInvitationUnavailable   = xerr.New(2061, "invitation is invalid, expired or already used").WithStatus(http.StatusConflict)
+	ErrCreateInvitationFailed  = xerr.New(2062, "failed to create invitation")
+	ErrResendInvitationFailed  = xerr.New(2063, "failed to resend invitation")
+	ErrRevokeInvitationFailed  = xerr.New(2064, "failed to revoke invitation")
+	ErrListInvitationsFailed   = xerr.New(2065, "failed to list invitations")
+	ErrAcceptInvitationFailed  = xerr.New(2066, "failed to accept invitation")
+	ErrInvitationNotFound      = xerr.New(2067, "invitation not found").WithStatus(http.StatusNotFound)
+	ErrUnsupportedFormat       = xerr.New(2071, "unsupported file format, expected csv or jsonl").WithStatus(http.StatusBadRequest)
+	ErrInvalidImportFile       = xerr.New(2072, "invalid import file").WithStatus(http.StatusBadRequest)
+	ErrImportFailed            = xerr.New(2073, "failed to import users")
+	ErrExportFailed            = xerr.New(2074, "failed to export users")
 )
+
+// locales 是用户模块错误码的消息目录，新增错误码时需同时补充 en.json 与 zh.json。
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	xerr.MustRegisterCatalog(locales, "locales")
+}
@@ -1,15 +1,23 @@
 package user
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Jayleonc/service/internal/rbac"
-	"github.com/Jayleonc/service/pkg/observe/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/ginx/etag"
 	"github.com/Jayleonc/service/pkg/ginx/request"
 	"github.com/Jayleonc/service/pkg/ginx/response"
 	"github.com/Jayleonc/service/pkg/xerr"
@@ -31,15 +39,32 @@ func (h *Handler) GetRoutes() feature.ModuleRoutes {
 		PublicRoutes: []feature.RouteDefinition{
 			{Path: "register", Handler: h.register},
 			{Path: "login", Handler: h.login},
+			{Path: "invitation/accept", Handler: h.acceptInvitation},
 		},
 		AuthenticatedRoutes: []feature.RouteDefinition{
 			{Path: "me/get", Handler: h.me},
 			{Path: "me/update", Handler: h.updateMe},
+			{Path: "me/permissions", Handler: h.myPermissions},
 			{Path: "create", Handler: h.create, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionCreate)},
+			{Path: "get", Handler: h.get, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionRead)},
 			{Path: "update", Handler: h.update, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionUpdate)},
 			{Path: "delete", Handler: h.delete, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionDelete)},
 			{Path: "list", Handler: h.list, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionList)},
+			// 导入为文件上传、导出包含批量用户数据、邀请响应包含明文令牌，这些路由不参与幂等处理。
+			{Path: "import", Handler: h.importUsers, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionImport), SkipIdempotency: true},
+			{Path: "export", Handler: h.exportUsers, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionExport), SkipIdempotency: true},
+			{Path: "invitation/create", Handler: h.createInvitation, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionInvite), SkipIdempotency: true},
+			{Path: "invitation/list", Handler: h.listInvitations, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionInvite)},
+			{Path: "invitation/resend", Handler: h.resendInvitation, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionInvite), SkipIdempotency: true},
+			{Path: "invitation/revoke", Handler: h.revokeInvitation, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionInvite)},
+			{Path: "status/change", Handler: h.changeStatus, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionChangeStatus)},
+			{Path: "deleted/list", Handler: h.listDeleted, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionRestore)},
+			{Path: "restore", Handler: h.restore, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionRestore)},
+			{Path: "purge", Handler: h.purge, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionPurge)},
 			{Path: "assign_roles", Handler: h.assignRoles, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionAssignRoles)},
+			{Path: "role/grant", Handler: h.grantRole, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionAssignRoles)},
+			{Path: "role/revoke", Handler: h.revokeRole, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionAssignRoles)},
+			{Path: "role/expiring", Handler: h.listExpiringRoles, RequiredPermission: rbac.PermissionKey(rbac.ResourceUser, rbac.ActionAssignRoles)},
 		},
 	}
 }
@@ -47,17 +72,13 @@ func (h *Handler) GetRoutes() feature.ModuleRoutes {
 func (h *Handler) register(c *gin.Context) {
 	var req RegisterInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	profile, err := h.svc.Register(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, ErrEmailExists) {
-			response.Error(c, http.StatusBadRequest, ErrEmailExists)
-			return
-		}
-		response.Error(c, http.StatusBadRequest, ErrRegisterFailed)
+		response.Fail(c, xerr.FromOr(err, ErrRegisterFailed))
 		return
 	}
 
@@ -67,17 +88,13 @@ func (h *Handler) register(c *gin.Context) {
 func (h *Handler) login(c *gin.Context) {
 	var req LoginInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	result, err := h.svc.Login(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, ErrInvalidCredentials) {
-			response.Error(c, http.StatusUnauthorized, ErrInvalidCredentials)
-			return
-		}
-		response.Error(c, http.StatusBadRequest, ErrLoginFailed)
+		response.Fail(c, xerr.FromOr(err, ErrLoginFailed))
 		return
 	}
 
@@ -94,27 +111,88 @@ func (h *Handler) me(c *gin.Context) {
 
 	profile, err := h.svc.Profile(c.Request.Context(), session.UserID)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, ErrProfileLookupFailed)
+		response.Fail(c, xerr.FromOr(err, ErrProfileLookupFailed))
+		return
+	}
+	if etag.NotModified(c, profileETag(profile)) {
 		return
 	}
 
 	response.Success(c, profile)
 }
 
+// myPermissions 返回当前用户的有效权限，并以版本号作为 ETag，客户端可通过 If-None-Match 复用缓存。
+func (h *Handler) myPermissions(c *gin.Context) {
+	session := feature.MustGetAuthContext(c)
+
+	permissions, err := h.svc.Permissions(c.Request.Context(), session)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrPermissionsFailed))
+		return
+	}
+
+	tag := `"` + permissions.Version + `"`
+	c.Header("ETag", tag)
+	c.Header("Cache-Control", "private, no-cache")
+	if c.GetHeader("If-None-Match") == tag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response.Success(c, permissions)
+}
+
 func (h *Handler) updateMe(c *gin.Context) {
 	session := feature.MustGetAuthContext(c)
 
 	var req UpdateProfileInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
+		return
+	}
+
+	version, fromHeader, err := etag.Expected(c, req.Version)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	req.Version = version
+
+	log.Debug(c.Request.Context(), "updateMe", "userId", session.UserID)
+
+	profile, err := h.svc.UpdateProfile(c.Request.Context(), session.UserID, req)
+	if err != nil {
+		err = etag.Conflict(err, ErrVersionConflict, fromHeader)
+		response.Fail(c, xerr.FromOr(err, ErrUpdateProfileFailed))
+		return
+	}
+
+	etag.SetTag(c, profileETag(profile))
+	response.Success(c, profile)
+}
+
+// get 管理员按 ID 查询用户，响应携带版本号 ETag，可配合 update 的 If-Match 使用。
+func (h *Handler) get(c *gin.Context) {
+	var payload struct {
+		ID string `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
 		return
 	}
 
-	logger.Info(c, "updateMe", "userId", session.UserID)
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
 
-	profile, err := h.svc.UpdateProfile(c, session.UserID, req)
+	profile, err := h.svc.Profile(c.Request.Context(), userID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, ErrUpdateProfileFailed)
+		response.Fail(c, xerr.FromOr(err, ErrProfileLookupFailed))
+		return
+	}
+	if etag.NotModified(c, profileETag(profile)) {
 		return
 	}
 
@@ -124,17 +202,13 @@ func (h *Handler) updateMe(c *gin.Context) {
 func (h *Handler) create(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	profile, err := h.svc.CreateUser(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, ErrEmailExists) {
-			response.Error(c, http.StatusBadRequest, ErrEmailExists)
-			return
-		}
-		response.Error(c, http.StatusBadRequest, ErrCreateFailed)
+		response.Fail(c, xerr.FromOr(err, ErrCreateFailed))
 		return
 	}
 
@@ -143,31 +217,41 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) update(c *gin.Context) {
 	var payload struct {
-		ID    string `json:"id" binding:"required"`
-		Name  string `json:"name"`
-		Phone string `json:"phone"`
+		ID      string `json:"id" binding:"required"`
+		Name    string `json:"name"`
+		Phone   string `json:"phone"`
+		Version int64  `json:"version"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	userID, err := uuid.Parse(payload.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	version, fromHeader, err := etag.Expected(c, payload.Version)
+	if err != nil {
+		response.Fail(c, err)
 		return
 	}
 
 	profile, err := h.svc.UpdateUser(c.Request.Context(), UpdateUserRequest{
-		ID:    userID,
-		Name:  payload.Name,
-		Phone: payload.Phone,
+		ID:      userID,
+		Name:    payload.Name,
+		Phone:   payload.Phone,
+		Version: version,
 	})
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, ErrUpdateUserFailed)
+		err = etag.Conflict(err, ErrVersionConflict, fromHeader)
+		response.Fail(c, xerr.FromOr(err, ErrUpdateUserFailed))
 		return
 	}
 
+	etag.SetTag(c, profileETag(profile))
 	response.Success(c, profile)
 }
 
@@ -176,69 +260,453 @@ func (h *Handler) delete(c *gin.Context) {
 		ID string `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	userID, err := uuid.Parse(payload.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
 		return
 	}
 
 	if err := h.svc.DeleteUser(c.Request.Context(), DeleteUserRequest{ID: userID}); err != nil {
-		response.Error(c, http.StatusBadRequest, ErrDeleteUserFailed)
+		response.Fail(c, xerr.FromOr(err, ErrDeleteUserFailed))
+		return
+	}
+
+	response.Success(c, gin.H{"id": userID})
+}
+
+func (h *Handler) restore(c *gin.Context) {
+	var payload struct {
+		ID string `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	profile, err := h.svc.RestoreUser(c.Request.Context(), RestoreUserRequest{ID: userID})
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrRestoreUserFailed))
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+func (h *Handler) purge(c *gin.Context) {
+	var payload struct {
+		ID string `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	if err := h.svc.PurgeUser(c.Request.Context(), PurgeUserRequest{ID: userID}); err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrPurgeUserFailed))
 		return
 	}
 
 	response.Success(c, gin.H{"id": userID})
 }
 
+// list 默认使用页码分页；请求体携带 cursor 对象时改用游标分页并返回 CursorPageResult。
 func (h *Handler) list(c *gin.Context) {
 	var payload struct {
-		Pagination request.Pagination `json:"pagination"`
-		Name       string             `json:"name"`
-		Email      string             `json:"email"`
+		Pagination request.Pagination        `json:"pagination"`
+		Cursor     *request.CursorPagination `json:"cursor"`
+		Filters    []request.Filter          `json:"filters"`
+		Name       string                    `json:"name"`
+		Email      string                    `json:"email"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
+		return
+	}
+
+	if payload.Cursor != nil {
+		result, err := h.svc.ListUsersByCursor(c.Request.Context(), ListUsersCursorRequest{
+			Cursor:  *payload.Cursor,
+			Filters: payload.Filters,
+			Name:    payload.Name,
+			Email:   payload.Email,
+		})
+		if err != nil {
+			response.Fail(c, xerr.FromOr(err, ErrListUsersFailed))
+			return
+		}
+		response.Success(c, result)
 		return
 	}
 
 	result, err := h.svc.ListUsers(c.Request.Context(), ListUsersRequest{
 		Pagination: payload.Pagination,
+		Filters:    payload.Filters,
 		Name:       payload.Name,
 		Email:      payload.Email,
 	})
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, ErrListUsersFailed)
+		response.Fail(c, xerr.FromOr(err, ErrListUsersFailed))
 		return
 	}
 
 	response.Success(c, result)
 }
 
+func (h *Handler) listDeleted(c *gin.Context) {
+	var payload ListUsersRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	result, err := h.svc.ListDeletedUsers(c.Request.Context(), payload)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrListUsersFailed))
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// importUsers 接收 multipart 表单：file 为导入文件，format 缺省时按文件扩展名推断，dryRun=true 时只校验不写入。
+func (h *Handler) importUsers(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("missing import file"))
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = FormatFromPath(fileHeader.Filename)
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultPostForm("dryRun", "false"))
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid dryRun flag"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Fail(c, ErrInvalidImportFile.Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	report, err := h.svc.ImportUsers(c.Request.Context(), file, ImportOptions{Format: format, DryRun: dryRun})
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrImportFailed))
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// exportUsers 以附件形式流式返回全部未删除用户，format 可选 csv（默认）或 jsonl。
+func (h *Handler) exportUsers(c *gin.Context) {
+	var payload struct {
+		Format string `json:"format"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		response.BindError(c, err)
+		return
+	}
+	if payload.Format == "" {
+		payload.Format = FormatCSV
+	}
+
+	format, err := ParseFormat(payload.Format)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// 响应头已发送，导出中途失败只能记录日志并截断输出。
+	if err := h.svc.ExportUsers(c.Request.Context(), c.Writer, format); err != nil {
+		log.Error(c.Request.Context(), "export users failed", "format", format, "error", err)
+	}
+}
+
 func (h *Handler) assignRoles(c *gin.Context) {
 	var payload struct {
 		ID    string   `json:"id" binding:"required"`
 		Roles []string `json:"roles" binding:"required,min=1,dive,required"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid request payload"))
+		response.BindError(c, err)
 		return
 	}
 
 	userID, err := uuid.Parse(payload.ID)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
 		return
 	}
 
 	profile, err := h.svc.AssignRoles(c.Request.Context(), AssignRolesRequest{ID: userID, Roles: payload.Roles})
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, ErrAssignRolesFailed)
+		response.Fail(c, xerr.FromOr(err, ErrAssignRolesFailed))
 		return
 	}
 
 	response.Success(c, profile)
 }
+
+func (h *Handler) grantRole(c *gin.Context) {
+	var payload struct {
+		ID        string     `json:"id" binding:"required"`
+		Role      string     `json:"role" binding:"required"`
+		StartsAt  *time.Time `json:"startsAt"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+		Reason    string     `json:"reason" binding:"omitempty,max=512"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	assignment, err := h.svc.GrantRole(c.Request.Context(), GrantRoleRequest{
+		ID:        userID,
+		Role:      payload.Role,
+		StartsAt:  payload.StartsAt,
+		ExpiresAt: payload.ExpiresAt,
+		Reason:    payload.Reason,
+	})
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrGrantRoleFailed))
+		return
+	}
+
+	response.Success(c, assignment)
+}
+
+func (h *Handler) revokeRole(c *gin.Context) {
+	var payload struct {
+		ID   string `json:"id" binding:"required"`
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	if err := h.svc.RevokeRole(c.Request.Context(), RevokeRoleRequest{ID: userID, Role: payload.Role}); err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrRevokeRoleFailed))
+		return
+	}
+
+	response.Success(c, gin.H{"id": userID, "role": rbac.NormalizeRoleName(payload.Role)})
+}
+
+func (h *Handler) listExpiringRoles(c *gin.Context) {
+	var payload struct {
+		Within string `json:"within"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	var within time.Duration
+	if payload.Within != "" {
+		parsed, err := time.ParseDuration(payload.Within)
+		if err != nil || parsed <= 0 {
+			response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid within duration"))
+			return
+		}
+		within = parsed
+	}
+
+	assignments, err := h.svc.ListExpiringRoles(c.Request.Context(), within)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrListExpiringFailed))
+		return
+	}
+
+	response.Success(c, assignments)
+}
+
+func (h *Handler) changeStatus(c *gin.Context) {
+	var payload struct {
+		ID     string `json:"id" binding:"required"`
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason" binding:"omitempty,max=512"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid user id"))
+		return
+	}
+
+	profile, err := h.svc.ChangeStatus(c.Request.Context(), ChangeStatusRequest{
+		ID:     userID,
+		Status: Status(payload.Status),
+		Reason: payload.Reason,
+	})
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrChangeStatusFailed))
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+func (h *Handler) createInvitation(c *gin.Context) {
+	var payload struct {
+		Email string   `json:"email" binding:"required,email"`
+		Roles []string `json:"roles" binding:"omitempty,dive,required"`
+		TTL   string   `json:"ttl"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	var ttl time.Duration
+	if payload.TTL != "" {
+		parsed, err := time.ParseDuration(payload.TTL)
+		if err != nil || parsed <= 0 {
+			response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid ttl duration"))
+			return
+		}
+		ttl = parsed
+	}
+
+	invitation, err := h.svc.CreateInvitation(c.Request.Context(), CreateInvitationRequest{
+		Email: payload.Email,
+		Roles: payload.Roles,
+		TTL:   ttl,
+	})
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrCreateInvitationFailed))
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, invitation)
+}
+
+func (h *Handler) listInvitations(c *gin.Context) {
+	var payload ListInvitationsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	result, err := h.svc.ListInvitations(c.Request.Context(), payload)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrListInvitationsFailed))
+		return
+	}
+
+	response.Success(c, result)
+}
+
+func (h *Handler) resendInvitation(c *gin.Context) {
+	invitationID, ok := bindInvitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.svc.ResendInvitation(c.Request.Context(), invitationID)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrResendInvitationFailed))
+		return
+	}
+
+	response.Success(c, invitation)
+}
+
+func (h *Handler) revokeInvitation(c *gin.Context) {
+	invitationID, ok := bindInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(c.Request.Context(), invitationID); err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrRevokeInvitationFailed))
+		return
+	}
+
+	response.Success(c, gin.H{"id": invitationID})
+}
+
+func (h *Handler) acceptInvitation(c *gin.Context) {
+	var req AcceptInvitationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	profile, err := h.svc.AcceptInvitation(c.Request.Context(), req)
+	if err != nil {
+		response.Fail(c, xerr.FromOr(err, ErrAcceptInvitationFailed))
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, profile)
+}
+
+// bindInvitationID 解析请求体中的邀请 ID，失败时直接写入错误响应。
+func bindInvitationID(c *gin.Context) (uuid.UUID, bool) {
+	var payload struct {
+		ID string `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BindError(c, err)
+		return uuid.Nil, false
+	}
+
+	invitationID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		response.Fail(c, xerr.ErrBadRequest.WithMessage("invalid invitation id"))
+		return uuid.Nil, false
+	}
+	return invitationID, true
+}
+
+// profileETag 由版本号与角色集合的摘要组成：角色的授予、撤销、过期与生效都不修改用户记录的版本号，
+// 只用版本号会让客户端在角色变化后仍得到 304 并继续使用过期的角色。
+func profileETag(profile Profile) string {
+	roles := slices.Clone(profile.Roles)
+	slices.Sort(roles)
+	sum := sha256.Sum256([]byte(strings.Join(roles, "\n")))
+	return etag.Tag(profile.Version, hex.EncodeToString(sum[:8]))
+}
@@ -0,0 +1,165 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// newVersionRouter 挂载与版本号相关的接口，请求以 subject 的身份访问。
+func newVersionRouter(h *Handler, subject uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		feature.SetAuthContext(c, feature.AuthContext{UserID: subject})
+		c.Next()
+	})
+	router.POST("/user/get", h.get)
+	router.POST("/user/update", h.update)
+	router.POST("/user/me/update", h.updateMe)
+	return router
+}
+
+func serveJSON(router *gin.Engine, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestHandlerUpdateVersion 验证更新接口的乐观并发控制：If-Match 过期返回 412，请求体版本过期返回 409，成功更新后版本号加一并写入 ETag。
+func TestHandlerUpdateVersion(t *testing.T) {
+	cases := []struct {
+		name       string
+		path       string
+		body       string
+		ifMatch    string
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "未提供版本", path: "/user/update", body: `{"id": "%s", "name": "changed"}`, wantStatus: http.StatusOK},
+		{name: "请求体版本一致", path: "/user/update", body: `{"id": "%s", "name": "changed", "version": 2}`, wantStatus: http.StatusOK},
+		{name: "If-Match 版本一致", path: "/user/update", body: `{"id": "%s", "name": "changed"}`, ifMatch: `"2"`, wantStatus: http.StatusOK},
+		{name: "If-Match 优先于请求体", path: "/user/update", body: `{"id": "%s", "name": "changed", "version": 1}`, ifMatch: `W/"2"`, wantStatus: http.StatusOK},
+		{name: "If-Match 为通配符", path: "/user/update", body: `{"id": "%s", "name": "changed"}`, ifMatch: "*", wantStatus: http.StatusOK},
+		{name: "请求体版本过期", path: "/user/update", body: `{"id": "%s", "name": "changed", "version": 1}`, wantStatus: http.StatusConflict, wantCode: ErrVersionConflict.Code},
+		{name: "If-Match 版本过期", path: "/user/update", body: `{"id": "%s", "name": "changed"}`, ifMatch: `"1"`, wantStatus: http.StatusPreconditionFailed, wantCode: ErrVersionConflict.Code},
+		{name: "If-Match 无法解析", path: "/user/update", body: `{"id": "%s", "name": "changed"}`, ifMatch: "2", wantStatus: http.StatusBadRequest, wantCode: xerr.ErrBadRequest.Code},
+		{name: "更新本人资料", path: "/user/me/update", body: `{"name": "changed", "version": 2}`, wantStatus: http.StatusOK},
+		{name: "更新本人资料时请求体版本过期", path: "/user/me/update", body: `{"name": "changed", "version": 1}`, wantStatus: http.StatusConflict, wantCode: ErrVersionConflict.Code},
+		{name: "更新本人资料时 If-Match 版本过期", path: "/user/me/update", body: `{"name": "changed"}`, ifMatch: `"1"`, wantStatus: http.StatusPreconditionFailed, wantCode: ErrVersionConflict.Code},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, db := newTestService(t, config.UserConfig{})
+			record := createTestUser(t, db, "version@example.com", StatusActive)
+			// 先由其他请求更新一次，使当前版本为 2。
+			_, err := svc.UpdateUser(t.Context(), UpdateUserRequest{ID: record.ID, Phone: "123"})
+			require.NoError(t, err)
+
+			router := newVersionRouter(NewHandler(svc), record.ID)
+			body := strings.Replace(tc.body, "%s", record.ID.String(), 1)
+			headers := map[string]string{}
+			if tc.ifMatch != "" {
+				headers["If-Match"] = tc.ifMatch
+			}
+			w := serveJSON(router, tc.path, body, headers)
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+
+			var resp struct {
+				Code int      `json:"code"`
+				Data *Profile `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			var stored User
+			require.NoError(t, db.First(&stored, "id = ?", record.ID).Error)
+
+			if tc.wantStatus != http.StatusOK {
+				require.Equal(t, tc.wantCode, resp.Code)
+				require.Empty(t, w.Header().Get("ETag"))
+				require.Equal(t, "tester", stored.Name)
+				require.Equal(t, int64(2), stored.Version)
+				return
+			}
+			require.NotNil(t, resp.Data)
+			require.Equal(t, "changed", resp.Data.Name)
+			require.Equal(t, int64(3), resp.Data.Version)
+			require.Equal(t, profileETag(*resp.Data), w.Header().Get("ETag"))
+			require.Equal(t, "changed", stored.Name)
+			require.Equal(t, int64(3), stored.Version)
+		})
+	}
+}
+
+// TestHandlerGetETag 验证查询接口返回由版本号与角色摘要组成的 ETag，If-None-Match 命中时返回 304，
+// 角色变化后旧的 ETag 不再命中。
+func TestHandlerGetETag(t *testing.T) {
+	svc, _, db := newTestService(t, config.UserConfig{})
+	record := createTestUser(t, db, "etag@example.com", StatusActive)
+	router := newVersionRouter(NewHandler(svc), record.ID)
+	body := `{"id": "` + record.ID.String() + `"}`
+
+	initial := serveJSON(router, "/user/get", body, nil)
+	require.Equal(t, http.StatusOK, initial.Code)
+	tag := initial.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(tag, `"1-`), tag)
+
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "ETag 一致", ifNoneMatch: tag, wantStatus: http.StatusNotModified},
+		{name: "弱 ETag 同样匹配", ifNoneMatch: `"7", W/` + tag, wantStatus: http.StatusNotModified},
+		{name: "只有版本号不匹配", ifNoneMatch: `"1"`, wantStatus: http.StatusOK},
+		{name: "ETag 不一致", ifNoneMatch: `"2-0000000000000000"`, wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serveJSON(router, "/user/get", body, map[string]string{"If-None-Match": tc.ifNoneMatch})
+			require.Equal(t, tc.wantStatus, w.Code)
+			require.Equal(t, tag, w.Header().Get("ETag"))
+			if tc.wantStatus == http.StatusNotModified {
+				require.Empty(t, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("授予角色后旧 ETag 失效", func(t *testing.T) {
+		_, err := svc.GrantRole(t.Context(), GrantRoleRequest{ID: record.ID, Role: "ADMIN"})
+		require.NoError(t, err)
+
+		w := serveJSON(router, "/user/get", body, map[string]string{"If-None-Match": tag})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotEqual(t, tag, w.Header().Get("ETag"))
+		require.True(t, strings.HasPrefix(w.Header().Get("ETag"), `"1-`), "roles do not change the row version")
+
+		var resp struct {
+			Data Profile `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, []string{"ADMIN"}, resp.Data.Roles)
+
+		// 新的 ETag 同样可以作为 If-Match 使用。
+		update := serveJSON(router, "/user/update", `{"id": "`+record.ID.String()+`", "name": "changed"}`, map[string]string{"If-Match": w.Header().Get("ETag")})
+		require.Equal(t, http.StatusOK, update.Code, update.Body.String())
+	})
+}
@@ -0,0 +1,437 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/ginx/filter"
+	"github.com/Jayleonc/service/pkg/ginx/paginator"
+	"github.com/Jayleonc/service/pkg/ginx/request"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+)
+
+// 自助注册方式，由配置项 user.registration_mode 指定。
+const (
+	RegistrationOpen            = "open"
+	RegistrationInviteOnly      = "invite_only"
+	RegistrationClosed          = "closed"
+	RegistrationDomainAllowlist = "domain_allowlist"
+)
+
+// InvitationState 描述邀请当前所处的阶段，由时间戳字段推导得出。
+type InvitationState string
+
+// 邀请状态。
+const (
+	InvitationPending  InvitationState = "pending"
+	InvitationAccepted InvitationState = "accepted"
+	InvitationRevoked  InvitationState = "revoked"
+	InvitationExpired  InvitationState = "expired"
+)
+
+// Invitation 邀请实体。令牌只保存 SHA-256 摘要，明文仅在创建或重发时返回一次。
+type Invitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Email      string     `gorm:"size:255;index"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex"`
+	Roles      []string   `gorm:"serializer:json"`
+	InvitedBy  *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt  time.Time  `gorm:"index"`
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	UserID     *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName 指定邀请表名。
+func (Invitation) TableName() string {
+	return "user_invitation"
+}
+
+// StateAt 返回邀请在 t 时刻的状态。
+func (i Invitation) StateAt(t time.Time) InvitationState {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !i.ExpiresAt.After(t):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// InvitationView 是返回给管理员的邀请信息，Token 仅在创建或重发时出现。
+type InvitationView struct {
+	ID         uuid.UUID       `json:"id"`
+	Email      string          `json:"email"`
+	Roles      []string        `json:"roles"`
+	State      InvitationState `json:"state"`
+	InvitedBy  *uuid.UUID      `json:"invitedBy,omitempty"`
+	ExpiresAt  time.Time       `json:"expiresAt"`
+	AcceptedAt *time.Time      `json:"acceptedAt,omitempty"`
+	RevokedAt  *time.Time      `json:"revokedAt,omitempty"`
+	UserID     *uuid.UUID      `json:"userId,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Token      string          `json:"token,omitempty"`
+}
+
+func toInvitationView(inv Invitation, now time.Time) InvitationView {
+	return InvitationView{
+		ID:         inv.ID,
+		Email:      inv.Email,
+		Roles:      inv.Roles,
+		State:      inv.StateAt(now),
+		InvitedBy:  inv.InvitedBy,
+		ExpiresAt:  inv.ExpiresAt,
+		AcceptedAt: inv.AcceptedAt,
+		RevokedAt:  inv.RevokedAt,
+		UserID:     inv.UserID,
+		CreatedAt:  inv.CreatedAt,
+	}
+}
+
+// CreateInvitationRequest 管理员创建邀请
+type CreateInvitationRequest struct {
+	Email string        `json:"email" validate:"required,email"`
+	Roles []string      `json:"roles" validate:"omitempty,dive,required"`
+	TTL   time.Duration `json:"ttl" validate:"omitempty"`
+}
+
+// AcceptInvitationInput 受邀者接受邀请并设置密码
+type AcceptInvitationInput struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+	Phone    string `json:"phone" validate:"omitempty"`
+}
+
+// ListInvitationsRequest 邀请分页请求，Email 为兼容旧接口的模糊匹配条件。
+type ListInvitationsRequest struct {
+	Pagination request.Pagination `json:"pagination"`
+	Filters    []request.Filter   `json:"filters"`
+	Email      string             `json:"email"`
+}
+
+// invitationListSchema 声明邀请列表可过滤、可排序的字段。
+var invitationListSchema = filter.Schema{
+	"email":      {Column: "email", Type: filter.String, Ops: []filter.Op{filter.OpEq, filter.OpIn, filter.OpLike}, Sortable: true},
+	"invitedBy":  {Column: "invited_by", Type: filter.UUID, Ops: []filter.Op{filter.OpEq, filter.OpIsNull}},
+	"expiresAt":  {Column: "expires_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange}, Sortable: true},
+	"acceptedAt": {Column: "accepted_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange, filter.OpIsNull}, Sortable: true},
+	"revokedAt":  {Column: "revoked_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange, filter.OpIsNull}, Sortable: true},
+	"createdAt":  {Column: "created_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange}, Sortable: true},
+}
+
+// CreateInvitation 持久化新邀请。
+func (r *Repository) CreateInvitation(ctx context.Context, inv *Invitation) error {
+	return r.db.WithContext(ctx).Create(inv).Error
+}
+
+// GetInvitation 根据 ID 查询邀请。
+func (r *Repository) GetInvitation(ctx context.Context, id uuid.UUID) (*Invitation, error) {
+	var inv Invitation
+	if err := r.db.WithContext(ctx).First(&inv, "id = ?", id).Error; err != nil {
+		return nil, notFound(err, ErrInvitationNotFound)
+	}
+	return &inv, nil
+}
+
+// GetInvitationByTokenHash 根据令牌摘要查询邀请。
+func (r *Repository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	var inv Invitation
+	if err := r.db.WithContext(ctx).First(&inv, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// InvitationQuery 返回用于邀请列表查询的基础链式查询对象。
+func (r *Repository) InvitationQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&Invitation{})
+}
+
+// UpdatePendingInvitation 仅在邀请尚未被接受或撤销时更新指定字段。
+func (r *Repository) UpdatePendingInvitation(ctx context.Context, id uuid.UUID, values map[string]any) error {
+	result := r.db.WithContext(ctx).
+		Model(&Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationUnavailable
+	}
+	return nil
+}
+
+// AcceptInvitation 在同一事务中消费邀请、创建用户并分配预设角色，保证令牌只能使用一次。
+func (r *Repository) AcceptInvitation(ctx context.Context, inv *Invitation, user *User, roles []*rbac.Role, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", inv.ID, now).
+			Updates(map[string]any{"accepted_at": now, "user_id": user.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationUnavailable
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Model(user).Association("Roles").Replace(roles)
+	})
+}
+
+// CreateInvitation 创建邀请并返回一次性令牌；同一邮箱已有未删除用户时返回 ErrEmailExists。
+func (s *Service) CreateInvitation(ctx context.Context, req CreateInvitationRequest) (InvitationView, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := s.repo.GetByEmail(ctx, email); err == nil {
+		return InvitationView{}, ErrEmailExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return InvitationView{}, err
+	}
+
+	requested := req.Roles
+	if len(requested) == 0 {
+		requested = []string{constant.RoleUser}
+	}
+	roles, err := s.rolesByNames(ctx, requested)
+	if err != nil {
+		return InvitationView{}, err
+	}
+	if len(roles) == 0 {
+		return InvitationView{}, ErrRolesRequired
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return InvitationView{}, err
+	}
+
+	now := time.Now()
+	inv := &Invitation{
+		ID:        uuid.Must(uuid.NewV7()),
+		Email:     email,
+		TokenHash: tokenHash,
+		Roles:     roleNames(roles),
+		ExpiresAt: now.Add(s.inviteTTL(req.TTL)),
+	}
+	if subject, ok := feature.AuthContextFromContext(ctx); ok {
+		inviter := subject.UserID
+		inv.InvitedBy = &inviter
+	}
+
+	if err := s.repo.CreateInvitation(ctx, inv); err != nil {
+		return InvitationView{}, err
+	}
+	log.Info(ctx, "invitation created", "invitationId", inv.ID.String(), "email", inv.Email)
+
+	view := toInvitationView(*inv, now)
+	view.Token = token
+	return view, nil
+}
+
+// ResendInvitation 为待接受或已过期的邀请重新生成令牌并顺延有效期，旧令牌立即失效。
+func (s *Service) ResendInvitation(ctx context.Context, id uuid.UUID) (InvitationView, error) {
+	inv, err := s.repo.GetInvitation(ctx, id)
+	if err != nil {
+		return InvitationView{}, err
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return InvitationView{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.inviteTTL(0))
+	if err := s.repo.UpdatePendingInvitation(ctx, id, map[string]any{
+		"token_hash": tokenHash,
+		"expires_at": expiresAt,
+	}); err != nil {
+		return InvitationView{}, err
+	}
+	log.Info(ctx, "invitation resent", "invitationId", id.String(), "email", inv.Email)
+
+	inv.TokenHash = tokenHash
+	inv.ExpiresAt = expiresAt
+	view := toInvitationView(*inv, now)
+	view.Token = token
+	return view, nil
+}
+
+// RevokeInvitation 撤销尚未被接受的邀请。
+func (s *Service) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.repo.GetInvitation(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.UpdatePendingInvitation(ctx, id, map[string]any{"revoked_at": time.Now()})
+}
+
+// ListInvitations 分页返回邀请列表。
+func (s *Service) ListInvitations(ctx context.Context, req ListInvitationsRequest) (*response.PageResult[InvitationView], error) {
+	scope, err := invitationListSchema.Scope(withLegacyFilters(req.Filters, "", strings.ToLower(req.Email)))
+	if err != nil {
+		return nil, err
+	}
+	pagination := req.Pagination
+	if pagination.OrderBy, err = invitationListSchema.OrderBy(pagination.OrderBy); err != nil {
+		return nil, err
+	}
+
+	pageResult, err := paginator.Paginate[Invitation](s.repo.InvitationQuery(ctx).Scopes(scope), &pagination, invitationListSchema.SortColumns()...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]InvitationView, 0, len(pageResult.List))
+	for _, inv := range pageResult.List {
+		views = append(views, toInvitationView(inv, now))
+	}
+
+	return &response.PageResult[InvitationView]{
+		List:     views,
+		Total:    pageResult.Total,
+		Page:     pageResult.Page,
+		PageSize: pageResult.PageSize,
+	}, nil
+}
+
+// AcceptInvitation 使用邀请令牌创建账户并设置密码，成功后令牌失效。
+func (s *Service) AcceptInvitation(ctx context.Context, input AcceptInvitationInput) (_ Profile, err error) {
+	defer func() { registrationsTotal.WithLabelValues(registrationInvitation, registrationOutcome(err)).Inc() }()
+
+	inv, err := s.repo.GetInvitationByTokenHash(ctx, hashInvitationToken(input.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Profile{}, ErrInvitationUnavailable
+		}
+		return Profile{}, err
+	}
+
+	now := time.Now()
+	if inv.StateAt(now) != InvitationPending {
+		return Profile{}, ErrInvitationUnavailable
+	}
+
+	roles, err := s.rolesByNames(ctx, inv.Roles)
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(roles) == 0 {
+		return Profile{}, ErrRolesRequired
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	user := &User{
+		ID:           uuid.Must(uuid.NewV7()),
+		Name:         input.Name,
+		Email:        inv.Email,
+		PasswordHash: string(passwordHash),
+		Phone:        input.Phone,
+		Status:       StatusActive,
+	}
+
+	if err := s.repo.AcceptInvitation(ctx, inv, user, roles, now); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return Profile{}, ErrEmailExists
+		}
+		return Profile{}, err
+	}
+	user.Roles = roles
+	log.Info(ctx, "invitation accepted", "invitationId", inv.ID.String(), "userId", user.ID.String())
+
+	return toProfile(*user), nil
+}
+
+// normalizeRegistrationMode 统一注册方式的大小写与空白，未配置时视为 open。
+func normalizeRegistrationMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		return RegistrationOpen
+	}
+	return mode
+}
+
+// ValidateRegistrationMode 校验 user.registration_mode 是否为已知取值，启动时调用，避免拼写错误被静默当作关闭注册。
+func ValidateRegistrationMode(mode string) error {
+	switch normalizeRegistrationMode(mode) {
+	case RegistrationOpen, RegistrationInviteOnly, RegistrationClosed, RegistrationDomainAllowlist:
+		return nil
+	default:
+		return fmt.Errorf("unknown user.registration_mode %q: must be one of %s, %s, %s, %s",
+			mode, RegistrationOpen, RegistrationInviteOnly, RegistrationClosed, RegistrationDomainAllowlist)
+	}
+}
+
+// checkRegistrationAllowed 按配置的注册方式校验自助注册请求。未知取值已在启动时拒绝，此处仍按关闭处理。
+func (s *Service) checkRegistrationAllowed(email string) error {
+	switch normalizeRegistrationMode(s.settings.RegistrationMode) {
+	case RegistrationOpen:
+		return nil
+	case RegistrationInviteOnly:
+		return ErrRegistrationInviteOnly
+	case RegistrationDomainAllowlist:
+		domain := email[strings.LastIndex(email, "@")+1:]
+		for _, allowed := range s.settings.AllowedEmailDomains {
+			if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(allowed), "@"), domain) {
+				return nil
+			}
+		}
+		return ErrEmailDomainNotAllowed
+	default:
+		return ErrRegistrationClosed
+	}
+}
+
+func (s *Service) inviteTTL(requested time.Duration) time.Duration {
+	if requested > 0 {
+		return requested
+	}
+	if s.settings.InviteTTL > 0 {
+		return s.settings.InviteTTL
+	}
+	return defaultInviteTTL
+}
+
+// defaultInviteTTL 是未配置 user.invite_ttl 时邀请的有效期。
+const defaultInviteTTL = 72 * time.Hour
+
+func newInvitationToken() (token, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/feature"
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// acceptInput 返回使用 token 接受邀请的入参。
+func acceptInput(token string) AcceptInvitationInput {
+	return AcceptInvitationInput{Token: token, Name: "invitee", Password: "password123"}
+}
+
+// expireInvitation 将邀请的有效期改到过去。
+func expireInvitation(t *testing.T, db *gorm.DB, id uuid.UUID) {
+	t.Helper()
+	require.NoError(t, db.Model(&Invitation{}).Where("id = ?", id).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+}
+
+// TestInvitationLifecycle 验证邀请从创建到接受的完整流程：令牌只保存摘要，接受后创建账户并分配预设角色，令牌只能使用一次。
+func TestInvitationLifecycle(t *testing.T) {
+	svc, _, db := newTestService(t, config.UserConfig{InviteTTL: 24 * time.Hour})
+	inviter := uuid.New()
+	ctx := feature.ContextWithAuthContext(context.Background(), feature.AuthContext{UserID: inviter})
+
+	before := time.Now()
+	view, err := svc.CreateInvitation(ctx, CreateInvitationRequest{Email: " Invitee@Example.com ", Roles: []string{constant.RoleAdmin}})
+	require.NoError(t, err)
+	require.Equal(t, "invitee@example.com", view.Email)
+	require.Equal(t, []string{constant.RoleAdmin}, view.Roles)
+	require.Equal(t, InvitationPending, view.State)
+	require.Equal(t, &inviter, view.InvitedBy)
+	require.NotEmpty(t, view.Token)
+	require.WithinDuration(t, before.Add(24*time.Hour), view.ExpiresAt, time.Minute)
+
+	stored, err := svc.repo.GetInvitation(ctx, view.ID)
+	require.NoError(t, err)
+	require.Equal(t, hashInvitationToken(view.Token), stored.TokenHash)
+
+	profile, err := svc.AcceptInvitation(ctx, acceptInput(view.Token))
+	require.NoError(t, err)
+	require.Equal(t, "invitee@example.com", profile.Email)
+	require.Equal(t, []string{constant.RoleAdmin}, profile.Roles)
+	require.Equal(t, StatusActive, profile.Status)
+
+	created, err := svc.repo.Get(ctx, profile.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{constant.RoleAdmin}, roleNames(created.Roles))
+
+	accepted, err := svc.repo.GetInvitation(ctx, view.ID)
+	require.NoError(t, err)
+	require.Equal(t, InvitationAccepted, accepted.StateAt(time.Now()))
+	require.Equal(t, &profile.ID, accepted.UserID)
+
+	_, err = svc.AcceptInvitation(ctx, acceptInput(view.Token))
+	require.ErrorIs(t, err, ErrInvitationUnavailable, "令牌只能使用一次")
+
+	var count int64
+	require.NoError(t, db.Model(&User{}).Where("email = ?", "invitee@example.com").Count(&count).Error)
+	require.Equal(t, int64(1), count)
+}
+
+// TestCreateInvitation 验证默认角色与有效期，以及邮箱已注册、角色不存在时拒绝创建。
+func TestCreateInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name      string
+		settings  config.UserConfig
+		req       CreateInvitationRequest
+		wantRoles []string
+		wantTTL   time.Duration
+		wantErr   error
+	}{
+		{name: "默认角色与有效期", req: CreateInvitationRequest{Email: "new@example.com"}, wantRoles: []string{constant.RoleUser}, wantTTL: defaultInviteTTL},
+		{name: "使用配置的有效期", settings: config.UserConfig{InviteTTL: time.Hour}, req: CreateInvitationRequest{Email: "new@example.com"}, wantRoles: []string{constant.RoleUser}, wantTTL: time.Hour},
+		{name: "请求指定的有效期优先", settings: config.UserConfig{InviteTTL: time.Hour}, req: CreateInvitationRequest{Email: "new@example.com", TTL: 10 * time.Minute}, wantRoles: []string{constant.RoleUser}, wantTTL: 10 * time.Minute},
+		{name: "邮箱已注册", req: CreateInvitationRequest{Email: "Existing@example.com"}, wantErr: ErrEmailExists},
+		{name: "角色不存在", req: CreateInvitationRequest{Email: "new@example.com", Roles: []string{"UNKNOWN"}}, wantErr: ErrRolesRequired},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, db := newTestService(t, tc.settings)
+			createTestUser(t, db, "existing@example.com", StatusActive)
+
+			before := time.Now()
+			view, err := svc.CreateInvitation(ctx, tc.req)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRoles, view.Roles)
+			require.Nil(t, view.InvitedBy)
+			require.WithinDuration(t, before.Add(tc.wantTTL), view.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+// TestAcceptInvitationUnavailable 验证已过期、已撤销、已使用、已被重发替换或不存在的令牌都返回 ErrInvitationUnavailable，且不会创建账户。
+func TestAcceptInvitationUnavailable(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		prepare func(t *testing.T, svc *Service, db *gorm.DB, view InvitationView) string
+	}{
+		{
+			name: "已过期",
+			prepare: func(t *testing.T, _ *Service, db *gorm.DB, view InvitationView) string {
+				expireInvitation(t, db, view.ID)
+				return view.Token
+			},
+		},
+		{
+			name: "已撤销",
+			prepare: func(t *testing.T, svc *Service, _ *gorm.DB, view InvitationView) string {
+				require.NoError(t, svc.RevokeInvitation(ctx, view.ID))
+				return view.Token
+			},
+		},
+		{
+			name: "已使用",
+			prepare: func(t *testing.T, svc *Service, db *gorm.DB, view InvitationView) string {
+				profile, err := svc.AcceptInvitation(ctx, acceptInput(view.Token))
+				require.NoError(t, err)
+				// 删除已创建的账户，确保失败来自邀请本身而不是邮箱冲突。
+				require.NoError(t, db.Unscoped().Delete(&User{}, "id = ?", profile.ID).Error)
+				return view.Token
+			},
+		},
+		{
+			name: "重发后的旧令牌",
+			prepare: func(t *testing.T, svc *Service, _ *gorm.DB, view InvitationView) string {
+				_, err := svc.ResendInvitation(ctx, view.ID)
+				require.NoError(t, err)
+				return view.Token
+			},
+		},
+		{
+			name: "令牌不存在",
+			prepare: func(*testing.T, *Service, *gorm.DB, InvitationView) string {
+				return "unknown-token"
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, db := newTestService(t, config.UserConfig{})
+			view, err := svc.CreateInvitation(ctx, CreateInvitationRequest{Email: "invitee@example.com"})
+			require.NoError(t, err)
+
+			token := tc.prepare(t, svc, db, view)
+			_, err = svc.AcceptInvitation(ctx, acceptInput(token))
+			require.ErrorIs(t, err, ErrInvitationUnavailable)
+
+			var count int64
+			require.NoError(t, db.Model(&User{}).Where("email = ?", "invitee@example.com").Count(&count).Error)
+			require.Zero(t, count)
+		})
+	}
+}
+
+// TestResendInvitation 验证重发会生成新令牌并顺延有效期，已过期的邀请重发后可以再次接受。
+func TestResendInvitation(t *testing.T) {
+	ctx := context.Background()
+	svc, _, db := newTestService(t, config.UserConfig{InviteTTL: time.Hour})
+
+	view, err := svc.CreateInvitation(ctx, CreateInvitationRequest{Email: "invitee@example.com"})
+	require.NoError(t, err)
+	expireInvitation(t, db, view.ID)
+
+	stored, err := svc.repo.GetInvitation(ctx, view.ID)
+	require.NoError(t, err)
+	require.Equal(t, InvitationExpired, stored.StateAt(time.Now()))
+
+	before := time.Now()
+	resent, err := svc.ResendInvitation(ctx, view.ID)
+	require.NoError(t, err)
+	require.Equal(t, view.ID, resent.ID)
+	require.Equal(t, InvitationPending, resent.State)
+	require.NotEmpty(t, resent.Token)
+	require.NotEqual(t, view.Token, resent.Token)
+	require.WithinDuration(t, before.Add(time.Hour), resent.ExpiresAt, time.Minute)
+
+	_, err = svc.AcceptInvitation(ctx, acceptInput(view.Token))
+	require.ErrorIs(t, err, ErrInvitationUnavailable)
+
+	_, err = svc.AcceptInvitation(ctx, acceptInput(resent.Token))
+	require.NoError(t, err)
+}
+
+// TestInvitationTerminalStates 验证已接受或已撤销的邀请不能再重发或撤销，不存在的邀请返回 ErrInvitationNotFound。
+func TestInvitationTerminalStates(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t, config.UserConfig{})
+
+	revoked, err := svc.CreateInvitation(ctx, CreateInvitationRequest{Email: "revoked@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.RevokeInvitation(ctx, revoked.ID))
+
+	accepted, err := svc.CreateInvitation(ctx, CreateInvitationRequest{Email: "accepted@example.com"})
+	require.NoError(t, err)
+	_, err = svc.AcceptInvitation(ctx, acceptInput(accepted.Token))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		id      uuid.UUID
+		wantErr error
+	}{
+		{name: "已撤销", id: revoked.ID, wantErr: ErrInvitationUnavailable},
+		{name: "已接受", id: accepted.ID, wantErr: ErrInvitationUnavailable},
+		{name: "不存在", id: uuid.New(), wantErr: ErrInvitationNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.ResendInvitation(ctx, tc.id)
+			require.ErrorIs(t, err, tc.wantErr)
+			require.ErrorIs(t, svc.RevokeInvitation(ctx, tc.id), tc.wantErr)
+		})
+	}
+
+	stored, err := svc.repo.GetInvitation(ctx, revoked.ID)
+	require.NoError(t, err)
+	require.Equal(t, InvitationRevoked, stored.StateAt(time.Now()))
+}
+
+// TestValidateRegistrationMode 验证只接受已知的注册方式，拼写错误在启动时报错而不是被当作关闭注册。
+func TestValidateRegistrationMode(t *testing.T) {
+	cases := []struct {
+		name    string
+		mode    string
+		wantErr bool
+	}{
+		{name: "未配置", mode: ""},
+		{name: "开放注册", mode: RegistrationOpen},
+		{name: "仅邀请", mode: RegistrationInviteOnly},
+		{name: "关闭注册", mode: RegistrationClosed},
+		{name: "域名白名单忽略大小写与空白", mode: " Domain_Allowlist "},
+		{name: "拼写错误", mode: "invite-only", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRegistrationMode(tc.mode)
+			if tc.wantErr {
+				require.ErrorContains(t, err, tc.mode)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Jayleonc/service/internal/auth"
+	"github.com/Jayleonc/service/pkg/observe/metrics"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// 注册来源。
+const (
+	registrationSelf       = "self"
+	registrationInvitation = "invitation"
+)
+
+var (
+	loginsTotal = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_logins_total",
+			Help: "Login attempts by outcome.",
+		},
+		[]string{"outcome"},
+	))
+	registrationsTotal = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_registrations_total",
+			Help: "Account registrations by source (self, invitation) and outcome.",
+		},
+		[]string{"source", "outcome"},
+	))
+)
+
+// loginOutcome 将登录结果归类为 success、invalid_credentials、account_inactive 或 error。
+func loginOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrInvalidCredentials):
+		return "invalid_credentials"
+	case auth.IsAccountStatusError(err):
+		return "account_inactive"
+	default:
+		return "error"
+	}
+}
+
+// registrationOutcome 将注册结果归类为 success、email_exists、not_allowed、rejected（其他客户端错误）或 error。
+func registrationOutcome(err error) string {
+	var xe *xerr.Error
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrEmailExists):
+		return "email_exists"
+	case errors.Is(err, ErrRegistrationClosed), errors.Is(err, ErrRegistrationInviteOnly), errors.Is(err, ErrEmailDomainNotAllowed):
+		return "not_allowed"
+	case errors.As(err, &xe) && xe.HTTPStatus() < 500:
+		return "rejected"
+	default:
+		return "error"
+	}
+}
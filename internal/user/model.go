@@ -1,20 +1,26 @@
 package user
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 
 	"github.com/Jayleonc/service/internal/rbac"
 	"github.com/Jayleonc/service/pkg/model"
 )
 
-// User 用户实体
+// User 用户实体。Email 的唯一性仅约束未删除的用户，见 Repository.Migrate。
 type User struct {
-	ID           uuid.UUID    `gorm:"type:uuid;primaryKey"`
-	Name         string       `gorm:"size:255"`
-	Email        string       `gorm:"size:255;uniqueIndex"`
-	PasswordHash string       `gorm:"column:password_hash"`
-	Phone        string       `gorm:"size:64"`
-	Roles        []*rbac.Role `gorm:"many2many:user_role;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ID              uuid.UUID    `gorm:"type:uuid;primaryKey"`
+	Name            string       `gorm:"size:255"`
+	Email           string       `gorm:"size:255;index:idx_user_email_lookup"`
+	PasswordHash    string       `gorm:"column:password_hash"`
+	Phone           string       `gorm:"size:64"`
+	Status          Status       `gorm:"size:32;not null;default:active;index"`
+	StatusReason    string       `gorm:"size:512"`
+	StatusChangedAt *time.Time   `gorm:"column:status_changed_at"`
+	Roles           []*rbac.Role `gorm:"many2many:user_role;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	model.Versioned
 	model.Base
 }
 
@@ -3,18 +3,30 @@ package user
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Jayleonc/service/internal/auth"
 	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/observe/logger"
 )
 
+// roleExpiryInterval 控制过期角色分配的清理频率。
+const roleExpiryInterval = time.Minute
+
+// log 是用户模块的日志器，级别可通过 logger.modules.user 或管理接口单独调整。
+var log = logger.Module("user")
+
 // Register 以结构化/依赖注入方式初始化用户功能。
 func Register(ctx context.Context, deps *feature.Dependencies) error {
 	if err := deps.Require("DB", "Router"); err != nil {
 		return fmt.Errorf("user feature dependencies: %w", err)
 	}
 
+	if err := ValidateRegistrationMode(deps.Config.User.RegistrationMode); err != nil {
+		return fmt.Errorf("user feature config: %w", err)
+	}
+
 	authService := auth.DefaultService()
 	if authService == nil {
 		return fmt.Errorf("user feature requires the auth service to be initialised")
@@ -31,7 +43,12 @@ func Register(ctx context.Context, deps *feature.Dependencies) error {
 		return fmt.Errorf("initialise rbac service: %w", err)
 	}
 
-	svc := NewService(repo, authService, rbacService)
+	svc := NewService(repo, authService, rbacService, deps.Config.User)
+	// 由用户模块提供账户状态校验，非 active 账户无法刷新令牌或通过鉴权。
+	authService.SetAccountChecker(svc)
+	// 后台定期清理过期的临时角色，并注销受影响用户的会话。
+	go svc.RunRoleExpiry(ctx, roleExpiryInterval)
+
 	handler := NewHandler(svc)
 	deps.Router.RegisterModule("user", handler.GetRoutes())
 
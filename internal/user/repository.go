@@ -2,11 +2,15 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/model"
+	"github.com/Jayleonc/service/pkg/xerr"
 )
 
 // Repository 提供用户数据的数据库访问能力。
@@ -24,51 +28,181 @@ func (r *Repository) Create(ctx context.Context, user *User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
-// Update 更新已有的用户记录。
+// Update 以乐观锁方式更新已有的用户记录，user.Version 与数据库不一致时返回 model.ErrVersionConflict。
 func (r *Repository) Update(ctx context.Context, user *User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return model.SaveVersioned(r.db.WithContext(ctx), user, &user.Versioned)
 }
 
-// Delete 根据 ID 删除用户，并清理角色关联关系。
+// Delete 根据 ID 软删除用户，用户不存在时返回 ErrUserNotFound。角色关联会被保留，以便 Restore 时原样恢复。
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&User{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound.Wrap(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// Restore 恢复已软删除的用户。若邮箱已被其他未删除用户占用，返回 gorm.ErrDuplicatedKey。
+func (r *Repository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound.Wrap(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// Purge 永久删除已软删除的用户及其角色关联；未删除的用户不能被清除。
+func (r *Repository) Purge(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		target := &User{ID: id}
-		if err := tx.Model(target).Association("Roles").Clear(); err != nil {
+		var user User
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&user, "id = ?", id).Error; err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&rbac.UserRole{}).Error; err != nil {
 			return err
 		}
-		return tx.Delete(&User{}, "id = ?", id).Error
+		return tx.Unscoped().Delete(&User{}, "id = ?", id).Error
 	})
 }
 
-// Get 根据 ID 查询用户并加载角色信息。
+// Get 根据 ID 查询用户并加载当前生效的角色信息。
 func (r *Repository) Get(ctx context.Context, id uuid.UUID) (*User, error) {
 	var user User
 	if err := r.db.WithContext(ctx).Preload("Roles").First(&user, "id = ?", id).Error; err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if err := r.FilterActiveRoles(ctx, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// GetByEmail 根据邮箱查询用户并加载角色信息。
+// GetByEmail 根据邮箱查询用户并加载当前生效的角色信息。
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
 	if err := r.db.WithContext(ctx).Preload("Roles").First(&user, "email = ?", email).Error; err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if err := r.FilterActiveRoles(ctx, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// FilterActiveRoles 移除尚未生效或已过期的角色分配，使 Roles 只包含当前生效的角色。
+func (r *Repository) FilterActiveRoles(ctx context.Context, users ...*User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	var active []rbac.UserRole
+	err := r.db.WithContext(ctx).
+		Model(&rbac.UserRole{}).
+		Scopes(rbac.ActiveAssignmentScope(time.Now())).
+		Where("user_id IN ?", ids).
+		Find(&active).Error
+	if err != nil {
+		return err
+	}
+
+	type key struct{ userID, roleID uuid.UUID }
+	activeSet := make(map[key]struct{}, len(active))
+	for _, assignment := range active {
+		activeSet[key{assignment.UserID, assignment.RoleID}] = struct{}{}
+	}
+
+	for _, user := range users {
+		roles := user.Roles[:0]
+		for _, role := range user.Roles {
+			if _, ok := activeSet[key{user.ID, role.ID}]; ok {
+				roles = append(roles, role)
+			}
+		}
+		user.Roles = roles
+	}
+	return nil
+}
+
 // Query 返回用于列表查询的基础链式查询对象。
 func (r *Repository) Query(ctx context.Context) *gorm.DB {
 	return r.db.WithContext(ctx).Model(&User{}).Preload("Roles")
 }
 
+// DeletedQuery 返回仅包含已软删除用户的链式查询对象。
+func (r *Repository) DeletedQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Unscoped().Model(&User{}).Preload("Roles").Where("deleted_at IS NOT NULL")
+}
+
 // ReplaceRoles 替换用户的角色集合
 func (r *Repository) ReplaceRoles(ctx context.Context, user *User, roles []*rbac.Role) error {
 	return r.db.WithContext(ctx).Model(user).Association("Roles").Replace(roles)
 }
 
-// Migrate 执行用户表结构迁移。
+// Migrate 执行用户表结构迁移，user_role 关联表使用 rbac.UserRole 以记录分配的有效期与来源。
 func (r *Repository) Migrate(ctx context.Context) error {
-	return r.db.WithContext(ctx).AutoMigrate(&User{})
+	db := r.db.WithContext(ctx)
+	if err := db.SetupJoinTable(&User{}, "Roles", &rbac.UserRole{}); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&User{}, &Invitation{}); err != nil {
+		return err
+	}
+	return migrateEmailUniqueness(db)
+}
+
+// legacyEmailIndex 是早期版本在 email 上建立的全表唯一索引，会阻止已删除用户的邮箱被重新注册。
+const legacyEmailIndex = "idx_user_email"
+
+// activeEmailIndex 只约束未删除用户的邮箱唯一性。
+const activeEmailIndex = "idx_user_email_active"
+
+// migrateEmailUniqueness 将 email 唯一性限定在未删除的用户上：
+// PostgreSQL 与 SQLite 使用部分索引；MySQL 不支持部分索引，改为在
+// 生成列 active_email（删除后为 NULL）上建立唯一索引，NULL 不参与唯一性比较。
+func migrateEmailUniqueness(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasIndex(&User{}, legacyEmailIndex) {
+		if err := migrator.DropIndex(&User{}, legacyEmailIndex); err != nil {
+			return err
+		}
+	}
+	if migrator.HasIndex(&User{}, activeEmailIndex) {
+		return nil
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		if !migrator.HasColumn(&User{}, "active_email") {
+			if err := db.Exec("ALTER TABLE `user` ADD COLUMN active_email VARCHAR(255) " +
+				"GENERATED ALWAYS AS (IF(deleted_at IS NULL, email, NULL)) VIRTUAL").Error; err != nil {
+				return err
+			}
+		}
+		return db.Exec("CREATE UNIQUE INDEX " + activeEmailIndex + " ON `user` (active_email)").Error
+	default:
+		return db.Exec(`CREATE UNIQUE INDEX ` + activeEmailIndex + ` ON "user" (email) WHERE deleted_at IS NULL`).Error
+	}
+}
+
+// notFound 将 gorm.ErrRecordNotFound 包装为模块内的 target 错误，原始错误仍可通过 errors.Is 判断；其他错误原样返回。
+func notFound(err error, target *xerr.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target.Wrap(err)
+	}
+	return err
 }
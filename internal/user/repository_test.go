@@ -0,0 +1,110 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// TestRepositoryEmailReuse 验证邮箱唯一性只约束未删除的用户：软删除后邮箱可重新注册，
+// 而此时恢复旧用户会因邮箱冲突失败。
+func TestRepositoryEmailReuse(t *testing.T) {
+	ctx := context.Background()
+	svc, _, db := newTestService(t, config.UserConfig{})
+	repo := svc.repo
+
+	original := createTestUser(t, db, "reuse@example.com", StatusActive)
+
+	duplicate := &User{ID: uuid.New(), Name: "duplicate", Email: "reuse@example.com", Status: StatusActive}
+	require.ErrorIs(t, repo.Create(ctx, duplicate), gorm.ErrDuplicatedKey)
+
+	require.NoError(t, repo.Delete(ctx, original.ID))
+	replacement := createTestUser(t, db, "reuse@example.com", StatusActive)
+
+	require.ErrorIs(t, repo.Restore(ctx, original.ID), gorm.ErrDuplicatedKey)
+	_, err := svc.RestoreUser(ctx, RestoreUserRequest{ID: original.ID})
+	require.ErrorIs(t, err, ErrEmailExists)
+
+	var deleted User
+	require.NoError(t, db.Unscoped().First(&deleted, "id = ?", original.ID).Error)
+	require.True(t, deleted.DeletedAt.Valid, "冲突时旧用户应保持删除状态")
+
+	require.NoError(t, repo.Delete(ctx, replacement.ID))
+	profile, err := svc.RestoreUser(ctx, RestoreUserRequest{ID: original.ID})
+	require.NoError(t, err)
+	require.Equal(t, original.ID, profile.ID)
+
+	restored, err := repo.GetByEmail(ctx, "reuse@example.com")
+	require.NoError(t, err)
+	require.Equal(t, original.ID, restored.ID)
+	require.Equal(t, original.Version+1, restored.Version)
+}
+
+// TestRepositoryRestore 验证只有已软删除的用户可以恢复。
+func TestRepositoryRestore(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	active := createTestUser(t, db, "active@example.com", StatusActive)
+
+	cases := []struct {
+		name string
+		id   uuid.UUID
+	}{
+		{name: "用户不存在", id: uuid.New()},
+		{name: "用户未删除", id: active.ID},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.Restore(ctx, tc.id)
+			require.ErrorIs(t, err, ErrUserNotFound)
+			require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		})
+	}
+}
+
+// TestRepositoryPurge 验证清除会同时删除用户与其角色关联，且只能清除已软删除的用户。
+func TestRepositoryPurge(t *testing.T) {
+	ctx := context.Background()
+	svc, _, db := newTestService(t, config.UserConfig{})
+	repo := svc.repo
+
+	roles, err := svc.rolesByNames(ctx, []string{constant.RoleAdmin, constant.RoleUser})
+	require.NoError(t, err)
+
+	purged := createTestUser(t, db, "purged@example.com", StatusActive)
+	kept := createTestUser(t, db, "kept@example.com", StatusActive)
+	for _, record := range []*User{purged, kept} {
+		require.NoError(t, repo.ReplaceRoles(ctx, record, roles))
+	}
+
+	countRoles := func(userID uuid.UUID) int64 {
+		var count int64
+		require.NoError(t, db.Model(&rbac.UserRole{}).Where("user_id = ?", userID).Count(&count).Error)
+		return count
+	}
+
+	require.ErrorIs(t, repo.Purge(ctx, purged.ID), ErrUserNotFound, "未删除的用户不能被清除")
+	require.Equal(t, int64(2), countRoles(purged.ID))
+
+	require.NoError(t, repo.Delete(ctx, purged.ID))
+	require.Equal(t, int64(2), countRoles(purged.ID), "软删除应保留角色关联")
+
+	require.NoError(t, repo.Purge(ctx, purged.ID))
+	require.Zero(t, countRoles(purged.ID))
+	require.Equal(t, int64(2), countRoles(kept.ID))
+
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&User{}).Where("id = ?", purged.ID).Count(&count).Error)
+	require.Zero(t, count)
+
+	require.ErrorIs(t, repo.Purge(ctx, purged.ID), ErrUserNotFound)
+}
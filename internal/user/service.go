@@ -2,7 +2,9 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"maps"
 	"strings"
 	"time"
 
@@ -12,18 +14,31 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/Jayleonc/service/internal/auth"
+	"github.com/Jayleonc/service/internal/feature"
 	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/config"
 	"github.com/Jayleonc/service/pkg/constant"
+	"github.com/Jayleonc/service/pkg/ginx/filter"
 	"github.com/Jayleonc/service/pkg/ginx/paginator"
 	"github.com/Jayleonc/service/pkg/ginx/request"
 	"github.com/Jayleonc/service/pkg/ginx/response"
+	"github.com/Jayleonc/service/pkg/model"
 )
 
+// SessionManager 是用户模块依赖的会话能力，由 *auth.Service 实现。
+type SessionManager interface {
+	IssueTokens(ctx context.Context, userID uuid.UUID, roles []string) (auth.Tokens, error)
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+}
+
+var _ SessionManager = (*auth.Service)(nil)
+
 // Service 协调用户相关的业务操作。
 type Service struct {
 	repo        *Repository
-	authService *auth.Service
+	authService SessionManager
 	rbacService *rbac.Service
+	settings    config.UserConfig
 }
 
 // RegisterInput 定义注册用户所需的入参结构。
@@ -40,10 +55,11 @@ type LoginInput struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// UpdateProfileInput 定义用户自助更新资料的入参。
+// UpdateProfileInput 定义用户自助更新资料的入参，Version 非零时要求与当前版本一致。
 type UpdateProfileInput struct {
-	Name  string `json:"name" validate:"omitempty"`
-	Phone string `json:"phone" validate:"omitempty"`
+	Name    string `json:"name" validate:"omitempty"`
+	Phone   string `json:"phone" validate:"omitempty"`
+	Version int64  `json:"version" validate:"omitempty"`
 }
 
 // CreateUserRequest 管理员创建用户请求
@@ -55,11 +71,12 @@ type CreateUserRequest struct {
 	Roles    []string `json:"roles" validate:"omitempty,dive,required"`
 }
 
-// UpdateUserRequest 管理员更新用户信息
+// UpdateUserRequest 管理员更新用户信息，Version 非零时要求与当前版本一致。
 type UpdateUserRequest struct {
-	ID    uuid.UUID `json:"id" validate:"required"`
-	Name  string    `json:"name" validate:"omitempty"`
-	Phone string    `json:"phone" validate:"omitempty"`
+	ID      uuid.UUID `json:"id" validate:"required"`
+	Name    string    `json:"name" validate:"omitempty"`
+	Phone   string    `json:"phone" validate:"omitempty"`
+	Version int64     `json:"version" validate:"omitempty"`
 }
 
 // DeleteUserRequest 管理员删除用户
@@ -67,32 +84,96 @@ type DeleteUserRequest struct {
 	ID uuid.UUID `json:"id" validate:"required"`
 }
 
+// RestoreUserRequest 管理员恢复已删除用户
+type RestoreUserRequest struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+// PurgeUserRequest 管理员永久清除已删除用户
+type PurgeUserRequest struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
 // AssignRolesRequest 管理员分配角色
 type AssignRolesRequest struct {
 	ID    uuid.UUID `json:"id" validate:"required"`
 	Roles []string  `json:"roles" validate:"required,min=1,dive,required"`
 }
 
-// ListUsersRequest 用户分页请求
+// GrantRoleRequest 管理员授予（临时）角色
+type GrantRoleRequest struct {
+	ID        uuid.UUID  `json:"id" validate:"required"`
+	Role      string     `json:"role" validate:"required"`
+	StartsAt  *time.Time `json:"startsAt" validate:"omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt" validate:"omitempty"`
+	Reason    string     `json:"reason" validate:"omitempty,max=512"`
+}
+
+// RevokeRoleRequest 管理员撤销角色
+type RevokeRoleRequest struct {
+	ID   uuid.UUID `json:"id" validate:"required"`
+	Role string    `json:"role" validate:"required"`
+}
+
+// defaultExpiringWindow 是查询即将过期角色分配时的默认时间窗口。
+const defaultExpiringWindow = 7 * 24 * time.Hour
+
+// ListUsersRequest 用户分页请求，Name 与 Email 为兼容旧接口的模糊匹配条件，等价于对应字段的 like 过滤。
 type ListUsersRequest struct {
 	Pagination request.Pagination `json:"pagination"`
+	Filters    []request.Filter   `json:"filters"`
 	Name       string             `json:"name"`
 	Email      string             `json:"email"`
 }
 
+// ListUsersCursorRequest 用户游标分页请求
+type ListUsersCursorRequest struct {
+	Cursor  request.CursorPagination `json:"cursor"`
+	Filters []request.Filter         `json:"filters"`
+	Name    string                   `json:"name"`
+	Email   string                   `json:"email"`
+}
+
+// userCursorSortKeys 是用户游标分页允许的排序列，主键 id 始终可用。
+var userCursorSortKeys = []string{"created_at", "name", "email"}
+
+// userListSchema 声明用户列表可过滤、可排序的字段。
+var userListSchema = filter.Schema{
+	"name":            {Column: "name", Type: filter.String, Ops: []filter.Op{filter.OpEq, filter.OpLike}, Sortable: true},
+	"email":           {Column: "email", Type: filter.String, Ops: []filter.Op{filter.OpEq, filter.OpIn, filter.OpLike}, Sortable: true},
+	"phone":           {Column: "phone", Type: filter.String, Ops: []filter.Op{filter.OpEq, filter.OpLike}},
+	"status":          {Column: "status", Type: filter.String, Ops: []filter.Op{filter.OpEq, filter.OpIn}, Sortable: true},
+	"statusChangedAt": {Column: "status_changed_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange, filter.OpIsNull}, Sortable: true},
+	"createdAt":       {Column: "created_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange}, Sortable: true},
+	"updatedAt":       {Column: "updated_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange}, Sortable: true},
+}
+
+// deletedUserListSchema 在 userListSchema 的基础上允许按删除时间过滤与排序。
+var deletedUserListSchema = func() filter.Schema {
+	schema := maps.Clone(userListSchema)
+	schema["deletedAt"] = filter.Field{Column: "deleted_at", Type: filter.Time, Ops: []filter.Op{filter.OpRange}, Sortable: true}
+	return schema
+}()
+
 // LoginResult 描述登录成功后的返回结果。
 type LoginResult struct {
 	Profile Profile
 	Tokens  auth.Tokens
 }
 
-// NewService 创建 Service 实例。
-func NewService(repo *Repository, authService *auth.Service, rbacService *rbac.Service) *Service {
-	return &Service{repo: repo, authService: authService, rbacService: rbacService}
+// NewService 创建 Service 实例，settings 控制自助注册方式与邀请有效期。
+func NewService(repo *Repository, authService SessionManager, rbacService *rbac.Service, settings config.UserConfig) *Service {
+	return &Service{repo: repo, authService: authService, rbacService: rbacService, settings: settings}
 }
 
-// Register 持久化新用户。
-func (s *Service) Register(ctx context.Context, input RegisterInput) (Profile, error) {
+// Register 持久化新用户，受 user.registration_mode 约束。
+func (s *Service) Register(ctx context.Context, input RegisterInput) (_ Profile, err error) {
+	defer func() { registrationsTotal.WithLabelValues(registrationSelf, registrationOutcome(err)).Inc() }()
+
+	if err := s.checkRegistrationAllowed(strings.ToLower(input.Email)); err != nil {
+		return Profile{}, err
+	}
+
 	roles, err := s.rolesByNames(ctx, []string{constant.RoleUser})
 	if err != nil {
 		return Profile{}, err
@@ -112,6 +193,7 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (Profile, e
 		Email:        strings.ToLower(input.Email),
 		PasswordHash: string(passwordHash),
 		Phone:        input.Phone,
+		Status:       StatusActive,
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -130,7 +212,9 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (Profile, e
 }
 
 // Login 校验凭证并签发新的令牌对。
-func (s *Service) Login(ctx context.Context, input LoginInput) (LoginResult, error) {
+func (s *Service) Login(ctx context.Context, input LoginInput) (_ LoginResult, err error) {
+	defer func() { loginsTotal.WithLabelValues(loginOutcome(err)).Inc() }()
+
 	record, err := s.repo.GetByEmail(ctx, strings.ToLower(input.Email))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -143,6 +227,10 @@ func (s *Service) Login(ctx context.Context, input LoginInput) (LoginResult, err
 		return LoginResult{}, ErrInvalidCredentials
 	}
 
+	if err := record.Status.Err(); err != nil {
+		return LoginResult{}, err
+	}
+
 	roles := roleNames(record.Roles)
 	if len(roles) == 0 {
 		return LoginResult{}, ErrRolesRequired
@@ -165,6 +253,11 @@ func (s *Service) Profile(ctx context.Context, id uuid.UUID) (Profile, error) {
 	return toProfile(*record), nil
 }
 
+// Permissions 返回当前用户的有效权限集合及其版本号，供前端按权限控制界面元素。
+func (s *Service) Permissions(ctx context.Context, session feature.AuthContext) (*rbac.UserPermissions, error) {
+	return s.rbacService.EffectiveUserPermissions(ctx, session.UserID, session.Roles)
+}
+
 // UpdateProfile 修改用户个人资料。
 func (s *Service) UpdateProfile(ctx context.Context, id uuid.UUID, input UpdateProfileInput) (Profile, error) {
 	record, err := s.repo.Get(ctx, id)
@@ -172,7 +265,7 @@ func (s *Service) UpdateProfile(ctx context.Context, id uuid.UUID, input UpdateP
 		return Profile{}, err
 	}
 
-	logger.Info(ctx, "updating user profile in service", logger.String("id", id.String()))
+	log.Debug(ctx, "updating user profile in service", logger.String("id", id.String()))
 
 	if input.Name != "" {
 		record.Name = input.Name
@@ -181,7 +274,7 @@ func (s *Service) UpdateProfile(ctx context.Context, id uuid.UUID, input UpdateP
 		record.Phone = input.Phone
 	}
 
-	if err := s.repo.Update(ctx, record); err != nil {
+	if err := s.updateVersioned(ctx, record, input.Version); err != nil {
 		return Profile{}, err
 	}
 
@@ -214,6 +307,7 @@ func (s *Service) CreateUser(ctx context.Context, req CreateUserRequest) (Profil
 		Email:        strings.ToLower(req.Email),
 		PasswordHash: string(passwordHash),
 		Phone:        req.Phone,
+		Status:       StatusActive,
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -245,36 +339,80 @@ func (s *Service) UpdateUser(ctx context.Context, req UpdateUserRequest) (Profil
 		record.Phone = req.Phone
 	}
 
-	if err := s.repo.Update(ctx, record); err != nil {
+	if err := s.updateVersioned(ctx, record, req.Version); err != nil {
 		return Profile{}, err
 	}
 
 	return toProfile(*record), nil
 }
 
-// DeleteUser 管理员删除用户
+// updateVersioned 保存用户记录：expected 非零时先校验客户端持有的版本，写入时再由数据库比较版本，
+// 任一环节不一致都返回 ErrVersionConflict。
+func (s *Service) updateVersioned(ctx context.Context, record *User, expected int64) error {
+	if expected != 0 && record.Version != expected {
+		return ErrVersionConflict
+	}
+	if err := s.repo.Update(ctx, record); err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteUser 管理员删除用户（软删除），并注销其现有会话。
 func (s *Service) DeleteUser(ctx context.Context, req DeleteUserRequest) error {
-	return s.repo.Delete(ctx, req.ID)
+	if err := s.repo.Delete(ctx, req.ID); err != nil {
+		return err
+	}
+	return s.authService.RevokeUserSessions(ctx, req.ID)
+}
+
+// RestoreUser 恢复已软删除的用户及其原有角色；邮箱已被他人占用时返回 ErrEmailExists。
+func (s *Service) RestoreUser(ctx context.Context, req RestoreUserRequest) (Profile, error) {
+	if err := s.repo.Restore(ctx, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return Profile{}, ErrEmailExists
+		}
+		return Profile{}, err
+	}
+	return s.Profile(ctx, req.ID)
+}
+
+// PurgeUser 永久清除已软删除的用户。
+func (s *Service) PurgeUser(ctx context.Context, req PurgeUserRequest) error {
+	return s.repo.Purge(ctx, req.ID)
 }
 
 // ListUsers 使用统一分页返回用户列表
 func (s *Service) ListUsers(ctx context.Context, req ListUsersRequest) (*response.PageResult[Profile], error) {
-	query := s.repo.Query(ctx)
-	if req.Name != "" {
-		query = query.Where("name LIKE ?", "%"+req.Name+"%")
+	return s.listProfiles(ctx, s.repo.Query(ctx), userListSchema, req)
+}
+
+// ListDeletedUsers 分页返回已软删除的用户
+func (s *Service) ListDeletedUsers(ctx context.Context, req ListUsersRequest) (*response.PageResult[Profile], error) {
+	return s.listProfiles(ctx, s.repo.DeletedQuery(ctx), deletedUserListSchema, req)
+}
+
+func (s *Service) listProfiles(ctx context.Context, query *gorm.DB, schema filter.Schema, req ListUsersRequest) (*response.PageResult[Profile], error) {
+	scope, err := schema.Scope(withLegacyFilters(req.Filters, req.Name, req.Email))
+	if err != nil {
+		return nil, err
 	}
-	if req.Email != "" {
-		query = query.Where("email LIKE ?", "%"+req.Email+"%")
+	pagination := req.Pagination
+	if pagination.OrderBy, err = schema.OrderBy(pagination.OrderBy); err != nil {
+		return nil, err
 	}
 
-	pageResult, err := paginator.Paginate[User](query, &req.Pagination)
+	pageResult, err := paginator.Paginate[User](query.Scopes(scope), &pagination, schema.SortColumns()...)
 	if err != nil {
 		return nil, err
 	}
 
-	profiles := make([]Profile, 0, len(pageResult.List))
-	for _, user := range pageResult.List {
-		profiles = append(profiles, toProfile(user))
+	profiles, err := s.toProfiles(ctx, pageResult.List)
+	if err != nil {
+		return nil, err
 	}
 
 	return &response.PageResult[Profile]{
@@ -285,6 +423,62 @@ func (s *Service) ListUsers(ctx context.Context, req ListUsersRequest) (*respons
 	}, nil
 }
 
+// ListUsersByCursor 使用游标分页返回用户列表，适合大表的顺序翻页。
+func (s *Service) ListUsersByCursor(ctx context.Context, req ListUsersCursorRequest) (*response.CursorPageResult[Profile], error) {
+	scope, err := userListSchema.Scope(withLegacyFilters(req.Filters, req.Name, req.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	pageResult, err := paginator.CursorPaginate[User](s.repo.Query(ctx).Scopes(scope), &req.Cursor, userCursorSortKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.toProfiles(ctx, pageResult.List)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.CursorPageResult[Profile]{
+		List:       profiles,
+		NextCursor: pageResult.NextCursor,
+		HasMore:    pageResult.HasMore,
+		Total:      pageResult.Total,
+		PageSize:   pageResult.PageSize,
+	}, nil
+}
+
+// withLegacyFilters 将旧接口的 name/email 参数转换为 like 过滤条件。
+func withLegacyFilters(filters []request.Filter, name, email string) []request.Filter {
+	legacy := [][2]string{{"name", name}, {"email", email}}
+	for _, pair := range legacy {
+		if pair[1] == "" {
+			continue
+		}
+		raw, _ := json.Marshal(pair[1])
+		filters = append(filters, request.Filter{Field: pair[0], Op: string(filter.OpLike), Value: raw})
+	}
+	return filters
+}
+
+// toProfiles 过滤未生效的角色后转换为对外返回的 Profile 列表。
+func (s *Service) toProfiles(ctx context.Context, list []User) ([]Profile, error) {
+	users := make([]*User, 0, len(list))
+	for i := range list {
+		users = append(users, &list[i])
+	}
+	if err := s.repo.FilterActiveRoles(ctx, users...); err != nil {
+		return nil, err
+	}
+
+	profiles := make([]Profile, 0, len(list))
+	for _, user := range list {
+		profiles = append(profiles, toProfile(user))
+	}
+	return profiles, nil
+}
+
 // AssignRoles 管理员分配角色
 func (s *Service) AssignRoles(ctx context.Context, req AssignRolesRequest) (Profile, error) {
 	record, err := s.repo.Get(ctx, req.ID)
@@ -308,6 +502,75 @@ func (s *Service) AssignRoles(ctx context.Context, req AssignRolesRequest) (Prof
 	return toProfile(*record), nil
 }
 
+// GrantRole 为用户授予角色，可选指定生效与过期时间。
+func (s *Service) GrantRole(ctx context.Context, req GrantRoleRequest) (*rbac.RoleAssignment, error) {
+	if _, err := s.repo.Get(ctx, req.ID); err != nil {
+		return nil, err
+	}
+
+	return s.rbacService.GrantRole(ctx, rbac.GrantRoleInput{
+		UserID:    req.ID,
+		Role:      req.Role,
+		StartsAt:  req.StartsAt,
+		ExpiresAt: req.ExpiresAt,
+		Reason:    req.Reason,
+	})
+}
+
+// RevokeRole 撤销用户的角色，并注销其现有会话以立即收回权限。
+func (s *Service) RevokeRole(ctx context.Context, req RevokeRoleRequest) error {
+	if err := s.rbacService.RevokeRole(ctx, rbac.RevokeRoleInput{UserID: req.ID, Role: req.Role}); err != nil {
+		return err
+	}
+	return s.authService.RevokeUserSessions(ctx, req.ID)
+}
+
+// ListExpiringRoles 返回即将在指定时间窗口内过期的角色分配。
+func (s *Service) ListExpiringRoles(ctx context.Context, within time.Duration) ([]rbac.RoleAssignment, error) {
+	if within <= 0 {
+		within = defaultExpiringWindow
+	}
+	return s.rbacService.ListExpiringAssignments(ctx, within)
+}
+
+// ExpireRoles 清理已过期的角色分配，并注销受影响用户的会话。
+func (s *Service) ExpireRoles(ctx context.Context) error {
+	expired, err := s.rbacService.ExpireAssignments(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	affected := make(map[uuid.UUID]struct{}, len(expired))
+	for _, assignment := range expired {
+		affected[assignment.UserID] = struct{}{}
+	}
+	for userID := range affected {
+		if err := s.authService.RevokeUserSessions(ctx, userID); err != nil {
+			return err
+		}
+		log.Info(ctx, "role assignment expired, sessions revoked", "userId", userID.String())
+	}
+	return nil
+}
+
+// RunRoleExpiry 按固定间隔执行 ExpireRoles，直到 ctx 结束。
+func (s *Service) RunRoleExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ExpireRoles(ctx); err != nil {
+			log.Error(ctx, "failed to expire role assignments", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (s *Service) rolesByNames(ctx context.Context, names []string) ([]*rbac.Role, error) {
 	roles, err := s.rbacService.GetRolesByNames(ctx, names)
 	if err != nil {
@@ -337,23 +600,33 @@ func roleNames(roles []*rbac.Role) []string {
 
 // Profile 表示返回给客户端的安全用户信息。
 type Profile struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Roles     []string  `json:"roles"`
-	Phone     string    `json:"phone"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Roles     []string   `json:"roles"`
+	Phone     string     `json:"phone"`
+	Status    Status     `json:"status"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
 func toProfile(u User) Profile {
-	return Profile{
+	profile := Profile{
 		ID:        u.ID,
 		Name:      u.Name,
 		Email:     u.Email,
 		Roles:     roleNames(u.Roles),
 		Phone:     u.Phone,
+		Status:    u.Status,
+		Version:   u.Version,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
+	if u.DeletedAt.Valid {
+		deletedAt := u.DeletedAt.Time
+		profile.DeletedAt = &deletedAt
+	}
+	return profile
 }
@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/auth"
+)
+
+// Status 表示用户账户的状态。
+type Status string
+
+// 账户状态。只有 StatusActive 的账户可以登录、刷新令牌与访问受保护接口。
+const (
+	StatusPending   Status = "pending"
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+	StatusDisabled  Status = "disabled"
+)
+
+// statusTransitions 定义允许的状态流转。
+//
+//	pending   -> active | disabled
+//	active    -> suspended | disabled
+//	suspended -> active | disabled
+//	disabled  -> active
+var statusTransitions = map[Status][]Status{
+	StatusPending:   {StatusActive, StatusDisabled},
+	StatusActive:    {StatusSuspended, StatusDisabled},
+	StatusSuspended: {StatusActive, StatusDisabled},
+	StatusDisabled:  {StatusActive},
+}
+
+// ParseStatus 解析状态字符串，未知状态返回 ErrInvalidStatus。
+func ParseStatus(value string) (Status, error) {
+	status := Status(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := statusTransitions[status]; !ok {
+		return "", ErrInvalidStatus
+	}
+	return status, nil
+}
+
+// CanTransitionTo 判断当前状态能否流转到目标状态。
+func (s Status) CanTransitionTo(target Status) bool {
+	for _, next := range statusTransitions[s] {
+		if next == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Err 返回该状态对应的认证错误；StatusActive 返回 nil。
+func (s Status) Err() error {
+	switch s {
+	case StatusActive:
+		return nil
+	case StatusPending:
+		return auth.ErrAccountPending
+	case StatusSuspended:
+		return auth.ErrAccountSuspended
+	default:
+		return auth.ErrAccountDisabled
+	}
+}
+
+// revokesSessions 判断流转到该状态时是否需要注销全部会话。
+func (s Status) revokesSessions() bool {
+	return s == StatusSuspended || s == StatusDisabled
+}
+
+// ChangeStatusRequest 管理员变更账户状态
+type ChangeStatusRequest struct {
+	ID     uuid.UUID `json:"id" validate:"required"`
+	Status Status    `json:"status" validate:"required"`
+	Reason string    `json:"reason" validate:"omitempty,max=512"`
+}
+
+// GetStatus 查询用户的账户状态。
+func (r *Repository) GetStatus(ctx context.Context, id uuid.UUID) (Status, error) {
+	var user User
+	if err := r.db.WithContext(ctx).Select("status").First(&user, "id = ?", id).Error; err != nil {
+		return "", notFound(err, ErrUserNotFound)
+	}
+	return user.Status, nil
+}
+
+// UpdateStatus 以比较并交换的方式更新账户状态，避免并发流转相互覆盖。
+// beforeCommit 在更新成功后、事务提交前执行，返回错误时回滚本次流转；为 nil 时直接提交。
+func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to Status, reason string, at time.Time, beforeCommit func() error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&User{}).
+			Where("id = ? AND status = ?", id, from).
+			Updates(map[string]any{
+				"status":            to,
+				"status_reason":     reason,
+				"status_changed_at": at,
+				"version":           gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidStatusTransition
+		}
+		if beforeCommit == nil {
+			return nil
+		}
+		return beforeCommit()
+	})
+}
+
+// ChangeStatus 按状态机流转账户状态；暂停或禁用时会注销该用户的全部会话。
+// 会话在状态提交前注销，注销失败时状态保持不变并返回错误，调用方可以直接重试，
+// 不会出现账户已暂停或禁用、刷新会话却仍然保留的情况。
+func (s *Service) ChangeStatus(ctx context.Context, req ChangeStatusRequest) (Profile, error) {
+	target, err := ParseStatus(string(req.Status))
+	if err != nil {
+		return Profile{}, err
+	}
+
+	current, err := s.repo.GetStatus(ctx, req.ID)
+	if err != nil {
+		return Profile{}, err
+	}
+	if !current.CanTransitionTo(target) {
+		return Profile{}, ErrInvalidStatusTransition
+	}
+
+	var revoke func() error
+	if target.revokesSessions() {
+		revoke = func() error { return s.authService.RevokeUserSessions(ctx, req.ID) }
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if err := s.repo.UpdateStatus(ctx, req.ID, current, target, reason, time.Now(), revoke); err != nil {
+		return Profile{}, err
+	}
+	log.Info(ctx, "user status changed", "userId", req.ID.String(), "from", string(current), "to", string(target), "reason", reason)
+
+	return s.Profile(ctx, req.ID)
+}
+
+// CheckAccount 实现 auth.AccountChecker，拒绝非 active 状态的账户继续使用会话。
+func (s *Service) CheckAccount(ctx context.Context, userID uuid.UUID) error {
+	status, err := s.repo.GetStatus(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrInvalidToken
+		}
+		return err
+	}
+	return status.Err()
+}
+
+var _ auth.AccountChecker = (*Service)(nil)
@@ -0,0 +1,235 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/internal/auth"
+	"github.com/Jayleonc/service/internal/rbac"
+	"github.com/Jayleonc/service/pkg/config"
+	"github.com/Jayleonc/service/pkg/constant"
+)
+
+// fakeSessions 记录签发与注销的会话，替代依赖 Redis 的 auth.Service。
+type fakeSessions struct {
+	issued    []uuid.UUID
+	revoked   []uuid.UUID
+	revokeErr error
+}
+
+func (f *fakeSessions) IssueTokens(_ context.Context, userID uuid.UUID, _ []string) (auth.Tokens, error) {
+	f.issued = append(f.issued, userID)
+	return auth.Tokens{AccessToken: "access-" + userID.String(), RefreshToken: "refresh-" + userID.String()}, nil
+}
+
+func (f *fakeSessions) RevokeUserSessions(_ context.Context, userID uuid.UUID) error {
+	f.revoked = append(f.revoked, userID)
+	return f.revokeErr
+}
+
+// setupTestDB 创建独立的内存数据库并执行用户与 RBAC 模块的迁移。
+// 与 database.Open 一致开启 TranslateError，使唯一约束冲突返回 gorm.ErrDuplicatedKey。
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	require.NoError(t, rbac.NewRepository(db).Migrate(context.Background()))
+	require.NoError(t, NewRepository(db).Migrate(context.Background()))
+	return db
+}
+
+// newTestService 基于内存数据库构造 Service，并预置 ADMIN 与 USER 角色。
+func newTestService(t *testing.T, settings config.UserConfig) (*Service, *fakeSessions, *gorm.DB) {
+	t.Helper()
+
+	db := setupTestDB(t)
+	rbacService := rbac.NewService(rbac.NewRepository(db))
+	for _, name := range []string{constant.RoleAdmin, constant.RoleUser} {
+		_, err := rbacService.CreateRole(context.Background(), rbac.CreateRoleInput{Name: name})
+		require.NoError(t, err)
+	}
+
+	sessions := &fakeSessions{}
+	return NewService(NewRepository(db), sessions, rbacService, settings), sessions, db
+}
+
+// createTestUser 直接写入一个指定状态的用户。
+func createTestUser(t *testing.T, db *gorm.DB, email string, status Status) *User {
+	t.Helper()
+
+	record := &User{ID: uuid.New(), Name: "tester", Email: email, Status: status}
+	require.NoError(t, db.Create(record).Error)
+	return record
+}
+
+// TestStatusCanTransitionTo 覆盖状态机允许与拒绝的全部流转。
+func TestStatusCanTransitionTo(t *testing.T) {
+	cases := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{from: StatusPending, to: StatusActive, want: true},
+		{from: StatusPending, to: StatusDisabled, want: true},
+		{from: StatusPending, to: StatusSuspended},
+		{from: StatusPending, to: StatusPending},
+		{from: StatusActive, to: StatusSuspended, want: true},
+		{from: StatusActive, to: StatusDisabled, want: true},
+		{from: StatusActive, to: StatusPending},
+		{from: StatusActive, to: StatusActive},
+		{from: StatusSuspended, to: StatusActive, want: true},
+		{from: StatusSuspended, to: StatusDisabled, want: true},
+		{from: StatusSuspended, to: StatusPending},
+		{from: StatusDisabled, to: StatusActive, want: true},
+		{from: StatusDisabled, to: StatusSuspended},
+		{from: StatusDisabled, to: StatusPending},
+		{from: Status("unknown"), to: StatusActive},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.from)+" 到 "+string(tc.to), func(t *testing.T) {
+			require.Equal(t, tc.want, tc.from.CanTransitionTo(tc.to))
+		})
+	}
+}
+
+// TestParseStatus 验证状态解析忽略大小写与空白，并拒绝未知状态。
+func TestParseStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		want    Status
+		wantErr error
+	}{
+		{name: "小写", value: "active", want: StatusActive},
+		{name: "大小写与空白", value: " Suspended ", want: StatusSuspended},
+		{name: "未知状态", value: "locked", wantErr: ErrInvalidStatus},
+		{name: "空字符串", value: "", wantErr: ErrInvalidStatus},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseStatus(tc.value)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestServiceChangeStatus 验证状态流转结果，以及暂停、禁用账户时注销其全部会话。
+func TestServiceChangeStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		from        Status
+		to          Status
+		wantErr     error
+		wantRevoked bool
+	}{
+		{name: "暂停账户注销会话", from: StatusActive, to: StatusSuspended, wantRevoked: true},
+		{name: "禁用账户注销会话", from: StatusSuspended, to: StatusDisabled, wantRevoked: true},
+		{name: "激活账户不注销会话", from: StatusPending, to: StatusActive},
+		{name: "恢复账户不注销会话", from: StatusSuspended, to: StatusActive},
+		{name: "不允许的流转", from: StatusDisabled, to: StatusSuspended, wantErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, sessions, db := newTestService(t, config.UserConfig{})
+			record := createTestUser(t, db, "status@example.com", tc.from)
+
+			profile, err := svc.ChangeStatus(context.Background(), ChangeStatusRequest{ID: record.ID, Status: tc.to, Reason: " review "})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Empty(t, sessions.revoked)
+
+				status, err := svc.repo.GetStatus(context.Background(), record.ID)
+				require.NoError(t, err)
+				require.Equal(t, tc.from, status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.to, profile.Status)
+
+			var stored User
+			require.NoError(t, db.First(&stored, "id = ?", record.ID).Error)
+			require.Equal(t, "review", stored.StatusReason)
+			require.NotNil(t, stored.StatusChangedAt)
+
+			if tc.wantRevoked {
+				require.Equal(t, []uuid.UUID{record.ID}, sessions.revoked)
+			} else {
+				require.Empty(t, sessions.revoked)
+			}
+			require.Equal(t, tc.to.Err() == nil, svc.CheckAccount(context.Background(), record.ID) == nil)
+		})
+	}
+}
+
+// TestServiceChangeStatusRevokeFailure 验证会话存储故障时状态流转整体回滚并返回错误，恢复后重试即可完成暂停与注销。
+func TestServiceChangeStatusRevokeFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, sessions, db := newTestService(t, config.UserConfig{})
+	record := createTestUser(t, db, "status@example.com", StatusActive)
+	sessions.revokeErr = errors.New("redis unavailable")
+
+	_, err := svc.ChangeStatus(ctx, ChangeStatusRequest{ID: record.ID, Status: StatusSuspended, Reason: "abuse"})
+	require.ErrorIs(t, err, sessions.revokeErr)
+	require.NoError(t, svc.CheckAccount(ctx, record.ID))
+
+	var stored User
+	require.NoError(t, db.First(&stored, "id = ?", record.ID).Error)
+	require.Empty(t, stored.StatusReason)
+	require.Nil(t, stored.StatusChangedAt)
+
+	sessions.revokeErr = nil
+	profile, err := svc.ChangeStatus(ctx, ChangeStatusRequest{ID: record.ID, Status: StatusSuspended, Reason: "abuse"})
+	require.NoError(t, err)
+	require.Equal(t, StatusSuspended, profile.Status)
+	require.Equal(t, []uuid.UUID{record.ID, record.ID}, sessions.revoked)
+	require.ErrorIs(t, svc.CheckAccount(ctx, record.ID), auth.ErrAccountSuspended)
+}
+
+// TestServiceCheckAccount 验证账户状态到认证错误的映射，不存在的用户视为令牌无效。
+func TestServiceCheckAccount(t *testing.T) {
+	svc, _, db := newTestService(t, config.UserConfig{})
+
+	cases := []struct {
+		name    string
+		status  Status
+		wantErr error
+	}{
+		{name: "正常账户", status: StatusActive},
+		{name: "待激活账户", status: StatusPending, wantErr: auth.ErrAccountPending},
+		{name: "已暂停账户", status: StatusSuspended, wantErr: auth.ErrAccountSuspended},
+		{name: "已禁用账户", status: StatusDisabled, wantErr: auth.ErrAccountDisabled},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := createTestUser(t, db, string(tc.status)+"@example.com", tc.status)
+			err := svc.CheckAccount(context.Background(), record.ID)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+
+	require.ErrorIs(t, svc.CheckAccount(context.Background(), uuid.New()), auth.ErrInvalidToken)
+}
@@ -27,6 +27,8 @@ func Init(cfg Config) (*redis.Client, error) {
 		Password: cfg.Password,
 		DB:       cfg.DB,
 	})
+	client.AddHook(NewTracingHook(cfg.Addr))
+	client.AddHook(NewMetricsHook())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
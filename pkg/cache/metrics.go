@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jayleonc/service/pkg/observe/metrics"
+)
+
+var (
+	commandDuration = metrics.Register(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Duration of Redis commands; pipelines are recorded as a single pipeline command.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		},
+		[]string{"command"},
+	))
+	commandErrors = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Failed Redis commands, excluding redis.Nil replies.",
+		},
+		[]string{"command"},
+	))
+)
+
+// metricsHook 记录 Redis 命令的耗时与错误，Init 创建的客户端会自动启用。
+type metricsHook struct{}
+
+// NewMetricsHook 返回记录命令指标的 go-redis Hook，可用于自行创建的客户端。
+func NewMetricsHook() redis.Hook {
+	return metricsHook{}
+}
+
+func (metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		record(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		record("pipeline", start, err)
+		return err
+	}
+}
+
+func record(command string, start time.Time, err error) {
+	commandDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, redis.Nil) {
+		commandErrors.WithLabelValues(command).Inc()
+	}
+}
+
+var _ redis.Hook = metricsHook{}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnection = errors.New("connection refused")
+
+// histogramCount 返回 collector 中 command 标签为 command 的直方图样本数，不存在时返回 0。
+func histogramCount(t *testing.T, collector prometheus.Collector, command string) uint64 {
+	t.Helper()
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(collector))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "command" && label.GetValue() == command {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// TestMetricsHook 验证命令按名称记录耗时，管道记录为 pipeline，失败的命令计入错误数，redis.Nil 不视为错误。
+// Hook 直接包装模拟的下一环节，无需连接 Redis。
+func TestMetricsHook(t *testing.T) {
+	ctx := context.Background()
+	hook := NewMetricsHook()
+
+	cases := []struct {
+		name        string
+		command     string
+		err         error
+		pipeline    bool
+		wantCounted bool
+	}{
+		{name: "成功的命令", command: "set"},
+		{name: "键不存在", command: "get", err: redis.Nil},
+		{name: "失败的命令", command: "incr", err: errConnection, wantCounted: true},
+		{name: "成功的管道", command: "pipeline", pipeline: true},
+		{name: "失败的管道", command: "pipeline", err: errConnection, pipeline: true, wantCounted: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			observed := histogramCount(t, commandDuration, tc.command)
+			failed := testutil.ToFloat64(commandErrors.WithLabelValues(tc.command))
+
+			var err error
+			if tc.pipeline {
+				process := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return tc.err })
+				err = process(ctx, []redis.Cmder{redis.NewStatusCmd(ctx, "set", "k", "v"), redis.NewStringCmd(ctx, "get", "k")})
+			} else {
+				process := hook.ProcessHook(func(context.Context, redis.Cmder) error { return tc.err })
+				err = process(ctx, redis.NewCmd(ctx, tc.command, "k"))
+			}
+			require.ErrorIs(t, err, tc.err)
+
+			require.Equal(t, observed+1, histogramCount(t, commandDuration, tc.command))
+			wantFailed := failed
+			if tc.wantCounted {
+				wantFailed++
+			}
+			require.Equal(t, wantFailed, testutil.ToFloat64(commandErrors.WithLabelValues(tc.command)))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Jayleonc/service/pkg/cache"
+
+// tracingHook 为每个 Redis 命令创建子 Span。Span 只记录命令名，不记录参数，避免缓存内容进入链路数据。
+type tracingHook struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+// NewTracingHook 返回创建 Redis Span 的 go-redis Hook，addr 为服务端地址，Init 创建的客户端会自动启用。
+func NewTracingHook(addr string) redis.Hook {
+	return &tracingHook{
+		tracer: otel.Tracer(tracerName),
+		attrs:  []attribute.KeyValue{semconv.DBSystemRedis, semconv.ServerAddress(addr)},
+	}
+}
+
+func (h *tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.start(ctx, cmd.Name())
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordSpanError(span, err)
+		return err
+	}
+}
+
+func (h *tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := h.start(ctx, "pipeline")
+		defer span.End()
+		span.SetAttributes(attribute.Int("db.redis.num_cmd", len(cmds)))
+
+		err := next(ctx, cmds)
+		recordSpanError(span, err)
+		return err
+	}
+}
+
+func (h *tracingHook) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return h.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attrs...),
+		trace.WithAttributes(semconv.DBOperation(operation)),
+	)
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+var _ redis.Hook = (*tracingHook)(nil)
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans 将全局 TracerProvider 替换为记录 Span 的实现，测试结束后恢复。Hook 需在调用之后创建。
+func recordSpans(t *testing.T) (*tracetest.SpanRecorder, trace.Tracer) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder, provider.Tracer("test")
+}
+
+// TestTracingHook 验证每个命令与管道在调用方 Span 下创建客户端子 Span，下一环节收到子 Span 的上下文；
+// 失败的命令标记为错误，redis.Nil 不视为错误。
+func TestTracingHook(t *testing.T) {
+	recorder, tracer := recordSpans(t)
+	hook := NewTracingHook("cache.internal:6379")
+
+	cases := []struct {
+		name     string
+		command  string
+		err      error
+		pipeline bool
+		wantFail bool
+	}{
+		{name: "成功的命令", command: "set"},
+		{name: "键不存在", command: "get", err: redis.Nil},
+		{name: "失败的命令", command: "incr", err: errConnection, wantFail: true},
+		{name: "成功的管道", command: "pipeline", pipeline: true},
+		{name: "失败的管道", command: "pipeline", err: errConnection, pipeline: true, wantFail: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ended := len(recorder.Ended())
+			ctx, parent := tracer.Start(context.Background(), "parent")
+
+			var inner trace.SpanContext
+			var err error
+			if tc.pipeline {
+				process := hook.ProcessPipelineHook(func(ctx context.Context, _ []redis.Cmder) error {
+					inner = trace.SpanContextFromContext(ctx)
+					return tc.err
+				})
+				err = process(ctx, []redis.Cmder{redis.NewStatusCmd(ctx, "set", "k", "v"), redis.NewStringCmd(ctx, "get", "k")})
+			} else {
+				process := hook.ProcessHook(func(ctx context.Context, _ redis.Cmder) error {
+					inner = trace.SpanContextFromContext(ctx)
+					return tc.err
+				})
+				err = process(ctx, redis.NewCmd(ctx, tc.command, "k"))
+			}
+			parent.End()
+			require.ErrorIs(t, err, tc.err)
+
+			spans := recorder.Ended()[ended:]
+			require.Len(t, spans, 2)
+			span := spans[0]
+			require.Equal(t, tc.command, span.Name())
+			require.Equal(t, trace.SpanKindClient, span.SpanKind())
+			require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+			require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			require.Equal(t, span.SpanContext().SpanID(), inner.SpanID())
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			require.Equal(t, semconv.DBSystemRedis.Value, attrs[semconv.DBSystemKey])
+			require.Equal(t, "cache.internal:6379", attrs[semconv.ServerAddressKey].AsString())
+			require.Equal(t, tc.command, attrs[semconv.DBOperationKey].AsString())
+			if tc.pipeline {
+				require.Equal(t, int64(2), attrs["db.redis.num_cmd"].AsInt64())
+			}
+
+			if tc.wantFail {
+				require.Equal(t, codes.Error, span.Status().Code)
+				require.Equal(t, errConnection.Error(), span.Status().Description)
+			} else {
+				require.Equal(t, codes.Unset, span.Status().Code)
+				require.Empty(t, span.Events())
+			}
+		})
+	}
+}
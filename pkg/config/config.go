@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
@@ -26,6 +27,12 @@ type App struct {
 	Telemetry TelemetryConfig `mapstructure:"telemetry"`
 	// Redis 描述缓存服务的连接参数。
 	Redis RedisConfig `mapstructure:"redis"`
+	// RBAC 控制 RBAC 插件的声明式策略文件。
+	RBAC RBACConfig `mapstructure:"rbac"`
+	// User 控制用户注册方式与邀请有效期。
+	User UserConfig `mapstructure:"user"`
+	// Idempotency 控制 Idempotency-Key 请求头的幂等处理。
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
 }
 
 // ServerConfig 控制 HTTP 服务器的基础行为。
@@ -38,6 +45,15 @@ type ServerConfig struct {
 	ReadTimeout time.Duration `mapstructure:"read_timeout"`
 	// WriteTimeout 配置响应写入阶段的超时时间。
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// ShutdownTimeout 指定收到退出信号后等待进行中请求完成的最长时间，默认 15s。
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// TrustRequestID 指定是否沿用入站请求携带的 X-Request-ID（需通过格式校验），仅在服务位于可信网关之后时开启。
+	TrustRequestID bool `mapstructure:"trust_request_id"`
+	// ProblemDetails 指定是否默认以 RFC 9457 问题详情（application/problem+json）返回失败响应；
+	// 关闭时客户端仍可通过 Accept: application/problem+json 单独请求该格式。
+	ProblemDetails bool `mapstructure:"problem_details"`
+	// ProblemTypeBase 指定问题详情 type 字段的 URI 前缀，为空时使用 about:blank。
+	ProblemTypeBase string `mapstructure:"problem_type_base"`
 }
 
 // DatabaseConfig 描述使用 GORM 连接数据库所需的配置。
@@ -82,6 +98,39 @@ type LoggerConfig struct {
 	Pretty *bool `mapstructure:"pretty"`
 	// Directory 指定启用文件日志的目录，可选。
 	Directory string `mapstructure:"directory"`
+	// Modules 为模块（auth、user、rbac、gorm、access）单独指定日志级别，未列出的模块跟随 Level。
+	// 修改配置文件后无需重启即可生效。
+	Modules map[string]string `mapstructure:"modules"`
+	// FilePrefix 指定服务日志文件名前缀，当前文件为 <FilePrefix>.log。
+	FilePrefix string `mapstructure:"file_prefix"`
+	// MaxSizeMB 指定单个日志文件的最大体积（MB），超过后轮转；0 表示不按大小轮转。
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+	// RotateInterval 指定按时间轮转的周期；0 表示不按时间轮转。
+	RotateInterval time.Duration `mapstructure:"rotate_interval"`
+	// MaxAge 指定轮转文件的保留时长；0 表示不按时间清理。
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// MaxTotalSizeMB 指定日志文件总体积上限（MB），超出时删除最旧的轮转文件；0 表示不限制。
+	MaxTotalSizeMB int `mapstructure:"max_total_size_mb"`
+	// Compress 指定是否以 gzip 压缩轮转后的文件。
+	Compress bool `mapstructure:"compress"`
+	// FlushInterval 指定日志缓冲区写入文件的间隔。
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// AccessLog 为 true 时访问日志单独写入 Directory 下的 access.log。
+	AccessLog bool `mapstructure:"access_log"`
+	// Redact 控制日志中敏感内容的脱敏规则。
+	Redact LogRedactConfig `mapstructure:"redact"`
+}
+
+// LogRedactConfig 描述日志脱敏规则，内置规则之外可按环境追加字段名与正则。
+type LogRedactConfig struct {
+	// Enabled 指定是否启用脱敏，默认启用。
+	Enabled *bool `mapstructure:"enabled"`
+	// Keys 为追加的敏感字段名片段，匹配时忽略大小写、下划线与连字符。
+	Keys []string `mapstructure:"keys"`
+	// Patterns 为追加的正则表达式，命中的字符串内容会被替换。
+	Patterns []string `mapstructure:"patterns"`
+	// MaskSQLParams 指定 SQL 日志是否隐藏绑定参数，可选；若为空将根据 Mode 自动设置（生产模式隐藏）。
+	MaskSQLParams *bool `mapstructure:"mask_sql_params"`
 }
 
 // TelemetryConfig 描述链路追踪导出配置。
@@ -92,6 +141,31 @@ type TelemetryConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 	// Endpoint 指定 OTLP 采集端点地址。
 	Endpoint string `mapstructure:"endpoint"`
+	// Exporter 指定导出方式：otlp-grpc、otlp-http、stdout 或 file。
+	Exporter string `mapstructure:"exporter"`
+	// FilePath 指定 file 导出器写入的文件。
+	FilePath string `mapstructure:"file_path"`
+	// Insecure 为 true 时 OTLP 导出不使用 TLS。
+	Insecure bool `mapstructure:"insecure"`
+	// TLS 指定 OTLP 导出的 TLS 证书选项。
+	TLS TelemetryTLSConfig `mapstructure:"tls"`
+	// SampleRatio 指定根 Span 的采样比例（0-1）。
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// ParentBased 指定是否沿用上游服务的采样决定。
+	ParentBased bool `mapstructure:"parent_based"`
+}
+
+// TelemetryTLSConfig 描述连接 OTLP 采集端时使用的证书。
+type TelemetryTLSConfig struct {
+	// CAFile 指定校验采集端证书的 CA 文件，为空时使用系统根证书。
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile 与 KeyFile 指定双向 TLS 的客户端证书。
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ServerName 覆盖校验证书时使用的服务端名称。
+	ServerName string `mapstructure:"server_name"`
+	// InsecureSkipVerify 跳过证书校验，仅用于测试环境。
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
 }
 
 // RedisConfig 描述缓存使用的 Redis 连接参数。
@@ -106,6 +180,40 @@ type RedisConfig struct {
 	DB int `mapstructure:"db"`
 }
 
+// RBACConfig 控制启动时对声明式 RBAC 策略文件的同步行为。
+type RBACConfig struct {
+	// PolicyFile 指定 YAML/JSON 策略文件路径，为空时不做同步。
+	PolicyFile string `mapstructure:"policy_file"`
+	// Prune 指定是否删除策略文件中未声明的角色（内置角色除外）。
+	Prune bool `mapstructure:"prune"`
+	// DebugHeader 指定是否在权限拒绝响应中附带 X-RBAC-Debug 诊断头，仅在 dev 模式下生效。
+	DebugHeader bool `mapstructure:"debug_header"`
+}
+
+// UserConfig 控制用户自助注册与邀请流程。
+type UserConfig struct {
+	// RegistrationMode 指定自助注册方式：open、invite_only、closed 或 domain_allowlist。
+	RegistrationMode string `mapstructure:"registration_mode"`
+	// AllowedEmailDomains 在 domain_allowlist 模式下允许自助注册的邮箱域名。
+	AllowedEmailDomains []string `mapstructure:"allowed_email_domains"`
+	// InviteTTL 指定邀请链接的有效期。
+	InviteTTL time.Duration `mapstructure:"invite_ttl"`
+}
+
+// IdempotencyConfig 控制 Idempotency-Key 幂等中间件。
+type IdempotencyConfig struct {
+	// Enabled 控制是否处理 Idempotency-Key 请求头。
+	Enabled bool `mapstructure:"enabled"`
+	// Store 指定幂等记录的存储：redis 或 memory（仅限单实例）。
+	Store string `mapstructure:"store"`
+	// TTL 指定已完成响应的保留时间，窗口内的重复请求直接重放该响应。
+	TTL time.Duration `mapstructure:"ttl"`
+	// LockTTL 指定处理中记录的最长保留时间。
+	LockTTL time.Duration `mapstructure:"lock_ttl"`
+	// MaxRequestBytes 指定携带幂等键的请求体上限，超出时返回 413，默认 1MiB。
+	MaxRequestBytes int64 `mapstructure:"max_request_bytes"`
+}
+
 var (
 	global App
 	mu     sync.RWMutex
@@ -114,12 +222,57 @@ var (
 
 // Load 从环境变量与可选的 CLI 参数中读取配置。
 func Load(_ context.Context, _ []string) (App, error) {
+	v, err := newViper()
+	if err != nil {
+		return App{}, err
+	}
+
+	var cfg App
+	if err := v.Unmarshal(&cfg); err != nil {
+		return App{}, err
+	}
+	return cfg, nil
+}
+
+// Watch 监听配置文件的变化：文件被修改后重新加载配置、更新全局配置实例并调用 onChange。
+// 重新加载失败时保留原有配置并通过 onError 报告。没有找到配置文件时不做任何事。
+func Watch(onChange func(App), onError func(error)) error {
+	v, err := newViper()
+	if err != nil {
+		return err
+	}
+	if v.ConfigFileUsed() == "" {
+		return nil
+	}
+
+	v.OnConfigChange(func(fsnotify.Event) {
+		var cfg App
+		if err := v.Unmarshal(&cfg); err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		Set(cfg)
+		if onChange != nil {
+			onChange(cfg)
+		}
+	})
+	v.WatchConfig()
+	return nil
+}
+
+// newViper 创建带默认值、环境变量与配置文件的 viper 实例。
+func newViper() (*viper.Viper, error) {
 	v := viper.New()
 	v.SetDefault("mode", "dev")
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.port", 3000)
 	v.SetDefault("server.read_timeout", "5s")
 	v.SetDefault("server.write_timeout", "5s")
+	v.SetDefault("server.trust_request_id", false)
+	v.SetDefault("server.problem_details", false)
+	v.SetDefault("server.problem_type_base", "")
 
 	v.SetDefault("database.driver", "postgres")
 	v.SetDefault("database.host", "localhost")
@@ -136,16 +289,44 @@ func Load(_ context.Context, _ []string) (App, error) {
 	v.SetDefault("auth.refresh_ttl", "720h")
 
 	v.SetDefault("logger.directory", "")
+	v.SetDefault("logger.modules", map[string]string{})
+	v.SetDefault("logger.file_prefix", "service")
+	v.SetDefault("logger.max_size_mb", 100)
+	v.SetDefault("logger.rotate_interval", "24h")
+	v.SetDefault("logger.max_age", "168h")
+	v.SetDefault("logger.max_total_size_mb", 0)
+	v.SetDefault("logger.compress", true)
+	v.SetDefault("logger.flush_interval", "1s")
+	v.SetDefault("logger.access_log", false)
+	v.SetDefault("logger.redact.enabled", true)
 
 	v.SetDefault("telemetry.service_name", "auth-service")
 	v.SetDefault("telemetry.enabled", false)
 	v.SetDefault("telemetry.endpoint", "localhost:4317")
+	v.SetDefault("telemetry.exporter", "otlp-grpc")
+	v.SetDefault("telemetry.file_path", "./logs/traces.jsonl")
+	v.SetDefault("telemetry.insecure", true)
+	v.SetDefault("telemetry.sample_ratio", 1.0)
+	v.SetDefault("telemetry.parent_based", true)
 
 	v.SetDefault("redis.addr", "localhost:6379")
 	v.SetDefault("redis.username", "")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
 
+	v.SetDefault("rbac.policy_file", "")
+	v.SetDefault("rbac.prune", false)
+	v.SetDefault("rbac.debug_header", false)
+
+	v.SetDefault("user.registration_mode", "open")
+	v.SetDefault("user.allowed_email_domains", []string{})
+	v.SetDefault("user.invite_ttl", "72h")
+
+	v.SetDefault("idempotency.enabled", true)
+	v.SetDefault("idempotency.store", "redis")
+	v.SetDefault("idempotency.ttl", "24h")
+	v.SetDefault("idempotency.lock_ttl", "1m")
+
 	v.SetEnvPrefix("AUTH")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
@@ -158,15 +339,10 @@ func Load(_ context.Context, _ []string) (App, error) {
 	if err := v.ReadInConfig(); err != nil {
 		var configFileNotFound viper.ConfigFileNotFoundError
 		if !errors.As(err, &configFileNotFound) {
-			return App{}, err
+			return nil, err
 		}
 	}
-
-	var cfg App
-	if err := v.Unmarshal(&cfg); err != nil {
-		return App{}, err
-	}
-	return cfg, nil
+	return v, nil
 }
 
 // Init 加载配置并注册全局配置实例。
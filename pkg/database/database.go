@@ -38,21 +38,33 @@ func New(cfg Config) (*gorm.DB, error) {
 		driver = "postgres"
 	}
 
-	gormCfg := &gorm.Config{}
+	// TranslateError 将各方言的唯一约束冲突统一转换为 gorm.ErrDuplicatedKey。
+	gormCfg := &gorm.Config{TranslateError: true}
 	if cfg.Logger != nil {
 		gormCfg.Logger = cfg.Logger
 	}
 
+	var dialector gorm.Dialector
 	switch strings.ToLower(driver) {
 	case "postgres", "postgresql":
-		dsn := buildPostgresDSN(cfg)
-		return gorm.Open(postgres.Open(dsn), gormCfg)
+		dialector = postgres.Open(buildPostgresDSN(cfg))
 	case "mysql":
-		dsn := buildMySQLDSN(cfg)
-		return gorm.Open(mysql.Open(dsn), gormCfg)
+		dialector = mysql.Open(buildMySQLDSN(cfg))
 	default:
 		return nil, fmt.Errorf("database: unsupported driver %q", cfg.Driver)
 	}
+
+	db, err := gorm.Open(dialector, gormCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(NewMetricsPlugin()); err != nil {
+		return nil, fmt.Errorf("database: register metrics: %w", err)
+	}
+	if err := db.Use(NewTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("database: register tracing: %w", err)
+	}
+	return db, nil
 }
 
 func buildPostgresDSN(cfg Config) string {
@@ -16,78 +16,102 @@ const (
 	slowQueryThreshold = 200 * time.Millisecond
 )
 
-// Logger 实现 GORM 的日志接口，基于应用的 slog 日志系统。
+// log 是 GORM 日志使用的模块日志器。将 gorm 模块调整为 debug 即可在运行时输出全部 SQL。
+var log = applogger.Module("gorm")
+
+// Logger 实现 GORM 的日志接口，基于应用的 slog 日志系统，级别跟随 gorm 模块。
 type Logger struct {
-	level                     slog.Level
+	// level 为会话通过 LogMode 设置的最低级别，为 nil 时完全跟随 gorm 模块的级别。
+	level                     *slog.Level
 	ignoreRecordNotFoundError bool
 	slowThreshold             time.Duration
 	printSQL                  bool
 }
 
-// NewLogger 创建一个 GORM 日志器。
-func NewLogger(level slog.Level) gormlogger.Interface {
+// NewLogger 创建一个 GORM 日志器，日志级别由 gorm 模块决定，可通过 logger.SetLevel 在运行时调整。
+func NewLogger() gormlogger.Interface {
 	return &Logger{
-		level:                     level,
 		ignoreRecordNotFoundError: true,
 		slowThreshold:             slowQueryThreshold,
 		printSQL:                  false,
 	}
 }
 
-// LogMode 调整日志级别。
+// LogMode 为当前会话设置最低日志级别，只能在 gorm 模块级别的基础上进一步收紧；Info 同时为该会话开启 SQL 日志。
 func (l *Logger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
 	clone := *l
+	var min slog.Level
 	switch level {
 	case gormlogger.Silent:
-		clone.level = slog.LevelError + 1
+		min = slog.LevelError + 1
 	case gormlogger.Error:
-		clone.level = slog.LevelError
+		min = slog.LevelError
 	case gormlogger.Warn:
-		clone.level = slog.LevelWarn
-	case gormlogger.Info:
-		clone.level = slog.LevelInfo
+		min = slog.LevelWarn
 	default:
-		clone.level = l.level
-	}
-	if clone.level < l.level {
-		clone.level = l.level
+		min = slog.LevelInfo
 	}
+	clone.level = &min
 	// 当 GORM 收到 Info 级别（例如调用 db.Debug()）时，仅在该会话内开启 SQL 正常执行日志
 	clone.printSQL = level >= gormlogger.Info
 	return &clone
 }
 
+// enabled 判断当前会话是否输出 level 级别的日志。
+func (l *Logger) enabled(level slog.Level) bool {
+	if l.level != nil && level < *l.level {
+		return false
+	}
+	return log.Enabled(level)
+}
+
 // Info 记录信息日志。
 func (l *Logger) Info(ctx context.Context, msg string, data ...interface{}) {
-	if l.level > slog.LevelInfo {
-		return
+	if l.enabled(slog.LevelInfo) {
+		log.Logger(ctx).InfoContext(ctx, msg, data...)
 	}
-	applogger.Info(ctx, msg, data...)
 }
 
 // Warn 记录警告日志。
 func (l *Logger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	if l.level > slog.LevelWarn {
-		return
+	if l.enabled(slog.LevelWarn) {
+		log.Logger(ctx).WarnContext(ctx, msg, data...)
 	}
-	applogger.Warn(ctx, msg, data...)
 }
 
 // Error 记录错误日志。
 func (l *Logger) Error(ctx context.Context, msg string, data ...interface{}) {
-	if l.level > slog.LevelError {
-		return
+	if l.enabled(slog.LevelError) {
+		log.Logger(ctx).ErrorContext(ctx, msg, data...)
 	}
-	applogger.Error(ctx, msg, data...)
 }
 
-// Trace 记录 SQL 执行详情。
+// Trace 记录 SQL 执行详情：失败与慢查询分别以 error、warn 级别输出，
+// 其余 SQL 在会话调用 db.Debug() 时以 info 级别输出，或在 gorm 模块为 debug 时以 debug 级别输出。
 func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	if fc == nil {
 		return
 	}
 
 	elapsed := time.Since(begin)
+	failed := err != nil && !(errors.Is(err, gorm.ErrRecordNotFound) && l.ignoreRecordNotFoundError)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	var level slog.Level
+	switch {
+	case failed:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	case l.printSQL:
+		level = slog.LevelInfo
+	default:
+		level = slog.LevelDebug
+	}
+	if !l.enabled(level) {
+		return
+	}
+
 	sql, rows := fc()
 	args := []any{
 		"sql", sql,
@@ -97,19 +121,23 @@ func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string,
 	}
 
 	switch {
-	case err != nil && !(errors.Is(err, gorm.ErrRecordNotFound) && l.ignoreRecordNotFoundError):
+	case failed:
 		args = append(args, "error", err)
-		applogger.Error(ctx, "sql execution failed", args...)
-	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		log.Logger(ctx).ErrorContext(ctx, "sql execution failed", args...)
+	case slow:
 		args = append(args, "threshold", l.slowThreshold)
-		if l.level <= slog.LevelWarn {
-			applogger.Warn(ctx, "slow query", args...)
-		}
+		log.Logger(ctx).WarnContext(ctx, "slow query", args...)
 	default:
-		if l.printSQL {
-			applogger.Info(ctx, "sql executed", args...)
-		}
+		log.Logger(ctx).Log(ctx, level, "sql executed", args...)
 	}
 }
 
-var _ gormlogger.Interface = (*Logger)(nil)
+// ParamsFilter 在 GORM 拼接日志中的 SQL 之前，按日志脱敏规则处理绑定参数，避免密码哈希、邮箱等取值进入日志。
+func (l *Logger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, applogger.RedactSQLParams(params)
+}
+
+var (
+	_ gormlogger.Interface = (*Logger)(nil)
+	_ gorm.ParamsFilter    = (*Logger)(nil)
+)
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+type account struct {
+	ID           uint
+	Name         string
+	Email        string
+	PasswordHash string
+}
+
+const testHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+// TestLoggerRedactsSQLParams 验证 SQL 日志中的绑定参数按脱敏配置处理。
+func TestLoggerRedactsSQLParams(t *testing.T) {
+	cases := []struct {
+		name      string
+		mode      string
+		wantShown []string
+		wantHide  []string
+	}{
+		{name: "生产模式隐藏字符串参数", mode: "prod", wantShown: []string{applogger.RedactedValue}, wantHide: []string{"alice", "alice@example.com", testHash}},
+		{name: "开发模式仅替换命中规则的参数", mode: "dev", wantShown: []string{`"alice"`, applogger.RedactedValue}, wantHide: []string{"alice@example.com", testHash}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			level := "debug"
+			_, err := applogger.Init(applogger.Config{Mode: tc.mode, Level: &level})
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			applogger.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), slog.LevelDebug)
+
+			db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: NewLogger()})
+			require.NoError(t, err)
+			require.NoError(t, db.AutoMigrate(&account{}))
+			buf.Reset()
+
+			require.NoError(t, db.Create(&account{Name: "alice", Email: "alice@example.com", PasswordHash: testHash}).Error)
+
+			var entry map[string]any
+			line, _, _ := strings.Cut(buf.String(), "\n")
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			sql, _ := entry["sql"].(string)
+			require.Contains(t, sql, "INSERT INTO")
+			for _, shown := range tc.wantShown {
+				require.Contains(t, sql, shown)
+			}
+			for _, hidden := range tc.wantHide {
+				require.NotContains(t, sql, hidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/pkg/observe/metrics"
+)
+
+const metricsStartKey = "metrics:start"
+
+var (
+	queryDuration = metrics.Register(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database operations by table and operation.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"table", "operation"},
+	))
+	queryErrors = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Failed database operations by table and operation, excluding record not found.",
+		},
+		[]string{"table", "operation"},
+	))
+)
+
+// metricsPlugin 通过 GORM 回调记录每次数据库操作的耗时与错误。
+type metricsPlugin struct{}
+
+// NewMetricsPlugin 返回记录数据库操作指标的 GORM 插件，New 会自动为连接启用。
+func NewMetricsPlugin() gorm.Plugin {
+	return metricsPlugin{}
+}
+
+func (metricsPlugin) Name() string {
+	return "metrics"
+}
+
+func (metricsPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+
+	cb := db.Callback()
+	processors := []struct {
+		operation string
+		before    register
+		after     register
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"row", cb.Row().Before("*").Register, cb.Row().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	}
+	for _, p := range processors {
+		if err := p.before("metrics:before_"+p.operation, startTimer); err != nil {
+			return err
+		}
+		if err := p.after("metrics:after_"+p.operation, observe(p.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := statementTable(db)
+		queryDuration.WithLabelValues(table, operation).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrors.WithLabelValues(table, operation).Inc()
+		}
+	}
+}
+
+// statementTable 返回操作涉及的表名；原生 SQL 等无法确定表名时返回 unknown。
+func statementTable(db *gorm.DB) string {
+	if db.Statement != nil && db.Statement.Table != "" {
+		return db.Statement.Table
+	}
+	return "unknown"
+}
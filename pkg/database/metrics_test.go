@@ -0,0 +1,114 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type metricsWidget struct {
+	ID   uint
+	Name string
+}
+
+// histogramCount 返回 collector 中标签与 labels 完全一致的直方图样本数，不存在时返回 0。
+func histogramCount(t *testing.T, collector prometheus.Collector, labels map[string]string) uint64 {
+	t.Helper()
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(collector))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+	metric:
+		for _, m := range family.GetMetric() {
+			if len(m.GetLabel()) != len(labels) {
+				continue
+			}
+			for _, label := range m.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metric
+				}
+			}
+			return m.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func openTestDB(t *testing.T, plugins ...gorm.Plugin) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&metricsWidget{}))
+	for _, plugin := range plugins {
+		require.NoError(t, db.Use(plugin))
+	}
+	return db
+}
+
+// TestMetricsPlugin 验证每类数据库操作按表名与操作类型记录耗时，失败的操作计入错误数，记录不存在不视为错误。
+func TestMetricsPlugin(t *testing.T) {
+	db := openTestDB(t, NewMetricsPlugin())
+
+	cases := []struct {
+		name        string
+		run         func(db *gorm.DB) error
+		table       string
+		operation   string
+		wantErr     error
+		wantCounted bool
+	}{
+		{name: "创建", run: func(db *gorm.DB) error { return db.Create(&metricsWidget{Name: "a"}).Error }, table: "metrics_widgets", operation: "create"},
+		{name: "查询", run: func(db *gorm.DB) error { return db.Find(&[]metricsWidget{}).Error }, table: "metrics_widgets", operation: "query"},
+		{name: "记录不存在不计为错误", run: func(db *gorm.DB) error {
+			return db.First(&metricsWidget{}, "name = ?", "missing").Error
+		}, table: "metrics_widgets", operation: "query", wantErr: gorm.ErrRecordNotFound},
+		{name: "更新", run: func(db *gorm.DB) error {
+			return db.Model(&metricsWidget{}).Where("name = ?", "a").Update("name", "b").Error
+		}, table: "metrics_widgets", operation: "update"},
+		{name: "删除", run: func(db *gorm.DB) error { return db.Where("name = ?", "b").Delete(&metricsWidget{}).Error }, table: "metrics_widgets", operation: "delete"},
+		{name: "单行查询", run: func(db *gorm.DB) error {
+			var count int64
+			return db.Table("metrics_widgets").Select("count(*)").Row().Scan(&count)
+		}, table: "metrics_widgets", operation: "row"},
+		{name: "原生 SQL 无法确定表名", run: func(db *gorm.DB) error { return db.Exec("UPDATE metrics_widgets SET name = name").Error }, table: "unknown", operation: "raw"},
+		{name: "失败的查询", run: func(db *gorm.DB) error { return db.Table("metrics_missing").Find(&[]metricsWidget{}).Error }, table: "metrics_missing", operation: "query", wantCounted: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := map[string]string{"table": tc.table, "operation": tc.operation}
+			observed := histogramCount(t, queryDuration, labels)
+			failed := testutil.ToFloat64(queryErrors.WithLabelValues(tc.table, tc.operation))
+
+			err := tc.run(db)
+			switch {
+			case tc.wantErr != nil:
+				require.ErrorIs(t, err, tc.wantErr)
+			case tc.wantCounted:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, observed+1, histogramCount(t, queryDuration, labels))
+			wantFailed := failed
+			if tc.wantCounted {
+				wantFailed++
+			}
+			require.Equal(t, wantFailed, testutil.ToFloat64(queryErrors.WithLabelValues(tc.table, tc.operation)))
+		})
+	}
+}
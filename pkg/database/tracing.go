@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName     = "github.com/Jayleonc/service/pkg/database"
+	tracingSpanKey = "tracing:span"
+)
+
+// tracingPlugin 为每次数据库操作创建子 Span。Span 只记录带占位符的 SQL，不包含绑定参数。
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewTracingPlugin 返回创建数据库 Span 的 GORM 插件，New 会自动为连接启用；未启用链路追踪时 Span 不会被导出。
+func NewTracingPlugin() gorm.Plugin {
+	return &tracingPlugin{tracer: otel.Tracer(tracerName)}
+}
+
+func (p *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+
+	cb := db.Callback()
+	processors := []struct {
+		operation string
+		before    register
+		after     register
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"row", cb.Row().Before("*").Register, cb.Row().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	}
+	for _, proc := range processors {
+		if err := proc.before("tracing:before_"+proc.operation, p.start(proc.operation)); err != nil {
+			return err
+		}
+		if err := proc.after("tracing:after_"+proc.operation, p.end(proc.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tracingState 记录操作的 Span 与原始上下文，操作结束后恢复上下文，避免后续操作挂在已结束的 Span 下。
+type tracingState struct {
+	span   trace.Span
+	parent context.Context
+}
+
+func (p *tracingPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+		parent := db.Statement.Context
+		ctx, span := p.tracer.Start(parent, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(dbSystem(db), semconv.DBOperation(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, tracingState{span: span, parent: parent})
+	}
+}
+
+func (p *tracingPlugin) end(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(tracingSpanKey)
+		if !ok {
+			return
+		}
+		state, ok := value.(tracingState)
+		if !ok {
+			return
+		}
+		span := state.span
+		defer span.End()
+		db.Statement.Context = state.parent
+
+		table := statementTable(db)
+		span.SetName(operation + " " + table)
+		span.SetAttributes(
+			semconv.DBSQLTable(table),
+			semconv.DBStatement(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		}
+	}
+}
+
+func dbSystem(db *gorm.DB) attribute.KeyValue {
+	switch name := db.Dialector.Name(); name {
+	case "postgres":
+		return semconv.DBSystemPostgreSQL
+	case "mysql":
+		return semconv.DBSystemMySQL
+	case "sqlite":
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemKey.String(name)
+	}
+}
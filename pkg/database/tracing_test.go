@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// recordSpans 将全局 TracerProvider 替换为记录 Span 的实现，测试结束后恢复。插件需在调用之后创建。
+func recordSpans(t *testing.T) (*tracetest.SpanRecorder, trace.Tracer) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder, provider.Tracer("test")
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// TestTracingPlugin 验证每次数据库操作在调用方 Span 下创建一个客户端子 Span，名称与属性包含操作类型和表名，
+// SQL 只记录占位符；失败的操作标记为错误，记录不存在不视为错误。
+func TestTracingPlugin(t *testing.T) {
+	recorder, tracer := recordSpans(t)
+	db := openTestDB(t, NewTracingPlugin())
+	require.NoError(t, db.Create(&metricsWidget{Name: "a"}).Error)
+
+	cases := []struct {
+		name      string
+		run       func(db *gorm.DB) error
+		wantName  string
+		table     string
+		operation string
+		wantErr   error
+		wantFail  bool
+	}{
+		{name: "创建", run: func(db *gorm.DB) error { return db.Create(&metricsWidget{Name: "b"}).Error }, wantName: "create metrics_widgets", table: "metrics_widgets", operation: "create"},
+		{name: "查询", run: func(db *gorm.DB) error {
+			return db.Where("name = ?", "secret").Find(&[]metricsWidget{}).Error
+		}, wantName: "query metrics_widgets", table: "metrics_widgets", operation: "query"},
+		{name: "记录不存在不标记错误", run: func(db *gorm.DB) error {
+			return db.First(&metricsWidget{}, "name = ?", "missing").Error
+		}, wantName: "query metrics_widgets", table: "metrics_widgets", operation: "query", wantErr: gorm.ErrRecordNotFound},
+		{name: "原生 SQL", run: func(db *gorm.DB) error { return db.Exec("UPDATE metrics_widgets SET name = name").Error }, wantName: "raw unknown", table: "unknown", operation: "raw"},
+		{name: "失败的查询", run: func(db *gorm.DB) error {
+			return db.Table("metrics_missing").Find(&[]metricsWidget{}).Error
+		}, wantName: "query metrics_missing", table: "metrics_missing", operation: "query", wantFail: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ended := len(recorder.Ended())
+			ctx, parent := tracer.Start(context.Background(), "parent")
+			err := tc.run(db.WithContext(ctx))
+			parent.End()
+			switch {
+			case tc.wantErr != nil:
+				require.ErrorIs(t, err, tc.wantErr)
+			case tc.wantFail:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+
+			spans := recorder.Ended()[ended:]
+			require.Len(t, spans, 2)
+			span := spans[0]
+			require.Equal(t, tc.wantName, span.Name())
+			require.Equal(t, trace.SpanKindClient, span.SpanKind())
+			require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+			require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+			attrs := spanAttributes(span)
+			require.Equal(t, semconv.DBSystemSqlite.Value, attrs[semconv.DBSystemKey])
+			require.Equal(t, tc.operation, attrs[semconv.DBOperationKey].AsString())
+			require.Equal(t, tc.table, attrs[semconv.DBSQLTableKey].AsString())
+			require.NotContains(t, attrs[semconv.DBStatementKey].AsString(), "secret")
+
+			if tc.wantFail {
+				require.Equal(t, codes.Error, span.Status().Code)
+				require.NotEmpty(t, span.Events())
+			} else {
+				require.Equal(t, codes.Unset, span.Status().Code)
+			}
+		})
+	}
+}
+
+// TestTracingPluginRestoresContext 验证操作结束后恢复调用方上下文，同一链式调用中的后续操作不会挂在已结束的 Span 下。
+func TestTracingPluginRestoresContext(t *testing.T) {
+	recorder, tracer := recordSpans(t)
+	db := openTestDB(t, NewTracingPlugin())
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	var count int64
+	var widgets []metricsWidget
+	require.NoError(t, db.WithContext(ctx).Model(&metricsWidget{}).Count(&count).Find(&widgets).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+}
@@ -0,0 +1,92 @@
+// Package etag 基于记录版本号生成 ETag，并解析 If-Match / If-None-Match 条件请求头，用于乐观并发控制。
+package etag
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// ErrInvalidPrecondition 表示 If-Match 请求头无法解析为版本号，以 400 返回。
+var ErrInvalidPrecondition = xerr.ErrBadRequest.WithMessage("invalid If-Match header")
+
+// Format 将版本号格式化为强 ETag，例如 "3"。
+func Format(version int64) string {
+	return Tag(version, "")
+}
+
+// Tag 将版本号与摘要格式化为强 ETag，例如 "3-1a2b3c4d"。摘要用于区分版本号相同、但由其他表维护的
+// 关联数据（如用户的角色）不同的表示；If-Match 只比较其中的版本号。digest 为空时等同于 Format。
+func Tag(version int64, digest string) string {
+	value := strconv.FormatInt(version, 10)
+	if digest != "" {
+		value += "-" + digest
+	}
+	return `"` + value + `"`
+}
+
+// Set 在响应中写入版本号对应的 ETag。
+func Set(c *gin.Context, version int64) {
+	c.Header("ETag", Format(version))
+}
+
+// SetTag 在响应中写入由 Tag 生成的 ETag。
+func SetTag(c *gin.Context, tag string) {
+	c.Header("ETag", tag)
+}
+
+// NotModified 写入 tag 作为 ETag，并在 If-None-Match 包含该 ETag（忽略弱标记）时返回 304，调用方应直接结束处理。
+func NotModified(c *gin.Context, tag string) bool {
+	SetTag(c, tag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// Expected 返回更新请求期望的版本号：优先使用 If-Match 请求头，其次使用请求体中的 version 字段。
+// fromHeader 表示版本号来自 If-Match，此时版本冲突应返回 412（见 Conflict），否则返回 409。
+// 两者都未提供（或 If-Match 为 *）时返回 0，表示不校验客户端持有的版本。
+func Expected(c *gin.Context, bodyVersion int64) (version int64, fromHeader bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return bodyVersion, false, nil
+	}
+	v, ok := parse(header)
+	if !ok {
+		return 0, false, ErrInvalidPrecondition
+	}
+	return v, true, nil
+}
+
+// Conflict 在版本号来自 If-Match 时把 err 中的版本冲突错误 conflict 改为以 412 返回；
+// 其余情况原样返回 err，版本冲突按 conflict 自身的状态码（通常为 409）返回。
+func Conflict(err error, conflict *xerr.Error, fromHeader bool) error {
+	var business *xerr.Error
+	if !fromHeader || !errors.As(err, &business) || !errors.Is(business, conflict) {
+		return err
+	}
+	return business.WithStatus(http.StatusPreconditionFailed)
+}
+
+// parse 解析 "3"、"3-1a2b3c4d" 或对应弱 ETag 形式的版本号。
+func parse(value string) (int64, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+	version, _, _ := strings.Cut(value[1:len(value)-1], "-")
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
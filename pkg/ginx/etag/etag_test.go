@@ -0,0 +1,68 @@
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+var errConflict = xerr.New(9901, "version conflict").WithStatus(http.StatusConflict)
+
+// TestConflict 验证版本冲突的状态码随期望版本的来源变化，其他错误保持原样。
+func TestConflict(t *testing.T) {
+	other := errors.New("db down")
+
+	cases := []struct {
+		name       string
+		err        error
+		fromHeader bool
+		wantStatus int
+		wantSame   bool
+	}{
+		{name: "版本来自 If-Match 返回 412", err: errConflict, fromHeader: true, wantStatus: http.StatusPreconditionFailed},
+		{name: "包装后的版本冲突同样识别", err: fmt.Errorf("update: %w", errConflict), fromHeader: true, wantStatus: http.StatusPreconditionFailed},
+		{name: "版本来自请求体保持 409", err: errConflict, wantStatus: http.StatusConflict, wantSame: true},
+		{name: "其他业务错误保持原样", err: xerr.ErrNotFound, fromHeader: true, wantStatus: http.StatusNotFound, wantSame: true},
+		{name: "非业务错误保持原样", err: other, fromHeader: true, wantStatus: http.StatusInternalServerError, wantSame: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Conflict(tc.err, errConflict, tc.fromHeader)
+			if tc.wantSame {
+				require.Equal(t, tc.err, got)
+			}
+			require.Equal(t, tc.wantStatus, xerr.From(got).HTTPStatus())
+		})
+	}
+}
+
+// TestParse 验证 If-Match 中的 ETag 只取版本号，带摘要与弱标记的形式同样可以解析。
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "版本号", value: `"3"`, want: 3},
+		{name: "弱 ETag", value: ` W/"3" `, want: 3},
+		{name: "带摘要", value: Tag(3, "1a2b3c4d"), want: 3},
+		{name: "缺少引号", value: "3", wantErr: true},
+		{name: "非数字版本", value: `"abc-1"`, wantErr: true},
+		{name: "非正数版本", value: `"0"`, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parse(tc.value)
+			require.Equal(t, !tc.wantErr, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Package filter 根据每个列表接口声明的字段白名单，将客户端提交的过滤与排序条件安全地转换为 gorm scope。
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Jayleonc/service/pkg/ginx/request"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// Op 表示过滤操作符。
+type Op string
+
+// 支持的过滤操作符。
+//
+//	eq      value 为单个值
+//	in      value 为数组，最多 maxInValues 个元素
+//	like    value 为字符串，按包含匹配，其中的 % 与 _ 按字面匹配
+//	range   value 为 {"from": x, "to": y}，两端闭区间，可省略其中一端
+//	is_null value 为布尔值，true 表示 IS NULL，false 表示 IS NOT NULL；省略时为 true
+const (
+	OpEq     Op = "eq"
+	OpIn     Op = "in"
+	OpLike   Op = "like"
+	OpRange  Op = "range"
+	OpIsNull Op = "is_null"
+)
+
+// maxInValues 限制 in 操作符的元素数量。
+const maxInValues = 100
+
+// likeEscape 是 like 模式中的转义字符。选用 "!" 而不是反斜杠，避免各数据库对字符串字面量中反斜杠的不同处理。
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// EscapeLike 转义 s 中的 LIKE 通配符，结果需要与 ESCAPE '!' 一起使用，见 Contains。
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Contains 返回列值包含 value 的条件，value 中的 % 与 _ 按字面匹配。
+func Contains(column clause.Column, value string) clause.Expression {
+	return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []any{column, "%" + EscapeLike(value) + "%"}}
+}
+
+// Type 描述字段的值类型，用于把 JSON 值还原为与数据库列匹配的 Go 类型。
+type Type int
+
+// 支持的字段类型。
+const (
+	String Type = iota
+	Int
+	Bool
+	Time
+	UUID
+)
+
+// Field 声明一个可对外暴露的字段。
+type Field struct {
+	// Column 为数据库列名。
+	Column string
+	Type   Type
+	// Ops 为允许的过滤操作符，为空时该字段不可过滤。
+	Ops []Op
+	// Sortable 表示字段可用于排序。
+	Sortable bool
+}
+
+// Schema 以客户端字段名（如 createdAt）为键声明列表接口可过滤、可排序的字段。
+type Schema map[string]Field
+
+// Error 描述被拒绝的过滤或排序条件，可直接序列化返回给客户端。
+// errors.As（进而 xerr.From）会把它转换为 400 的 xerr.ErrBadRequest，并以自身作为响应详情。
+type Error struct {
+	Field  string `json:"field"`
+	Op     string `json:"op,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Error 实现 error 接口。
+func (e *Error) Error() string {
+	if e.Op != "" {
+		return fmt.Sprintf("invalid filter %q on field %q: %s", e.Op, e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid field %q: %s", e.Field, e.Reason)
+}
+
+// As 支持 errors.As 将过滤错误转换为 *xerr.Error。
+func (e *Error) As(target any) bool {
+	business, ok := target.(**xerr.Error)
+	if ok {
+		*business = xerr.ErrBadRequest.WithMessage(e.Error()).WithData(e)
+	}
+	return ok
+}
+
+type rangeValue struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// Scope 校验过滤条件并返回对应的 gorm scope，未声明的字段或操作符返回 *Error。
+func (s Schema) Scope(filters []request.Filter) (func(*gorm.DB) *gorm.DB, error) {
+	exprs := make([]clause.Expression, 0, len(filters))
+	for _, f := range filters {
+		expr, err := s.condition(f)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if len(exprs) == 0 {
+			return db
+		}
+		return db.Clauses(clause.Where{Exprs: exprs})
+	}, nil
+}
+
+// OrderBy 校验形如 "createdAt desc, name" 的排序表达式，返回以数据库列名表示的排序，
+// 可直接作为 request.Pagination.OrderBy 交给 paginator.Paginate；不可排序的字段返回 *Error。
+func (s Schema) OrderBy(orderBy string) (string, error) {
+	var columns []string
+	for _, item := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 {
+			continue
+		}
+
+		field, ok := s[parts[0]]
+		if !ok || !field.Sortable {
+			return "", &Error{Field: parts[0], Reason: "field is not sortable"}
+		}
+		if len(parts) > 2 {
+			return "", &Error{Field: parts[0], Reason: "invalid sort expression"}
+		}
+
+		direction := "asc"
+		if len(parts) == 2 {
+			direction = strings.ToLower(parts[1])
+			if direction != "asc" && direction != "desc" {
+				return "", &Error{Field: parts[0], Reason: "sort direction must be asc or desc"}
+			}
+		}
+		columns = append(columns, field.Column+" "+direction)
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// SortColumns 返回可排序字段的数据库列名，作为 paginator.Paginate 的排序白名单。
+func (s Schema) SortColumns() []string {
+	columns := make([]string, 0, len(s))
+	for _, field := range s {
+		if field.Sortable {
+			columns = append(columns, field.Column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func (s Schema) condition(f request.Filter) (clause.Expression, error) {
+	field, ok := s[f.Field]
+	if !ok {
+		return nil, &Error{Field: f.Field, Op: f.Op, Reason: "unknown field"}
+	}
+
+	op := Op(strings.ToLower(strings.TrimSpace(f.Op)))
+	if op == "" {
+		op = OpEq
+	}
+	allowed := false
+	for _, candidate := range field.Ops {
+		if candidate == op {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, &Error{Field: f.Field, Op: string(op), Reason: "operator not allowed"}
+	}
+
+	invalid := func(reason string) error {
+		return &Error{Field: f.Field, Op: string(op), Reason: reason}
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: field.Column}
+
+	switch op {
+	case OpEq:
+		value, err := field.Type.decode(f.Value)
+		if err != nil || value == nil {
+			return nil, invalid("invalid value")
+		}
+		return clause.Eq{Column: column, Value: value}, nil
+
+	case OpIn:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(f.Value, &raws); err != nil || len(raws) == 0 {
+			return nil, invalid("value must be a non-empty array")
+		}
+		if len(raws) > maxInValues {
+			return nil, invalid(fmt.Sprintf("at most %d values are allowed", maxInValues))
+		}
+		values := make([]any, 0, len(raws))
+		for _, raw := range raws {
+			value, err := field.Type.decode(raw)
+			if err != nil || value == nil {
+				return nil, invalid("invalid value")
+			}
+			values = append(values, value)
+		}
+		return clause.IN{Column: column, Values: values}, nil
+
+	case OpLike:
+		var value string
+		if err := json.Unmarshal(f.Value, &value); err != nil || value == "" {
+			return nil, invalid("value must be a non-empty string")
+		}
+		return Contains(column, value), nil
+
+	case OpRange:
+		var bounds rangeValue
+		if err := json.Unmarshal(f.Value, &bounds); err != nil {
+			return nil, invalid(`value must be {"from": x, "to": y}`)
+		}
+		from, err := field.Type.decode(bounds.From)
+		if err != nil {
+			return nil, invalid("invalid from value")
+		}
+		to, err := field.Type.decode(bounds.To)
+		if err != nil {
+			return nil, invalid("invalid to value")
+		}
+		switch {
+		case from != nil && to != nil:
+			return clause.And(clause.Gte{Column: column, Value: from}, clause.Lte{Column: column, Value: to}), nil
+		case from != nil:
+			return clause.Gte{Column: column, Value: from}, nil
+		case to != nil:
+			return clause.Lte{Column: column, Value: to}, nil
+		default:
+			return nil, invalid("from or to is required")
+		}
+
+	default: // OpIsNull
+		isNull := true
+		if !isJSONNull(f.Value) {
+			if err := json.Unmarshal(f.Value, &isNull); err != nil {
+				return nil, invalid("value must be a boolean")
+			}
+		}
+		if isNull {
+			return clause.Expr{SQL: "? IS NULL", Vars: []any{column}}, nil
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{column}}, nil
+	}
+}
+
+// decode 将 JSON 值解析为字段类型对应的 Go 值，JSON null 或缺省返回 nil。
+func (t Type) decode(raw json.RawMessage) (any, error) {
+	if isJSONNull(raw) {
+		return nil, nil
+	}
+
+	var target any
+	switch t {
+	case Int:
+		target = new(int64)
+	case Bool:
+		target = new(bool)
+	case Time:
+		target = new(time.Time)
+	case UUID:
+		target = new(uuid.UUID)
+	default:
+		target = new(string)
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return nil, err
+	}
+
+	switch v := target.(type) {
+	case *int64:
+		return *v, nil
+	case *bool:
+		return *v, nil
+	case *time.Time:
+		return *v, nil
+	case *uuid.UUID:
+		return *v, nil
+	default:
+		return *target.(*string), nil
+	}
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
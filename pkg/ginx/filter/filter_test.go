@@ -0,0 +1,198 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/pkg/ginx/request"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+type item struct {
+	ID   int
+	Name string
+	Age  int
+	Note *string
+}
+
+var testSchema = Schema{
+	"name": {Column: "name", Type: String, Ops: []Op{OpEq, OpIn, OpLike}, Sortable: true},
+	"age":  {Column: "age", Type: Int, Ops: []Op{OpEq, OpIn, OpRange}, Sortable: true},
+	"note": {Column: "note", Type: String, Ops: []Op{OpIsNull}},
+}
+
+func newItemDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&item{}))
+
+	note := "vip"
+	items := []item{
+		{ID: 1, Name: "alice", Age: 20, Note: &note},
+		{ID: 2, Name: "bob", Age: 30},
+		{ID: 3, Name: "100%_off", Age: 40},
+		{ID: 4, Name: "100 dollars off", Age: 50},
+		{ID: 5, Name: "a!b", Age: 60},
+	}
+	require.NoError(t, db.Create(&items).Error)
+	return db
+}
+
+func filterOf(field, op, value string) request.Filter {
+	f := request.Filter{Field: field, Op: op}
+	if value != "" {
+		f.Value = json.RawMessage(value)
+	}
+	return f
+}
+
+// TestSchemaScope 验证各操作符的值解析与查询结果。
+func TestSchemaScope(t *testing.T) {
+	db := newItemDB(t)
+
+	cases := []struct {
+		name    string
+		filters []request.Filter
+		wantIDs []int
+	}{
+		{name: "无过滤条件", wantIDs: []int{1, 2, 3, 4, 5}},
+		{name: "省略操作符按 eq 处理", filters: []request.Filter{filterOf("name", "", `"bob"`)}, wantIDs: []int{2}},
+		{name: "in 按字段类型解析数组", filters: []request.Filter{filterOf("age", "in", `[20, 40]`)}, wantIDs: []int{1, 3}},
+		{name: "range 同时指定两端", filters: []request.Filter{filterOf("age", "range", `{"from": 30, "to": 50}`)}, wantIDs: []int{2, 3, 4}},
+		{name: "range 只指定 from", filters: []request.Filter{filterOf("age", "range", `{"from": 50}`)}, wantIDs: []int{4, 5}},
+		{name: "range 只指定 to", filters: []request.Filter{filterOf("age", "range", `{"to": 20}`)}, wantIDs: []int{1}},
+		{name: "is_null 省略值表示 IS NULL", filters: []request.Filter{filterOf("note", "is_null", "")}, wantIDs: []int{2, 3, 4, 5}},
+		{name: "is_null 为 false 表示 IS NOT NULL", filters: []request.Filter{filterOf("note", "is_null", `false`)}, wantIDs: []int{1}},
+		{name: "操作符大小写不敏感", filters: []request.Filter{filterOf("name", " LIKE ", `"li"`)}, wantIDs: []int{1}},
+		{name: "like 中的百分号按字面匹配", filters: []request.Filter{filterOf("name", "like", `"0%"`)}, wantIDs: []int{3}},
+		{name: "like 中的下划线按字面匹配", filters: []request.Filter{filterOf("name", "like", `"%_"`)}, wantIDs: []int{3}},
+		{name: "like 中的转义字符按字面匹配", filters: []request.Filter{filterOf("name", "like", `"!"`)}, wantIDs: []int{5}},
+		{name: "多个条件取交集", filters: []request.Filter{filterOf("name", "like", `"o"`), filterOf("age", "range", `{"from": 40}`)}, wantIDs: []int{3, 4}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scope, err := testSchema.Scope(tc.filters)
+			require.NoError(t, err)
+
+			var ids []int
+			require.NoError(t, db.Model(&item{}).Scopes(scope).Order("id").Pluck("id", &ids).Error)
+			require.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
+
+// TestSchemaScopeRejects 验证未声明的字段、操作符与非法值返回 *Error，并被识别为 400。
+func TestSchemaScopeRejects(t *testing.T) {
+	tooMany := "[" + strings.TrimSuffix(strings.Repeat("1,", maxInValues+1), ",") + "]"
+	atLimit := "[" + strings.TrimSuffix(strings.Repeat("1,", maxInValues), ",") + "]"
+
+	cases := []struct {
+		name       string
+		filter     request.Filter
+		wantReason string
+	}{
+		{name: "未声明的字段", filter: filterOf("password", "eq", `"x"`), wantReason: "unknown field"},
+		{name: "不支持的操作符", filter: filterOf("name", "range", `{"from": "a"}`), wantReason: "operator not allowed"},
+		{name: "未知操作符", filter: filterOf("name", "regexp", `".*"`), wantReason: "operator not allowed"},
+		{name: "eq 的值类型不匹配", filter: filterOf("age", "eq", `"twenty"`), wantReason: "invalid value"},
+		{name: "eq 缺少值", filter: filterOf("name", "eq", ""), wantReason: "invalid value"},
+		{name: "in 的值不是数组", filter: filterOf("age", "in", `20`), wantReason: "value must be a non-empty array"},
+		{name: "in 的数组为空", filter: filterOf("age", "in", `[]`), wantReason: "value must be a non-empty array"},
+		{name: "in 的元素类型不匹配", filter: filterOf("age", "in", `[1, "two"]`), wantReason: "invalid value"},
+		{name: "in 的元素超过上限", filter: filterOf("age", "in", tooMany), wantReason: fmt.Sprintf("at most %d values are allowed", maxInValues)},
+		{name: "like 的值为空字符串", filter: filterOf("name", "like", `""`), wantReason: "value must be a non-empty string"},
+		{name: "range 两端都缺省", filter: filterOf("age", "range", `{}`), wantReason: "from or to is required"},
+		{name: "range 的值不是对象", filter: filterOf("age", "range", `[1, 2]`), wantReason: `value must be {"from": x, "to": y}`},
+		{name: "range 的 from 类型不匹配", filter: filterOf("age", "range", `{"from": "x"}`), wantReason: "invalid from value"},
+		{name: "is_null 的值不是布尔值", filter: filterOf("note", "is_null", `"yes"`), wantReason: "value must be a boolean"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := testSchema.Scope([]request.Filter{tc.filter})
+
+			var filterErr *Error
+			require.ErrorAs(t, err, &filterErr)
+			require.Equal(t, tc.filter.Field, filterErr.Field)
+			require.Equal(t, tc.wantReason, filterErr.Reason)
+
+			business := xerr.From(err)
+			require.True(t, errors.Is(business, xerr.ErrBadRequest))
+			require.Equal(t, filterErr, business.Data())
+		})
+	}
+
+	_, err := testSchema.Scope([]request.Filter{filterOf("age", "in", atLimit)})
+	require.NoError(t, err, "恰好达到上限的 in 条件应被接受")
+}
+
+// TestSchemaOrderBy 验证排序表达式只接受可排序字段，并转换为数据库列名。
+func TestSchemaOrderBy(t *testing.T) {
+	cases := []struct {
+		name       string
+		orderBy    string
+		want       string
+		wantReason string
+	}{
+		{name: "空表达式", orderBy: "", want: ""},
+		{name: "默认升序", orderBy: "name", want: "name asc"},
+		{name: "多个字段与大小写方向", orderBy: "age DESC, name", want: "age desc, name asc"},
+		{name: "忽略空项", orderBy: "name,, age", want: "name asc, age asc"},
+		{name: "未声明的字段", orderBy: "password", wantReason: "field is not sortable"},
+		{name: "声明但不可排序的字段", orderBy: "note", wantReason: "field is not sortable"},
+		{name: "注入语句", orderBy: "name; drop table items", wantReason: "field is not sortable"},
+		{name: "方向后附加额外内容", orderBy: "name desc nulls", wantReason: "invalid sort expression"},
+		{name: "非法方向", orderBy: "name sideways", wantReason: "sort direction must be asc or desc"},
+		{name: "子查询", orderBy: "(select 1)", wantReason: "field is not sortable"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := testSchema.OrderBy(tc.orderBy)
+			if tc.wantReason == "" {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+				return
+			}
+
+			var filterErr *Error
+			require.ErrorAs(t, err, &filterErr)
+			require.Equal(t, tc.wantReason, filterErr.Reason)
+		})
+	}
+}
+
+// TestSchemaSortColumns 验证排序白名单只包含可排序字段的列名。
+func TestSchemaSortColumns(t *testing.T) {
+	require.Equal(t, []string{"age", "name"}, testSchema.SortColumns())
+}
+
+// TestEscapeLike 验证 LIKE 通配符与转义字符本身都被转义。
+func TestEscapeLike(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "普通字符串", in: "alice", want: "alice"},
+		{name: "百分号", in: "100%", want: "100!%"},
+		{name: "下划线", in: "a_b", want: "a!_b"},
+		{name: "转义字符", in: "a!b", want: "a!!b"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, EscapeLike(tc.in))
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package paginator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/Jayleonc/service/pkg/ginx/request"
+	"github.com/Jayleonc/service/pkg/ginx/response"
+	"github.com/Jayleonc/service/pkg/utils"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+var (
+	// ErrInvalidCursor 表示游标无法解析，或与本次请求的排序条件不一致。
+	ErrInvalidCursor error = badRequestError("invalid cursor")
+	// ErrInvalidSortKey 表示排序表达式不合法，或排序字段不在允许列表中。
+	ErrInvalidSortKey error = badRequestError("invalid sort key")
+)
+
+// badRequestError 是分页参数错误的类型。各个取值可用 errors.Is 区分，
+// 同时 errors.As（进而 xerr.From）会把它们转换为 400 的 xerr.ErrBadRequest。
+type badRequestError string
+
+func (e badRequestError) Error() string {
+	return string(e)
+}
+
+// As 支持 errors.As 将分页参数错误转换为 *xerr.Error。
+func (e badRequestError) As(target any) bool {
+	business, ok := target.(**xerr.Error)
+	if ok {
+		*business = xerr.ErrBadRequest.WithMessage(string(e))
+	}
+	return ok
+}
+
+// cursorToken 是游标编码前的内容，记录上一页最后一行的排序值与主键。
+type cursorToken struct {
+	SortKey string          `json:"s"`
+	Desc    bool            `json:"d,omitempty"`
+	Value   json.RawMessage `json:"v,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// CursorPaginate 执行游标分页查询：按 (排序字段, 主键) 做 keyset 比较，不使用 OFFSET，
+// 也只在 req.WithTotal 时执行 COUNT。主键为 UUIDv7 时默认按主键排序即为按创建时间排序。
+//
+// sortKeys 列出允许客户端指定的排序列（数据库列名），主键始终允许；排序列应为非空列。
+func CursorPaginate[T any](db *gorm.DB, req *request.CursorPagination, sortKeys ...string) (*response.CursorPageResult[T], error) {
+	if db == nil {
+		return nil, gorm.ErrInvalidDB
+	}
+	if req == nil {
+		req = &request.CursorPagination{}
+	}
+
+	pageSize := defaultPageSize
+	if req.PageSize > 0 {
+		pageSize = min(req.PageSize, maxPageSize)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, gorm.ErrPrimaryKeyRequired
+	}
+
+	sortField := pk
+	if sortBy := utils.CamelToSnake(strings.TrimSpace(req.SortBy)); sortBy != "" && sortBy != pk.DBName {
+		if !slices.Contains(sortKeys, sortBy) {
+			return nil, ErrInvalidSortKey
+		}
+		if sortField = stmt.Schema.LookUpField(sortBy); sortField == nil {
+			return nil, ErrInvalidSortKey
+		}
+	}
+
+	query := db.Session(&gorm.Session{})
+
+	var total *int64
+	if req.WithTotal {
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	sortColumn := clause.Column{Table: clause.CurrentTable, Name: sortField.DBName}
+	pkColumn := clause.Column{Table: clause.CurrentTable, Name: pk.DBName}
+
+	if req.Cursor != "" {
+		value, id, err := decodeCursor(req.Cursor, sortField, pk, req.Desc)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if req.Desc {
+			op = "<"
+		}
+		if sortField == pk {
+			query = query.Where(clause.Expr{SQL: "? " + op + " ?", Vars: []any{pkColumn, id}})
+		} else {
+			query = query.Where(clause.Expr{
+				SQL:  "(? " + op + " ? OR (? = ? AND ? " + op + " ?))",
+				Vars: []any{sortColumn, value, sortColumn, value, pkColumn, id},
+			})
+		}
+	}
+
+	if sortField != pk {
+		query = query.Order(clause.OrderByColumn{Column: sortColumn, Desc: req.Desc})
+	}
+	query = query.Order(clause.OrderByColumn{Column: pkColumn, Desc: req.Desc})
+
+	var items []T
+	if err := query.Limit(pageSize + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	result := &response.CursorPageResult[T]{Total: total, PageSize: pageSize}
+	if len(items) > pageSize {
+		items = items[:pageSize]
+		result.HasMore = true
+
+		last := reflect.ValueOf(&items[len(items)-1]).Elem()
+		cursor, err := encodeCursor(query.Statement.Context, last, sortField, pk, req.Desc)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = cursor
+	}
+	if items == nil {
+		items = []T{}
+	}
+	result.List = items
+	return result, nil
+}
+
+func encodeCursor(ctx context.Context, row reflect.Value, sortField, pk *schema.Field, desc bool) (string, error) {
+	id, _ := pk.ValueOf(ctx, row)
+	token := cursorToken{SortKey: sortField.DBName, Desc: desc}
+
+	var err error
+	if token.ID, err = json.Marshal(id); err != nil {
+		return "", err
+	}
+	if sortField != pk {
+		value, _ := sortField.ValueOf(ctx, row)
+		if token.Value, err = json.Marshal(value); err != nil {
+			return "", err
+		}
+	}
+
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor 解析游标，并将排序值与主键还原为字段的 Go 类型，保证与数据库列按原类型比较。
+func decodeCursor(cursor string, sortField, pk *schema.Field, desc bool) (value, id any, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	if token.SortKey != sortField.DBName || token.Desc != desc {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	if id, err = decodeFieldValue(token.ID, pk); err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	if sortField != pk {
+		if value, err = decodeFieldValue(token.Value, sortField); err != nil {
+			return nil, nil, ErrInvalidCursor
+		}
+	}
+	return value, id, nil
+}
+
+func decodeFieldValue(data json.RawMessage, field *schema.Field) (any, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	ptr := reflect.New(field.FieldType)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
@@ -0,0 +1,195 @@
+package paginator
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/Jayleonc/service/pkg/ginx/request"
+)
+
+type cursorItem struct {
+	ID    int
+	Score int
+}
+
+// cursorScores 中的分数有重复，用于验证排序值相同的行按主键继续排序。
+var cursorScores = []int{2, 1, 3, 1, 2, 1, 3}
+
+func newCursorDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&cursorItem{}))
+	for i, score := range cursorScores {
+		require.NoError(t, db.Create(&cursorItem{ID: i + 1, Score: score}).Error)
+	}
+	return db
+}
+
+func parseFields(t *testing.T, db *gorm.DB, model any, sortKey string) (sortField, pk *schema.Field) {
+	t.Helper()
+
+	stmt := &gorm.Statement{DB: db}
+	require.NoError(t, stmt.Parse(model))
+	pk = stmt.Schema.PrioritizedPrimaryField
+	return stmt.Schema.LookUpField(sortKey), pk
+}
+
+// collectPages 从首页开始按 nextCursor 翻完所有页，返回依次读到的主键。
+func collectPages(t *testing.T, db *gorm.DB, req request.CursorPagination) []int {
+	t.Helper()
+
+	var ids []int
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, len(cursorScores), "翻页没有终止")
+
+		result, err := CursorPaginate[cursorItem](db.Model(&cursorItem{}), &req, "score")
+		require.NoError(t, err)
+		for _, row := range result.List {
+			ids = append(ids, row.ID)
+		}
+		if !result.HasMore {
+			require.Empty(t, result.NextCursor)
+			return ids
+		}
+		require.NotEmpty(t, result.NextCursor)
+		req.Cursor = result.NextCursor
+	}
+}
+
+// TestCursorPaginateOrder 验证升序与降序翻页，排序值相同时按主键兜底，不跳过也不重复任何行。
+func TestCursorPaginateOrder(t *testing.T) {
+	db := newCursorDB(t)
+
+	cases := []struct {
+		name    string
+		req     request.CursorPagination
+		wantIDs []int
+	}{
+		{name: "默认按主键升序", req: request.CursorPagination{PageSize: 3}, wantIDs: []int{1, 2, 3, 4, 5, 6, 7}},
+		{name: "按主键降序", req: request.CursorPagination{PageSize: 3, Desc: true}, wantIDs: []int{7, 6, 5, 4, 3, 2, 1}},
+		{name: "显式指定主键排序", req: request.CursorPagination{PageSize: 4, SortBy: "id"}, wantIDs: []int{1, 2, 3, 4, 5, 6, 7}},
+		{name: "按非唯一列升序", req: request.CursorPagination{PageSize: 2, SortBy: "score"}, wantIDs: []int{2, 4, 6, 1, 5, 3, 7}},
+		{name: "按非唯一列降序", req: request.CursorPagination{PageSize: 2, SortBy: "score", Desc: true}, wantIDs: []int{7, 3, 5, 1, 6, 4, 2}},
+		{name: "每页一行", req: request.CursorPagination{PageSize: 1, SortBy: "score"}, wantIDs: []int{2, 4, 6, 1, 5, 3, 7}},
+		{name: "单页取完", req: request.CursorPagination{PageSize: 10, SortBy: "score"}, wantIDs: []int{2, 4, 6, 1, 5, 3, 7}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantIDs, collectPages(t, db, tc.req))
+		})
+	}
+}
+
+// TestCursorPaginateTotal 验证只有请求 withTotal 时才返回总数。
+func TestCursorPaginateTotal(t *testing.T) {
+	db := newCursorDB(t)
+
+	result, err := CursorPaginate[cursorItem](db.Model(&cursorItem{}), &request.CursorPagination{PageSize: 2})
+	require.NoError(t, err)
+	require.Nil(t, result.Total)
+
+	result, err = CursorPaginate[cursorItem](db.Model(&cursorItem{}).Where("score = ?", 1), &request.CursorPagination{PageSize: 2, WithTotal: true})
+	require.NoError(t, err)
+	require.NotNil(t, result.Total)
+	require.EqualValues(t, 3, *result.Total)
+	require.True(t, result.HasMore)
+}
+
+// TestCursorPaginateRejects 验证被篡改的游标、与本次排序不一致的游标以及白名单外的排序列。
+func TestCursorPaginateRejects(t *testing.T) {
+	db := newCursorDB(t)
+
+	first, err := CursorPaginate[cursorItem](db.Model(&cursorItem{}), &request.CursorPagination{PageSize: 2, SortBy: "score"}, "score")
+	require.NoError(t, err)
+	byScore := first.NextCursor
+
+	first, err = CursorPaginate[cursorItem](db.Model(&cursorItem{}), &request.CursorPagination{PageSize: 2}, "score")
+	require.NoError(t, err)
+	byID := first.NextCursor
+
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	cases := []struct {
+		name    string
+		req     request.CursorPagination
+		wantErr error
+	}{
+		{name: "不是 base64", req: request.CursorPagination{Cursor: "!!!", SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "不是 JSON", req: request.CursorPagination{Cursor: encode("not json"), SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "主键类型不匹配", req: request.CursorPagination{Cursor: encode(`{"s":"score","v":1,"id":"x"}`), SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "缺少排序值", req: request.CursorPagination{Cursor: encode(`{"s":"score","id":1}`), SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "缺少主键", req: request.CursorPagination{Cursor: encode(`{"s":"score","v":1}`), SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "截断的游标", req: request.CursorPagination{Cursor: byScore[:len(byScore)-2], SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "游标按其他列生成", req: request.CursorPagination{Cursor: byScore}, wantErr: ErrInvalidCursor},
+		{name: "游标按主键生成却按其他列翻页", req: request.CursorPagination{Cursor: byID, SortBy: "score"}, wantErr: ErrInvalidCursor},
+		{name: "游标的排序方向不一致", req: request.CursorPagination{Cursor: byScore, SortBy: "score", Desc: true}, wantErr: ErrInvalidCursor},
+		{name: "排序列不在白名单内", req: request.CursorPagination{SortBy: "name"}, wantErr: ErrInvalidSortKey},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := CursorPaginate[cursorItem](db.Model(&cursorItem{}), &tc.req, "score")
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+type cursorRecord struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+}
+
+// TestCursorRoundTrip 验证游标编码后能还原为字段原本的 Go 类型。
+func TestCursorRoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	record := cursorRecord{ID: uuid.Must(uuid.NewV7()), CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)}
+	row := reflect.ValueOf(&record).Elem()
+
+	cases := []struct {
+		name      string
+		sortKey   string
+		desc      bool
+		wantValue any
+	}{
+		{name: "按主键", sortKey: "id"},
+		{name: "按时间列升序", sortKey: "created_at", wantValue: record.CreatedAt},
+		{name: "按时间列降序", sortKey: "created_at", desc: true, wantValue: record.CreatedAt},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sortField, pk := parseFields(t, db, &cursorRecord{}, tc.sortKey)
+
+			cursor, err := encodeCursor(context.Background(), row, sortField, pk, tc.desc)
+			require.NoError(t, err)
+
+			value, id, err := decodeCursor(cursor, sortField, pk, tc.desc)
+			require.NoError(t, err)
+			require.Equal(t, record.ID, id)
+			if tc.wantValue == nil {
+				require.Nil(t, value)
+			} else {
+				require.True(t, tc.wantValue.(time.Time).Equal(value.(time.Time)))
+			}
+
+			_, _, err = decodeCursor(cursor, sortField, pk, !tc.desc)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package paginator
 
 import (
+	"regexp"
+	"slices"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Jayleonc/service/pkg/ginx/request"
 	"github.com/Jayleonc/service/pkg/ginx/response"
@@ -16,8 +19,11 @@ const (
 	maxPageSize     = 100
 )
 
-// Paginate 执行标准分页查询，返回统一分页结果
-func Paginate[T any](db *gorm.DB, req *request.Pagination) (*response.PageResult[T], error) {
+// Paginate 执行标准分页查询，返回统一分页结果。
+//
+// sortKeys 列出允许出现在 req.OrderBy 中的列（数据库列名），未提供时任何排序都返回 ErrInvalidSortKey。
+// 按客户端字段排序的接口通常先用 filter.Schema.OrderBy 转换排序表达式，再传入 Schema.SortColumns()。
+func Paginate[T any](db *gorm.DB, req *request.Pagination, sortKeys ...string) (*response.PageResult[T], error) {
 	if db == nil {
 		return nil, gorm.ErrInvalidDB
 	}
@@ -42,6 +48,11 @@ func Paginate[T any](db *gorm.DB, req *request.Pagination) (*response.PageResult
 		pageSize = maxPageSize
 	}
 
+	orders, err := orderColumns(orderBy, sortKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	query := db.Session(&gorm.Session{})
 
 	var total int64
@@ -54,8 +65,8 @@ func Paginate[T any](db *gorm.DB, req *request.Pagination) (*response.PageResult
 		offset = 0
 	}
 
-	if orderBy != "" {
-		query = query.Order(utils.CamelToSnake(orderBy))
+	if len(orders) > 0 {
+		query = query.Order(clause.OrderBy{Columns: orders})
 	}
 
 	var items []T
@@ -70,3 +81,42 @@ func Paginate[T any](db *gorm.DB, req *request.Pagination) (*response.PageResult
 		PageSize: pageSize,
 	}, nil
 }
+
+// sortIdentifier 限定 OrderBy 中的字段名只能是普通标识符，避免拼接任意 SQL。
+var sortIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// orderColumns 解析形如 "createdAt desc, name" 的排序表达式，字段名转换为下划线列名，
+// 且必须出现在 sortKeys 中。
+func orderColumns(orderBy string, sortKeys []string) ([]clause.OrderByColumn, error) {
+	var columns []clause.OrderByColumn
+	for _, item := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) > 2 || !sortIdentifier.MatchString(parts[0]) {
+			return nil, ErrInvalidSortKey
+		}
+
+		desc := false
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+			case "desc":
+				desc = true
+			default:
+				return nil, ErrInvalidSortKey
+			}
+		}
+
+		column := utils.CamelToSnake(parts[0])
+		if !slices.Contains(sortKeys, column) {
+			return nil, ErrInvalidSortKey
+		}
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: column},
+			Desc:   desc,
+		})
+	}
+	return columns, nil
+}
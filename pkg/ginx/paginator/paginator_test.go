@@ -0,0 +1,75 @@
+package paginator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/Jayleonc/service/pkg/ginx/request"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+type pageItem struct {
+	ID        int
+	Name      string
+	CreatedAt int
+}
+
+func newPageDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&pageItem{}))
+	require.NoError(t, db.Create(&[]pageItem{
+		{ID: 1, Name: "c", CreatedAt: 3},
+		{ID: 2, Name: "a", CreatedAt: 1},
+		{ID: 3, Name: "b", CreatedAt: 2},
+	}).Error)
+	return db
+}
+
+// TestPaginate 验证排序字段必须在白名单内，未提供白名单时拒绝任何排序。
+func TestPaginate(t *testing.T) {
+	db := newPageDB(t)
+
+	cases := []struct {
+		name     string
+		req      *request.Pagination
+		sortKeys []string
+		wantIDs  []int
+		wantErr  error
+	}{
+		{name: "不排序时无需白名单", req: &request.Pagination{}, wantIDs: []int{1, 2, 3}},
+		{name: "白名单内的驼峰字段", req: &request.Pagination{OrderBy: "createdAt desc"}, sortKeys: []string{"created_at"}, wantIDs: []int{1, 3, 2}},
+		{name: "多个排序字段", req: &request.Pagination{OrderBy: "name asc, id"}, sortKeys: []string{"name", "id"}, wantIDs: []int{2, 3, 1}},
+		{name: "分页参数", req: &request.Pagination{Page: 2, PageSize: 2, OrderBy: "name"}, sortKeys: []string{"name"}, wantIDs: []int{1}},
+		{name: "未提供白名单", req: &request.Pagination{OrderBy: "name"}, wantErr: ErrInvalidSortKey},
+		{name: "字段不在白名单内", req: &request.Pagination{OrderBy: "password"}, sortKeys: []string{"name"}, wantErr: ErrInvalidSortKey},
+		{name: "注入语句", req: &request.Pagination{OrderBy: "name; drop table page_items"}, sortKeys: []string{"name"}, wantErr: ErrInvalidSortKey},
+		{name: "方向后附加额外内容", req: &request.Pagination{OrderBy: "name desc nulls"}, sortKeys: []string{"name"}, wantErr: ErrInvalidSortKey},
+		{name: "非法方向", req: &request.Pagination{OrderBy: "name sideways"}, sortKeys: []string{"name"}, wantErr: ErrInvalidSortKey},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Paginate[pageItem](db.Model(&pageItem{}), tc.req, tc.sortKeys...)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.True(t, errors.Is(xerr.From(err), xerr.ErrBadRequest))
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, 3, result.Total)
+
+			ids := make([]int, 0, len(result.List))
+			for _, row := range result.List {
+				ids = append(ids, row.ID)
+			}
+			require.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
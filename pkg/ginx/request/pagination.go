@@ -1,9 +1,28 @@
 // Package request defines common structures for parsing API request payloads.
 package request
 
+import "encoding/json"
+
 // Pagination 定义了标准的分页请求参数
 type Pagination struct {
 	Page     int    `json:"page"`
 	PageSize int    `json:"pageSize"`
 	OrderBy  string `json:"orderBy"`
 }
+
+// CursorPagination 定义了游标（keyset）分页请求参数。
+// Cursor 为上一页返回的 nextCursor，首页留空；翻页时 SortBy 与 Desc 必须与生成游标时一致。
+type CursorPagination struct {
+	Cursor    string `json:"cursor"`
+	PageSize  int    `json:"pageSize"`
+	SortBy    string `json:"sortBy"`
+	Desc      bool   `json:"desc"`
+	WithTotal bool   `json:"withTotal"`
+}
+
+// Filter 定义了列表接口的单个过滤条件，可用字段与操作符由各接口的 filter.Schema 声明。
+type Filter struct {
+	Field string          `json:"field"`
+	Op    string          `json:"op"`
+	Value json.RawMessage `json:"value"`
+}
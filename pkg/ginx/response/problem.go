@@ -0,0 +1,108 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// ProblemContentType 是 RFC 9457 问题详情响应的媒体类型。
+const ProblemContentType = "application/problem+json"
+
+// Problem 是 RFC 9457 定义的问题详情响应体，并扩展了业务错误码、请求 ID、trace_id 与字段级错误。
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      int    `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+	Errors    any    `json:"errors,omitempty"`
+}
+
+// ProblemConfig 控制失败响应何时使用问题详情格式。
+type ProblemConfig struct {
+	// Enabled 为 true 时所有失败响应都使用问题详情格式；否则仅在请求的 Accept 包含 application/problem+json 时使用。
+	Enabled bool
+	// TypeBase 为 type 字段的 URI 前缀，实际值为 <TypeBase>/<错误码>；为空时使用 about:blank。
+	TypeBase string
+}
+
+var problemConfig atomic.Pointer[ProblemConfig]
+
+// ConfigureProblemDetails 设置问题详情的渲染方式，通常在服务启动时调用一次。
+func ConfigureProblemDetails(cfg ProblemConfig) {
+	cfg.TypeBase = strings.TrimRight(strings.TrimSpace(cfg.TypeBase), "/")
+	problemConfig.Store(&cfg)
+}
+
+func currentProblemConfig() ProblemConfig {
+	if cfg := problemConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return ProblemConfig{}
+}
+
+// wantsProblem 判断当前请求的失败响应是否应使用问题详情格式。
+func wantsProblem(c *gin.Context) bool {
+	return currentProblemConfig().Enabled || acceptsProblem(c.GetHeader("Accept"))
+}
+
+// acceptsProblem 判断 Accept 请求头是否以非零 q 值列出了 application/problem+json。
+func acceptsProblem(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaType), ProblemContentType) {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, err := strconv.ParseFloat(value, 64)
+				return err == nil && q > 0
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeProblem 以问题详情格式写出失败响应，data 中的 errors 字段会提升为 Problem.Errors。
+func writeProblem(c *gin.Context, status, code int, detail string, data any) {
+	cfg := currentProblemConfig()
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: applogger.RequestIDFromContext(c.Request.Context()),
+		TraceID:   applogger.TraceIDFromContext(c.Request.Context()),
+		Errors:    problemErrors(data),
+	}
+	if cfg.TypeBase != "" {
+		problem.Type = cfg.TypeBase + "/" + strconv.Itoa(code)
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
+
+func problemErrors(data any) any {
+	switch v := data.(type) {
+	case nil:
+		return nil
+	case gin.H:
+		if errs, ok := v["errors"]; ok {
+			return errs
+		}
+	}
+	return []any{data}
+}
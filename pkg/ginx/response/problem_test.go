@@ -0,0 +1,163 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// configureProblem 设置问题详情配置，并在测试结束后恢复默认值。
+func configureProblem(t *testing.T, cfg ProblemConfig) {
+	t.Helper()
+	ConfigureProblemDetails(cfg)
+	t.Cleanup(func() { ConfigureProblemDetails(ProblemConfig{}) })
+}
+
+// TestAcceptsProblem 验证 Accept 请求头的解析：媒体类型不区分大小写，q=0 表示明确拒绝。
+func TestAcceptsProblem(t *testing.T) {
+	cases := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "未提供", accept: ""},
+		{name: "仅接受 JSON", accept: "application/json"},
+		{name: "通配符不视为请求问题详情", accept: "*/*"},
+		{name: "精确匹配", accept: "application/problem+json", want: true},
+		{name: "大小写与空白", accept: "application/json,  Application/Problem+JSON ", want: true},
+		{name: "带 q 值", accept: "application/json;q=0.9, application/problem+json;q=0.5", want: true},
+		{name: "q 值前的其他参数", accept: "application/problem+json; charset=utf-8; q=1", want: true},
+		{name: "q=0 明确拒绝", accept: "application/problem+json;q=0"},
+		{name: "q=0.0 明确拒绝", accept: "application/problem+json; q=0.0, application/json"},
+		{name: "无法解析的 q 值", accept: "application/problem+json;q=abc"},
+		{name: "相似的媒体类型", accept: "application/problem+xml"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, acceptsProblem(tc.accept))
+		})
+	}
+}
+
+// TestFailProblem 验证失败响应按配置与 Accept 协商格式，问题详情包含状态码、业务错误码、请求路径与请求 ID，
+// data 中的 errors 提升为顶层字段，其他详情包装为数组。
+func TestFailProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errBoom := errors.New("boom")
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(applogger.ContextWithRequestID(c.Request.Context(), "req-1"))
+		c.Next()
+	})
+	router.GET("/forbidden", func(c *gin.Context) { Fail(c, xerr.ErrForbidden) })
+	router.GET("/field-errors", func(c *gin.Context) {
+		Fail(c, xerr.ErrBadRequest.WithData(gin.H{"errors": []gin.H{{"field": "email"}}}))
+	})
+	router.GET("/detail", func(c *gin.Context) { Fail(c, xerr.ErrConflict.WithData("taken")) })
+	router.GET("/internal", func(c *gin.Context) { Fail(c, errBoom) })
+
+	cases := []struct {
+		name        string
+		cfg         ProblemConfig
+		path        string
+		accept      string
+		wantProblem bool
+		want        Problem
+	}{
+		{name: "未请求时使用默认格式", path: "/forbidden"},
+		{name: "q=0 时使用默认格式", path: "/forbidden", accept: "application/problem+json;q=0"},
+		{
+			name:        "按 Accept 协商",
+			path:        "/forbidden",
+			accept:      "application/problem+json",
+			wantProblem: true,
+			want:        Problem{Type: "about:blank", Title: "Forbidden", Status: http.StatusForbidden, Detail: xerr.ErrForbidden.Localize(""), Instance: "/forbidden", Code: xerr.ErrForbidden.Code, RequestID: "req-1"},
+		},
+		{
+			name:        "全局开启并配置 type 前缀",
+			cfg:         ProblemConfig{Enabled: true, TypeBase: " https://errors.example.com/ "},
+			path:        "/forbidden",
+			wantProblem: true,
+			want:        Problem{Type: "https://errors.example.com/403", Title: "Forbidden", Status: http.StatusForbidden, Detail: xerr.ErrForbidden.Localize(""), Instance: "/forbidden", Code: xerr.ErrForbidden.Code, RequestID: "req-1"},
+		},
+		{
+			name:        "字段级错误提升为 errors",
+			cfg:         ProblemConfig{Enabled: true},
+			path:        "/field-errors",
+			wantProblem: true,
+			want:        Problem{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: xerr.ErrBadRequest.Localize(""), Instance: "/field-errors", Code: xerr.ErrBadRequest.Code, RequestID: "req-1", Errors: []any{map[string]any{"field": "email"}}},
+		},
+		{
+			name:        "其他详情包装为数组",
+			cfg:         ProblemConfig{Enabled: true},
+			path:        "/detail",
+			wantProblem: true,
+			want:        Problem{Type: "about:blank", Title: "Conflict", Status: http.StatusConflict, Detail: xerr.ErrConflict.Localize(""), Instance: "/detail", Code: xerr.ErrConflict.Code, RequestID: "req-1", Errors: []any{"taken"}},
+		},
+		{
+			name:        "未知错误不暴露原因",
+			cfg:         ProblemConfig{Enabled: true},
+			path:        "/internal",
+			wantProblem: true,
+			want:        Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError, Detail: xerr.ErrInternalServer.Localize(""), Instance: "/internal", Code: xerr.ErrInternalServer.Code, RequestID: "req-1"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			configureProblem(t, tc.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.NotContains(t, w.Body.String(), errBoom.Error())
+
+			if !tc.wantProblem {
+				require.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"))
+				var body Response
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				require.Equal(t, xerr.ErrForbidden.Code, body.Code)
+				require.Equal(t, "req-1", body.RequestID)
+				return
+			}
+
+			require.Equal(t, tc.want.Status, w.Code)
+			require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+			var problem Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, tc.want, problem)
+		})
+	}
+}
+
+// TestBindErrorProblem 验证 BindError 的字段级错误在问题详情格式下位于顶层 errors。
+func TestBindErrorProblem(t *testing.T) {
+	configureProblem(t, ProblemConfig{Enabled: true})
+	router := newBindRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"email": "bad"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "invalid request payload", body["detail"])
+	require.NotContains(t, body, "data")
+	require.Equal(t, []any{map[string]any{"field": "email", "rule": "email", "message": "email must be a valid email address"}}, body["errors"])
+}
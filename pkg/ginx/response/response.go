@@ -1,19 +1,22 @@
 package response
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+	"github.com/Jayleonc/service/pkg/validation"
 	"github.com/Jayleonc/service/pkg/xerr"
 )
 
-// Response 定义了 API 响应的通用结构。
+// Response 定义了 API 响应的通用结构。RequestID 与 TraceID 仅在失败响应中返回，便于排查问题时关联日志与链路。
 type Response struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	Data      any    `json:"data,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
 }
 
 // PageResult 标准分页响应结构
@@ -24,6 +27,15 @@ type PageResult[T any] struct {
 	PageSize int   `json:"pageSize"`
 }
 
+// CursorPageResult 游标分页响应结构，Total 仅在请求 withTotal 时返回
+type CursorPageResult[T any] struct {
+	List       []T    `json:"list"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	Total      *int64 `json:"total,omitempty"`
+	PageSize   int    `json:"pageSize"`
+}
+
 // Success 使用默认 200 状态码返回成功响应。
 func Success(c *gin.Context, data any) {
 	SuccessWithStatus(c, http.StatusOK, data)
@@ -47,18 +59,42 @@ func SuccessWithMessage(c *gin.Context, message string, data any) {
 	})
 }
 
-// Error 根据传入错误类型返回统一的失败响应，并优先使用业务错误信息。
-func Error(c *gin.Context, httpStatus int, err error) {
-	var business *xerr.Error
-	if errors.As(err, &business) {
-		c.JSON(httpStatus, Response{Code: business.Code, Message: business.Message})
-		return
+// Fail 将 err 转换为业务错误（见 xerr.From），以其 HTTP 状态码返回失败响应，并附带 WithData 设置的详情。
+// 业务错误的消息按请求的 Accept-Language 本地化；原始错误通过 c.Error 交给访问日志记录，底层原因不会出现在响应中。
+func Fail(c *gin.Context, err error) {
+	business := xerr.From(err)
+	if business == nil {
+		business = xerr.ErrInternalServer
+	} else {
+		_ = c.Error(err)
 	}
+	writeError(c, business.HTTPStatus(), business.Code, business.Localize(c.GetHeader("Accept-Language")), business.Data())
+}
 
-	message := http.StatusText(httpStatus)
-	if err != nil && err.Error() != "" {
-		message = err.Error()
+// writeError 按请求协商的格式写出失败响应：问题详情（见 ConfigureProblemDetails）或默认的 Response 结构。
+func writeError(c *gin.Context, status, code int, message string, data any) {
+	if wantsProblem(c) {
+		writeProblem(c, status, code, message, data)
+		return
 	}
+	ctx := c.Request.Context()
+	c.JSON(status, Response{
+		Code:      code,
+		Message:   message,
+		Data:      data,
+		RequestID: applogger.RequestIDFromContext(ctx),
+		TraceID:   applogger.TraceIDFromContext(ctx),
+	})
+}
 
-	c.JSON(httpStatus, Response{Code: httpStatus, Message: message})
+// BindError 返回请求体解析或校验失败的 400 响应；能定位到字段的错误会按 Accept-Language
+// 本地化后以 data.errors 返回，例如 [{"field": "email", "rule": "email", "message": "..."}]。
+func BindError(c *gin.Context, err error) {
+	bad := xerr.ErrBadRequest.WithMessage("invalid request payload")
+	details := validation.Translate(err, c.GetHeader("Accept-Language"))
+	if len(details) == 0 {
+		Fail(c, bad)
+		return
+	}
+	Fail(c, bad.WithData(gin.H{"errors": details}))
 }
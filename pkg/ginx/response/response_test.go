@@ -0,0 +1,109 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jayleonc/service/pkg/validation"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+type bindRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Items []struct {
+		Qty int `json:"qty" validate:"min=1"`
+	} `json:"items" validate:"dive"`
+}
+
+// bindErrorBody 是 BindError 默认响应格式中与断言相关的部分。
+type bindErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    *struct {
+		Errors []validation.FieldError `json:"errors"`
+	} `json:"data"`
+}
+
+func newBindRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	binding.Validator = validation.NewDualTagValidator()
+
+	router := gin.New()
+	router.POST("/bind", func(c *gin.Context) {
+		var req bindRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BindError(c, err)
+			return
+		}
+		Success(c, nil)
+	})
+	return router
+}
+
+// TestBindError 验证请求体解析与校验失败返回 400，能定位到字段的错误按语言本地化后放入 data.errors。
+func TestBindError(t *testing.T) {
+	router := newBindRouter()
+
+	cases := []struct {
+		name           string
+		body           string
+		acceptLanguage string
+		wantErrors     []validation.FieldError
+	}{
+		{
+			name:       "校验失败",
+			body:       `{"email": "bad"}`,
+			wantErrors: []validation.FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email address"}},
+		},
+		{
+			name:           "中文校验消息",
+			body:           `{}`,
+			acceptLanguage: "zh-CN",
+			wantErrors:     []validation.FieldError{{Field: "email", Rule: "required", Message: "email为必填字段"}},
+		},
+		{
+			name:       "数组元素的校验失败",
+			body:       `{"email": "a@example.com", "items": [{"qty": 1}, {"qty": 0}]}`,
+			wantErrors: []validation.FieldError{{Field: "items[1].qty", Rule: "min", Param: "1", Message: "qty must be 1 or greater"}},
+		},
+		{
+			name:           "类型不匹配",
+			body:           `{"email": "a@example.com", "items": [{"qty": "one"}]}`,
+			acceptLanguage: "zh",
+			wantErrors:     []validation.FieldError{{Field: "items[0].qty", Rule: "type", Param: "number", Message: "items[0].qty必须是有效的数字"}},
+		},
+		{name: "语法错误不附带字段详情", body: `{"email": `},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var body bindErrorBody
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, xerr.ErrBadRequest.Code, body.Code)
+			require.Equal(t, "invalid request payload", body.Message)
+
+			if tc.wantErrors == nil {
+				require.Nil(t, body.Data)
+				return
+			}
+			require.NotNil(t, body.Data)
+			require.Equal(t, tc.wantErrors, body.Data.Errors)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Package i18n 提供服务支持的语言列表，以及基于 Accept-Language 请求头的语言协商。
+package i18n
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale 是 Accept-Language 无法匹配任何支持语言时使用的语言。
+const DefaultLocale = "en"
+
+// SupportedLocales 列出服务支持的语言，每种语言都需要提供完整的错误码与校验消息翻译。
+var SupportedLocales = []string{"en", "zh"}
+
+// Negotiate 根据 Accept-Language 请求头返回支持的语言：按 q 值从高到低匹配，
+// 先尝试完整语言标签（如 zh-cn）再尝试主语言（如 zh），均不支持时返回 DefaultLocale。
+func Negotiate(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		tag = strings.ReplaceAll(strings.ToLower(tag), "-", "_")
+		if slices.Contains(SupportedLocales, tag) {
+			return tag
+		}
+		primary, _, _ := strings.Cut(tag, "_")
+		if slices.Contains(SupportedLocales, primary) {
+			return primary
+		}
+	}
+	return DefaultLocale
+}
+
+// parseAcceptLanguage 按 q 值降序返回语言标签，q=0 的标签会被忽略。
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
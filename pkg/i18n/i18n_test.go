@@ -0,0 +1,36 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNegotiate 验证按 q 值选择语言、地区标签回退到主语言，以及无法匹配时使用默认语言。
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "空请求头", acceptLanguage: "", want: DefaultLocale},
+		{name: "完整匹配", acceptLanguage: "zh", want: "zh"},
+		{name: "地区标签回退到主语言", acceptLanguage: "zh-CN", want: "zh"},
+		{name: "下划线分隔与大小写", acceptLanguage: "ZH_tw", want: "zh"},
+		{name: "按出现顺序选择同权重语言", acceptLanguage: "zh-CN,en", want: "zh"},
+		{name: "按 q 值选择", acceptLanguage: "zh;q=0.5, en;q=0.8", want: "en"},
+		{name: "省略 q 值视为 1", acceptLanguage: "en;q=0.9, zh", want: "zh"},
+		{name: "跳过不支持的语言", acceptLanguage: "fr, de;q=0.9, zh;q=0.1", want: "zh"},
+		{name: "q=0 表示不接受", acceptLanguage: "zh;q=0, fr", want: DefaultLocale},
+		{name: "q=0 的语言不参与匹配", acceptLanguage: "zh;q=0.0, en;q=0.1", want: "en"},
+		{name: "非法 q 值被忽略", acceptLanguage: "zh;q=abc, en;q=0.2", want: "en"},
+		{name: "通配符不匹配具体语言", acceptLanguage: "*", want: DefaultLocale},
+		{name: "全部不支持时回退默认语言", acceptLanguage: "fr-FR, ja", want: DefaultLocale},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Negotiate(tc.acceptLanguage))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict 表示记录在读取之后已被其他请求修改。
+var ErrVersionConflict = errors.New("version conflict")
+
+// Versioned 为需要乐观并发控制的模型提供版本号字段，每次成功更新后加一。
+type Versioned struct {
+	Version int64 `json:"version" gorm:"column:version;not null;default:1"`
+}
+
+// BeforeCreate 确保新记录的版本号从 1 开始，并在创建后即可读取。
+func (v *Versioned) BeforeCreate(*gorm.DB) error {
+	if v.Version == 0 {
+		v.Version = 1
+	}
+	return nil
+}
+
+// SaveVersioned 以乐观锁方式保存已加载的记录：仅当数据库中的版本号仍等于 current.Version 时写入全部字段，
+// 并将版本号加一；版本不一致或记录已被删除时返回 ErrVersionConflict，且 current 保持不变。
+// value 必须是嵌入了 Versioned 的模型指针，current 为其中的 Versioned 字段。
+func SaveVersioned(db *gorm.DB, value any, current *Versioned) error {
+	expected := current.Version
+	current.Version = expected + 1
+
+	result := db.Model(value).
+		Where("version = ?", expected).
+		Select("*").
+		Omit(clause.Associations, "created_at", "deleted_at").
+		Updates(value)
+	if result.Error != nil {
+		current.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		current.Version = expected
+		return ErrVersionConflict
+	}
+	return nil
+}
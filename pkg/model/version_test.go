@@ -0,0 +1,117 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type versionedRecord struct {
+	ID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name string
+	Versioned
+	Base
+}
+
+func setupVersionedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&versionedRecord{}))
+	return db
+}
+
+func loadVersioned(t *testing.T, db *gorm.DB, id uuid.UUID) *versionedRecord {
+	t.Helper()
+
+	var record versionedRecord
+	require.NoError(t, db.Unscoped().First(&record, "id = ?", id).Error)
+	return &record
+}
+
+// TestSaveVersioned 验证保存成功后版本号加一；持有过期版本或记录已删除时返回 ErrVersionConflict，且内存中的版本号与数据库都保持不变。
+func TestSaveVersioned(t *testing.T) {
+	cases := []struct {
+		name        string
+		prepare     func(t *testing.T, db *gorm.DB, id uuid.UUID)
+		wantErr     error
+		wantVersion int64
+	}{
+		{name: "版本一致", wantVersion: 2},
+		{
+			name: "版本已被其他请求更新",
+			prepare: func(t *testing.T, db *gorm.DB, id uuid.UUID) {
+				other := loadVersioned(t, db, id)
+				other.Name = "other"
+				require.NoError(t, SaveVersioned(db, other, &other.Versioned))
+			},
+			wantErr:     ErrVersionConflict,
+			wantVersion: 1,
+		},
+		{
+			name: "记录已被删除",
+			prepare: func(t *testing.T, db *gorm.DB, id uuid.UUID) {
+				require.NoError(t, db.Delete(&versionedRecord{}, "id = ?", id).Error)
+			},
+			wantErr:     ErrVersionConflict,
+			wantVersion: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupVersionedDB(t)
+			created := &versionedRecord{ID: uuid.New(), Name: "original"}
+			require.NoError(t, db.Create(created).Error)
+			require.Equal(t, int64(1), created.Version)
+
+			record := loadVersioned(t, db, created.ID)
+			if tc.prepare != nil {
+				tc.prepare(t, db, created.ID)
+			}
+			before := loadVersioned(t, db, created.ID)
+
+			record.Name = "changed"
+			err := SaveVersioned(db, record, &record.Versioned)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Equal(t, tc.wantVersion, record.Version)
+				require.Equal(t, before, loadVersioned(t, db, created.ID))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantVersion, record.Version)
+
+			stored := loadVersioned(t, db, created.ID)
+			require.Equal(t, "changed", stored.Name)
+			require.Equal(t, tc.wantVersion, stored.Version)
+		})
+	}
+}
+
+// TestSaveVersionedPreservesCreatedAt 验证保存全部字段时不会覆盖创建时间与删除标记。
+func TestSaveVersionedPreservesCreatedAt(t *testing.T) {
+	db := setupVersionedDB(t)
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, db.Create(&versionedRecord{ID: uuid.New(), Name: "original", Base: Base{CreatedAt: createdAt}}).Error)
+
+	var record versionedRecord
+	require.NoError(t, db.First(&record).Error)
+	record.Name = "changed"
+	record.CreatedAt = time.Time{}
+	require.NoError(t, SaveVersioned(db, &record, &record.Versioned))
+
+	stored := loadVersioned(t, db, record.ID)
+	require.True(t, createdAt.Equal(stored.CreatedAt))
+	require.False(t, stored.DeletedAt.Valid)
+	require.Equal(t, int64(2), stored.Version)
+}
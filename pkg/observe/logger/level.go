@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RootModule 是全局日志级别对应的名称，未单独设置级别的模块跟随该级别。
+const RootModule = "root"
+
+var (
+	// ErrUnknownModule 表示模块未通过 Module 注册。
+	ErrUnknownModule = errors.New("logger: unknown module")
+	// ErrInvalidLevel 表示无法识别的日志级别。
+	ErrInvalidLevel = errors.New("logger: invalid level")
+)
+
+var (
+	rootLevel = new(slog.LevelVar)
+
+	modulesMu sync.RWMutex
+	modules   = make(map[string]*ModuleLogger)
+)
+
+// ModuleLogger 是按模块命名的日志器，级别可在运行时通过 SetLevel 单独调整，未调整时跟随全局级别。
+// 日志会附带 module 字段，并沿用上下文日志器中的 request_id 等字段。
+type ModuleLogger struct {
+	name     string
+	override atomic.Pointer[slog.Level]
+}
+
+// Module 返回名为 name 的模块日志器，同名模块共享同一个实例。通常在包级变量中调用：
+//
+//	var log = logger.Module("user")
+func Module(name string) *ModuleLogger {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	if m, ok := modules[name]; ok {
+		return m
+	}
+	m := &ModuleLogger{name: name}
+	modules[name] = m
+	return m
+}
+
+// Name 返回模块名称。
+func (m *ModuleLogger) Name() string {
+	return m.name
+}
+
+// Level 返回模块当前生效的日志级别，实现 slog.Leveler。
+func (m *ModuleLogger) Level() slog.Level {
+	if level := m.override.Load(); level != nil {
+		return *level
+	}
+	return rootLevel.Level()
+}
+
+// Enabled 判断模块是否会输出 level 级别的日志。
+func (m *ModuleLogger) Enabled(level slog.Level) bool {
+	return level >= m.Level()
+}
+
+// Logger 返回以模块级别过滤、并附带 module 字段的上下文日志器。
+func (m *ModuleLogger) Logger(ctx context.Context) *slog.Logger {
+	log := FromContext(ctx)
+	if h, ok := log.Handler().(*levelHandler); ok {
+		log = slog.New(&levelHandler{next: h.next, leveler: m})
+	}
+	return log.With(slog.String("module", m.name))
+}
+
+// Debug 记录调试级别日志。
+func (m *ModuleLogger) Debug(ctx context.Context, msg string, args ...any) {
+	m.log(ctx, slog.LevelDebug, msg, args...)
+}
+
+// Info 记录信息级别日志。
+func (m *ModuleLogger) Info(ctx context.Context, msg string, args ...any) {
+	m.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+// Warn 记录警告级别日志。
+func (m *ModuleLogger) Warn(ctx context.Context, msg string, args ...any) {
+	m.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+// Error 记录错误级别日志。
+func (m *ModuleLogger) Error(ctx context.Context, msg string, args ...any) {
+	m.log(ctx, slog.LevelError, msg, args...)
+}
+
+func (m *ModuleLogger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	ensureContext(ctx)
+	if !m.Enabled(level) {
+		return
+	}
+	m.Logger(ctx).Log(contextOrBackground(ctx), level, msg, args...)
+}
+
+// ModuleLevel 描述一个模块当前生效的日志级别。
+type ModuleLevel struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+	// Inherited 表示模块未单独设置级别，跟随全局级别。
+	Inherited bool `json:"inherited"`
+}
+
+// Levels 返回全局级别与全部已注册模块的级别，全局级别排在首位，其余按模块名排序。
+func Levels() []ModuleLevel {
+	modulesMu.RLock()
+	defer modulesMu.RUnlock()
+
+	result := make([]ModuleLevel, 0, len(modules)+1)
+	result = append(result, ModuleLevel{Module: RootModule, Level: formatLevel(rootLevel.Level())})
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := modules[name]
+		result = append(result, ModuleLevel{Module: name, Level: formatLevel(m.Level()), Inherited: m.override.Load() == nil})
+	}
+	return result
+}
+
+// SetLevel 在运行时调整 module 的日志级别并返回调整前的级别。module 为 RootModule 时调整全局级别；
+// level 为 inherit 时取消模块的单独设置，使其重新跟随全局级别。
+func SetLevel(module, level string) (string, error) {
+	module = strings.ToLower(strings.TrimSpace(module))
+	level = strings.ToLower(strings.TrimSpace(level))
+
+	if module == RootModule {
+		parsed, ok := parseLevel(level)
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrInvalidLevel, level)
+		}
+		previous := formatLevel(rootLevel.Level())
+		rootLevel.Set(parsed)
+		return previous, nil
+	}
+
+	modulesMu.RLock()
+	m, ok := modules[module]
+	modulesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownModule, module)
+	}
+
+	previous := "inherit"
+	if current := m.override.Load(); current != nil {
+		previous = formatLevel(*current)
+	}
+	if level == "inherit" {
+		m.override.Store(nil)
+		return previous, nil
+	}
+	parsed, ok := parseLevel(level)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrInvalidLevel, level)
+	}
+	m.override.Store(&parsed)
+	return previous, nil
+}
+
+func formatLevel(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// levelHandler 按 leveler 的当前级别过滤日志。Init 创建的底层 Handler 不再过滤级别，
+// 由最外层的 levelHandler 决定输出，从而允许单个模块低于全局级别输出。
+type levelHandler struct {
+	next    slog.Handler
+	leveler slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.leveler.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), leveler: h.leveler}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), leveler: h.leveler}
+}
+
+// Audit 记录一条审计日志：带有 audit=true 字段，以 info 级别输出且不受全局与模块级别限制，
+// 用于记录调整日志级别等运维操作。
+func Audit(ctx context.Context, msg string, args ...any) {
+	ensureContext(ctx)
+	log := FromContext(ctx)
+	if h, ok := log.Handler().(*levelHandler); ok {
+		log = slog.New(h.next)
+	}
+	log.With(slog.Bool("audit", true)).InfoContext(contextOrBackground(ctx), msg, args...)
+}
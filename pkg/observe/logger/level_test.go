@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// isolateLevels 注册测试专用的模块，并在测试结束后恢复全局级别、移除这些模块。
+func isolateLevels(t *testing.T, names ...string) []*ModuleLogger {
+	t.Helper()
+
+	saved := rootLevel.Level()
+	rootLevel.Set(slog.LevelInfo)
+	result := make([]*ModuleLogger, 0, len(names))
+	for _, name := range names {
+		result = append(result, Module(name))
+	}
+
+	t.Cleanup(func() {
+		rootLevel.Set(saved)
+		modulesMu.Lock()
+		for _, m := range result {
+			delete(modules, m.name)
+		}
+		modulesMu.Unlock()
+	})
+	return result
+}
+
+// TestSetLevel 验证模块单独设置的级别不受全局级别影响，inherit 取消设置后重新跟随全局级别。
+func TestSetLevel(t *testing.T) {
+	mods := isolateLevels(t, "level-test-a", "level-test-b")
+	a, b := mods[0], mods[1]
+
+	steps := []struct {
+		name         string
+		module       string
+		level        string
+		wantPrevious string
+		wantErr      error
+		wantA        slog.Level
+		wantB        slog.Level
+	}{
+		{name: "模块单独设置", module: " Level-Test-A ", level: "DEBUG", wantPrevious: "inherit", wantA: slog.LevelDebug, wantB: slog.LevelInfo},
+		{name: "调整全局级别不影响已设置的模块", module: RootModule, level: "warning", wantPrevious: "info", wantA: slog.LevelDebug, wantB: slog.LevelWarn},
+		{name: "再次设置返回之前的级别", module: "level-test-a", level: "error", wantPrevious: "debug", wantA: slog.LevelError, wantB: slog.LevelWarn},
+		{name: "inherit 恢复跟随全局级别", module: "level-test-a", level: "inherit", wantPrevious: "error", wantA: slog.LevelWarn, wantB: slog.LevelWarn},
+		{name: "未设置的模块 inherit 无副作用", module: "level-test-b", level: "inherit", wantPrevious: "inherit", wantA: slog.LevelWarn, wantB: slog.LevelWarn},
+		{name: "全局级别不支持 inherit", module: RootModule, level: "inherit", wantErr: ErrInvalidLevel, wantA: slog.LevelWarn, wantB: slog.LevelWarn},
+		{name: "无法识别的级别", module: "level-test-b", level: "verbose", wantErr: ErrInvalidLevel, wantA: slog.LevelWarn, wantB: slog.LevelWarn},
+		{name: "未注册的模块", module: "level-test-missing", level: "debug", wantErr: ErrUnknownModule, wantA: slog.LevelWarn, wantB: slog.LevelWarn},
+	}
+
+	for _, step := range steps {
+		previous, err := SetLevel(step.module, step.level)
+		if step.wantErr != nil {
+			require.ErrorIs(t, err, step.wantErr, step.name)
+		} else {
+			require.NoError(t, err, step.name)
+			require.Equal(t, step.wantPrevious, previous, step.name)
+		}
+		require.Equal(t, step.wantA, a.Level(), step.name)
+		require.Equal(t, step.wantB, b.Level(), step.name)
+	}
+}
+
+// TestLevels 验证全局级别排在首位，模块按名称排序并标记是否跟随全局级别。
+func TestLevels(t *testing.T) {
+	isolateLevels(t, "level-test-b", "level-test-a")
+	_, err := SetLevel("level-test-b", "error")
+	require.NoError(t, err)
+
+	var got []ModuleLevel
+	for _, level := range Levels() {
+		if level.Module == RootModule || strings.HasPrefix(level.Module, "level-test-") {
+			got = append(got, level)
+		}
+	}
+	require.Equal(t, []ModuleLevel{
+		{Module: RootModule, Level: "info"},
+		{Module: "level-test-a", Level: "info", Inherited: true},
+		{Module: "level-test-b", Level: "error"},
+	}, got)
+}
+
+// TestModuleLoggerOutput 验证模块日志按模块级别过滤并附带 module 字段，审计日志不受全局级别限制。
+func TestModuleLoggerOutput(t *testing.T) {
+	mods := isolateLevels(t, "level-test-verbose", "level-test-quiet")
+	verbose, quiet := mods[0], mods[1]
+	_, err := SetLevel(verbose.Name(), "debug")
+	require.NoError(t, err)
+	_, err = SetLevel(quiet.Name(), "error")
+	require.NoError(t, err)
+	_, err = SetLevel(RootModule, "error")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	base := slog.New(&levelHandler{next: slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), leveler: rootLevel})
+	ctx := WithContext(context.Background(), base.With(slog.String("request_id", "req-1")))
+
+	verbose.Debug(ctx, "verbose debug")
+	quiet.Warn(ctx, "quiet warn")
+	quiet.Error(ctx, "quiet error")
+	FromContext(ctx).Warn("root warn")
+	Audit(ctx, "audited")
+
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		got = append(got, record)
+	}
+
+	require.Len(t, got, 3)
+	require.Equal(t, "verbose debug", got[0]["msg"])
+	require.Equal(t, "level-test-verbose", got[0]["module"])
+	require.Equal(t, "req-1", got[0]["request_id"])
+	require.Equal(t, "quiet error", got[1]["msg"])
+	require.Equal(t, "level-test-quiet", got[1]["module"])
+	require.Equal(t, "audited", got[2]["msg"])
+	require.Equal(t, true, got[2]["audit"])
+}
@@ -3,11 +3,11 @@ package logger
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -22,21 +22,37 @@ type Config struct {
 	Level     *string
 	Pretty    *bool
 	Directory string
+	// Modules 为模块单独指定日志级别，键为 Module 注册的模块名，值为级别或 inherit。
+	Modules map[string]string
+	// Rotate 控制写入 Directory 的日志文件如何轮转与保留，其中的 Directory 与 Prefix 由 Init 填充。
+	Rotate RotateConfig
+	// AccessLog 为 true 时访问日志单独写入 Directory 下的 access.log，不再混入服务日志文件。
+	AccessLog bool
+	// Redact 控制日志与 SQL 参数的脱敏规则，默认启用。
+	Redact RedactConfig
 }
 
+// RequestIDHeader 是携带请求 ID 的 HTTP 头，入站请求与出站调用使用同一个名称。
+const RequestIDHeader = "X-Request-ID"
+
 const (
 	requestIDLength   = 16
 	requestIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
-	logFilePrefix     = "service"
-	retentionDays     = 7
+	accessFilePrefix  = "access"
 )
 
 var (
-	globalMu    sync.RWMutex
-	globalLog   *slog.Logger
-	globalLevel slog.Level = slog.LevelInfo
+	globalMu  sync.RWMutex
+	globalLog *slog.Logger
+	// accessLog 为单独写入访问日志文件的日志器，未启用 Config.AccessLog 时为空。
+	accessLog *slog.Logger
+	// writers 记录 Init 打开的日志文件，由 Close 统一关闭。
+	writers []io.Closer
 )
 
+// access 是访问日志的模块日志器，级别可像其他模块一样单独调整。
+var access = Module("access")
+
 // Init 根据配置初始化日志记录器，并将其设置为全局默认值。
 func Init(cfg Config) (*slog.Logger, error) {
 	level, pretty := resolveModeDefaults(cfg.Mode)
@@ -50,20 +66,67 @@ func Init(cfg Config) (*slog.Logger, error) {
 		pretty = *cfg.Pretty
 	}
 
-	handlerOpts := &slog.HandlerOptions{Level: level}
+	if cfg.Redact.Enabled == nil || *cfg.Redact.Enabled {
+		r, err := NewRedactor(cfg.Redact, cfg.Mode)
+		if err != nil {
+			return nil, err
+		}
+		redactor.Store(r)
+	} else {
+		redactor.Store(nil)
+	}
+
+	// 重复初始化时先关闭上一次打开的日志文件。
+	if err := Close(); err != nil {
+		return nil, fmt.Errorf("logger: close previous writers: %w", err)
+	}
 
 	writer := io.Writer(os.Stdout)
+	var accessWriter io.Writer
 	if dir := strings.TrimSpace(cfg.Directory); dir != "" {
-		rotating, err := newRotatingWriter(dir)
+		rotate := cfg.Rotate
+		rotate.Directory = dir
+		rotating, err := openWriter(rotate)
 		if err != nil {
-			return nil, fmt.Errorf("logger: create rotating writer: %w", err)
+			return nil, err
 		}
 		writer = io.MultiWriter(os.Stdout, rotating)
+
+		if cfg.AccessLog {
+			rotate.Prefix = accessFilePrefix
+			accessFile, err := openWriter(rotate)
+			if err != nil {
+				return nil, err
+			}
+			accessWriter = io.MultiWriter(os.Stdout, accessFile)
+		}
 	}
 
+	log := slog.New(&levelHandler{next: newHandler(cfg.Mode, pretty, writer), leveler: rootLevel})
+	SetDefault(log, level)
+
+	globalMu.Lock()
+	accessLog = nil
+	if accessWriter != nil {
+		accessLog = slog.New(&levelHandler{next: newHandler(cfg.Mode, pretty, accessWriter), leveler: access})
+	}
+	globalMu.Unlock()
+
+	for module, moduleLevel := range cfg.Modules {
+		if _, err := SetLevel(module, moduleLevel); err != nil {
+			return nil, err
+		}
+	}
+	return log, nil
+}
+
+// newHandler 构建输出到 writer 的底层 Handler。它输出全部级别，实际过滤由外层的 levelHandler 按全局或模块级别完成。
+func newHandler(mode string, pretty bool, writer io.Writer) slog.Handler {
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+
 	var handler slog.Handler
 	if pretty {
-		if isDevMode(cfg.Mode) {
+		if isDevMode(mode) {
 			handler = newDevTextHandler(writer, handlerOpts)
 		} else {
 			handler = slog.NewTextHandler(writer, handlerOpts)
@@ -73,13 +136,52 @@ func Init(cfg Config) (*slog.Logger, error) {
 	}
 
 	// In dev mode with pretty enabled, wrap with prettySQLHandler for multi-line, colorized SQL output
-	if isDevMode(cfg.Mode) && pretty {
+	if isDevMode(mode) && pretty {
 		handler = newPrettySQLHandler(handler)
 	}
+	return newRedactHandler(newTraceHandler(handler))
+}
 
-	log := slog.New(handler)
-	SetDefault(log, level)
-	return log, nil
+func openWriter(cfg RotateConfig) (*rotatingWriter, error) {
+	w, err := newRotatingWriter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("logger: create rotating writer: %w", err)
+	}
+	globalMu.Lock()
+	writers = append(writers, w)
+	globalMu.Unlock()
+	return w, nil
+}
+
+// Close 写出缓冲区中的日志并关闭 Init 打开的日志文件，应在进程退出前调用。
+func Close() error {
+	globalMu.Lock()
+	closing := writers
+	writers = nil
+	globalMu.Unlock()
+
+	var errs []error
+	for _, w := range closing {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Access 返回记录访问日志的日志器：启用 Config.AccessLog 时写入单独的访问日志文件，否则与服务日志一起输出。
+// 返回的日志器附带 module=access 与请求的 request_id，级别由 access 模块决定。
+func Access(ctx context.Context) *slog.Logger {
+	globalMu.RLock()
+	log := accessLog
+	globalMu.RUnlock()
+
+	if log == nil {
+		return access.Logger(ctx)
+	}
+	log = log.With(slog.String("module", access.Name()))
+	if id := RequestIDFromContext(ctx); id != "" {
+		log = log.With(slog.String("request_id", id))
+	}
+	return log
 }
 
 // SetDefault 替换全局日志记录器和日志级别。
@@ -90,7 +192,7 @@ func SetDefault(log *slog.Logger, level slog.Level) {
 	globalMu.Lock()
 	defer globalMu.Unlock()
 	globalLog = log
-	globalLevel = level
+	rootLevel.Set(level)
 	slog.SetDefault(log)
 }
 
@@ -104,11 +206,9 @@ func Default() *slog.Logger {
 	return slog.Default()
 }
 
-// Level 返回当前全局日志级别。
+// Level 返回当前全局日志级别，可通过 SetLevel(RootModule, ...) 在运行时调整。
 func Level() slog.Level {
-	globalMu.RLock()
-	defer globalMu.RUnlock()
-	return globalLevel
+	return rootLevel.Level()
 }
 
 type contextKey struct{}
@@ -166,25 +266,25 @@ func RequestIDFromContext(ctx context.Context) string {
 // Debug 记录调试级别日志。
 func Debug(ctx context.Context, msg string, args ...any) {
 	ensureContext(ctx)
-	FromContext(ctx).Debug(msg, args...)
+	FromContext(ctx).DebugContext(contextOrBackground(ctx), msg, args...)
 }
 
 // Info 记录信息级别日志。
 func Info(ctx context.Context, msg string, args ...any) {
 	ensureContext(ctx)
-	FromContext(ctx).Info(msg, args...)
+	FromContext(ctx).InfoContext(contextOrBackground(ctx), msg, args...)
 }
 
 // Warn 记录警告级别日志。
 func Warn(ctx context.Context, msg string, args ...any) {
 	ensureContext(ctx)
-	FromContext(ctx).Warn(msg, args...)
+	FromContext(ctx).WarnContext(contextOrBackground(ctx), msg, args...)
 }
 
 // Error 记录错误级别日志。
 func Error(ctx context.Context, msg string, args ...any) {
 	ensureContext(ctx)
-	FromContext(ctx).Error(msg, args...)
+	FromContext(ctx).ErrorContext(contextOrBackground(ctx), msg, args...)
 }
 
 // ensureContext 确保上下文是 context.Context 类型。
@@ -200,6 +300,14 @@ func ensureContext(ctx context.Context) {
 	}
 }
 
+// contextOrBackground 保证传给 slog 的上下文非空，以便 Handler 读取其中的 span。
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
 func resolveModeDefaults(mode string) (slog.Level, bool) {
 	switch strings.ToLower(strings.TrimSpace(mode)) {
 	case "prod", "production", "pro":
@@ -224,82 +332,6 @@ func parseLevel(level string) (slog.Level, bool) {
 	}
 }
 
-type rotatingWriter struct {
-	dir  string
-	mu   sync.Mutex
-	day  string
-	file *os.File
-}
-
-func newRotatingWriter(dir string) (*rotatingWriter, error) {
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return nil, err
-	}
-	return &rotatingWriter{dir: dir}, nil
-}
-
-func (w *rotatingWriter) Write(p []byte) (int, error) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	today := time.Now().UTC().Format("2006-01-02")
-	if w.file == nil || w.day != today {
-		if err := w.rotateLocked(today); err != nil {
-			return 0, err
-		}
-	}
-
-	n, err := w.file.Write(p)
-	if err != nil {
-		return n, err
-	}
-	if err := w.file.Sync(); err != nil {
-		return n, err
-	}
-	return n, nil
-}
-
-func (w *rotatingWriter) rotateLocked(day string) error {
-	if w.file != nil {
-		_ = w.file.Close()
-	}
-
-	name := fmt.Sprintf("%s-%s.log", logFilePrefix, day)
-	file, err := os.OpenFile(filepath.Join(w.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
-	if err != nil {
-		return err
-	}
-	w.file = file
-	w.day = day
-	w.cleanupLocked()
-	return nil
-}
-
-func (w *rotatingWriter) cleanupLocked() {
-	entries, err := os.ReadDir(w.dir)
-	if err != nil {
-		return
-	}
-	cutoff := time.Now().UTC().AddDate(0, 0, -retentionDays)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if !strings.HasPrefix(name, logFilePrefix+"-") || !strings.HasSuffix(name, ".log") {
-			continue
-		}
-		datePart := strings.TrimSuffix(strings.TrimPrefix(name, logFilePrefix+"-"), ".log")
-		t, err := time.Parse("2006-01-02", datePart)
-		if err != nil {
-			continue
-		}
-		if t.Before(cutoff) {
-			_ = os.Remove(filepath.Join(w.dir, name))
-		}
-	}
-}
-
 // GenerateRequestID 返回一个长度为 16 的随机请求 ID。
 func GenerateRequestID() string {
 	buf := make([]byte, requestIDLength)
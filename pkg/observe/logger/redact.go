@@ -0,0 +1,348 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RedactedValue 是敏感内容被替换后的占位文本。
+const RedactedValue = "[REDACTED]"
+
+var (
+	// DefaultRedactKeys 是默认视为敏感的字段名片段。字段名去掉大小写、下划线与连字符后包含任一片段即会被替换，
+	// 例如 password 同时匹配 password_hash 与 newPassword。
+	DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "apikey", "privatekey", "credential"}
+	// DefaultRedactPatterns 是默认在字符串内容中查找并替换的正则：Bearer 凭证、JWT、bcrypt 哈希与邮箱地址。
+	DefaultRedactPatterns = []string{
+		`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`,
+		`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+		`\$2[aby]?\$\d{2}\$[./A-Za-z0-9]{53}`,
+		`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	}
+)
+
+// RedactConfig 控制日志脱敏规则。
+type RedactConfig struct {
+	// Enabled 指定是否启用脱敏，为空时默认启用。
+	Enabled *bool
+	// Keys 为在 DefaultRedactKeys 之外追加的敏感字段名片段。
+	Keys []string
+	// Patterns 为在 DefaultRedactPatterns 之外追加的正则表达式。
+	Patterns []string
+	// MaskSQLParams 指定 SQL 日志是否隐藏绑定参数中的字符串等取值；为空时生产模式隐藏、开发模式仅按 Patterns 替换。
+	MaskSQLParams *bool
+}
+
+// redactor 为 Init 配置的脱敏规则，为空时不做脱敏。
+var redactor atomic.Pointer[Redactor]
+
+// Redactor 按字段名、内容正则与结构体标签替换日志中的敏感内容。
+//
+// 结构体字段可以通过 `log:"redact"` 标签标记为敏感，通过 `log:"-"` 从日志中省略；
+// 带有这类标签或敏感字段名的结构体会以分组形式输出，字段名优先取 json 标签。
+type Redactor struct {
+	keys          []string
+	pattern       *regexp.Regexp
+	maskSQLParams bool
+
+	// types 缓存结构体类型是否包含需要脱敏的字段。
+	types sync.Map
+}
+
+// NewRedactor 根据配置编译脱敏规则，mode 用于决定 MaskSQLParams 的默认值。
+func NewRedactor(cfg RedactConfig, mode string) (*Redactor, error) {
+	r := &Redactor{maskSQLParams: !isDevMode(mode)}
+	if cfg.MaskSQLParams != nil {
+		r.maskSQLParams = *cfg.MaskSQLParams
+	}
+
+	for _, key := range append(append([]string{}, DefaultRedactKeys...), cfg.Keys...) {
+		if key = normalizeKey(key); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+
+	patterns := make([]string, 0, len(DefaultRedactPatterns)+len(cfg.Patterns))
+	for _, p := range append(append([]string{}, DefaultRedactPatterns...), cfg.Patterns...) {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("logger: invalid redact pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, "(?:"+p+")")
+	}
+	if len(patterns) > 0 {
+		r.pattern = regexp.MustCompile(strings.Join(patterns, "|"))
+	}
+	return r, nil
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(key)
+}
+
+// SensitiveKey 判断字段名是否命中敏感字段规则。
+func (r *Redactor) SensitiveKey(key string) bool {
+	normalized := normalizeKey(key)
+	if normalized == "" {
+		return false
+	}
+	for _, k := range r.keys {
+		if strings.Contains(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString 将 s 中命中正则规则的内容替换为 RedactedValue。
+func (r *Redactor) RedactString(s string) string {
+	if r.pattern == nil || s == "" {
+		return s
+	}
+	return r.pattern.ReplaceAllString(s, RedactedValue)
+}
+
+// ReplaceAttr 对单个属性做脱敏，签名与 slog.HandlerOptions.ReplaceAttr 一致。
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup && r.SensitiveKey(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.RedactString(a.Value.String()))
+	case slog.KindGroup:
+		members := a.Value.Group()
+		redacted := make([]slog.Attr, 0, len(members))
+		nested := append(append([]string{}, groups...), a.Key)
+		for _, m := range members {
+			redacted = append(redacted, r.ReplaceAttr(nested, m))
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		a.Value = r.redactAny(groups, a.Value.Any())
+	}
+	return a
+}
+
+func (r *Redactor) redactAny(groups []string, v any) slog.Value {
+	if err, ok := v.(error); ok {
+		msg := err.Error()
+		if redacted := r.RedactString(msg); redacted != msg {
+			return slog.StringValue(redacted)
+		}
+		return slog.AnyValue(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return slog.AnyValue(v)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if r.structNeedsRedaction(rv.Type()) {
+			return r.structValue(groups, rv)
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			return r.mapValue(rv)
+		}
+	}
+	return slog.AnyValue(v)
+}
+
+// structValue 将结构体转换为分组，逐个字段应用标签与字段名规则。
+func (r *Redactor) structValue(groups []string, rv reflect.Value) slog.Value {
+	t := rv.Type()
+	attrs := make([]slog.Attr, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := fieldName(field)
+		if skip {
+			continue
+		}
+		if field.Tag.Get("log") == "redact" || r.SensitiveKey(name) {
+			attrs = append(attrs, slog.String(name, RedactedValue))
+			continue
+		}
+		attrs = append(attrs, r.ReplaceAttr(groups, slog.Any(name, rv.Field(i).Interface())))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (r *Redactor) mapValue(rv reflect.Value) slog.Value {
+	out := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		value := iter.Value().Interface()
+		switch v := value.(type) {
+		case string:
+			value = r.RedactString(v)
+		}
+		if r.SensitiveKey(key) {
+			value = RedactedValue
+		}
+		out[key] = value
+	}
+	return slog.AnyValue(out)
+}
+
+// fieldName 返回字段在日志中的名称；json 或 log 标签为 "-" 时跳过该字段。
+func fieldName(field reflect.StructField) (string, bool) {
+	if field.Tag.Get("log") == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", true
+	case "":
+		return field.Name, false
+	default:
+		return name, false
+	}
+}
+
+// structNeedsRedaction 判断结构体（含嵌套结构体字段）是否有带 log 标签或命中敏感字段名的字段。
+func (r *Redactor) structNeedsRedaction(t reflect.Type) bool {
+	if cached, ok := r.types.Load(t); ok {
+		return cached.(bool)
+	}
+	// 先记为 false，避免自引用类型无限递归。
+	r.types.Store(t, false)
+
+	needs := false
+	for i := 0; i < t.NumField() && !needs; i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Tag.Get("log") != "" || r.SensitiveKey(field.Name) {
+			needs = true
+			break
+		}
+		if name, skip := fieldName(field); !skip && r.SensitiveKey(name) {
+			needs = true
+			break
+		}
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			needs = r.structNeedsRedaction(ft)
+		}
+	}
+	r.types.Store(t, needs)
+	return needs
+}
+
+// RedactSQLParams 按当前脱敏规则处理 SQL 绑定参数，供数据库日志在拼接 SQL 前调用。
+// 启用 MaskSQLParams 时字符串等取值整体替换为 RedactedValue，数值、布尔与时间参数保留原值；
+// 否则仅替换字符串中命中正则规则的部分。未启用脱敏时原样返回。
+func RedactSQLParams(params []any) []any {
+	r := redactor.Load()
+	if r == nil || len(params) == 0 {
+		return params
+	}
+
+	out := make([]any, len(params))
+	for i, p := range params {
+		out[i] = r.redactSQLParam(p)
+	}
+	return out
+}
+
+func (r *Redactor) redactSQLParam(p any) any {
+	switch v := p.(type) {
+	case nil, bool, time.Time, *time.Time:
+		return p
+	case string:
+		if r.maskSQLParams {
+			return RedactedValue
+		}
+		return r.RedactString(v)
+	case []byte:
+		if r.maskSQLParams {
+			return RedactedValue
+		}
+		return r.RedactString(string(v))
+	}
+
+	switch reflect.ValueOf(p).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return p
+	}
+	if r.maskSQLParams {
+		return RedactedValue
+	}
+	return p
+}
+
+// redactHandler 在日志进入底层 Handler 之前应用当前的脱敏规则，
+// 因此开发模式的文本与 SQL 格式化输出同样经过脱敏。
+type redactHandler struct {
+	next   slog.Handler
+	groups []string
+}
+
+func newRedactHandler(next slog.Handler) slog.Handler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	r := redactor.Load()
+	if r == nil {
+		return h.next.Handle(ctx, record)
+	}
+
+	redacted := slog.NewRecord(record.Time, record.Level, r.RedactString(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(r.ReplaceAttr(h.groups, a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if r := redactor.Load(); r != nil {
+		redacted := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			redacted[i] = r.ReplaceAttr(h.groups, a)
+		}
+		attrs = redacted
+	}
+	return &redactHandler{next: h.next.WithAttrs(attrs), groups: h.groups}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &redactHandler{next: h.next.WithGroup(name), groups: append(append([]string{}, h.groups...), name)}
+}
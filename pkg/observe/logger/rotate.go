@@ -0,0 +1,400 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFilePrefix    = "service"
+	defaultFlushInterval = time.Second
+	writeBufferSize      = 64 * 1024
+	// backupTimeFormat 为轮转文件名中的时间戳格式，按字典序即可排序。
+	backupTimeFormat = "20060102T150405.000"
+	compressSuffix   = ".gz"
+)
+
+// RotateConfig 控制日志文件的轮转、压缩与保留策略。
+type RotateConfig struct {
+	// Directory 为日志文件所在目录。
+	Directory string
+	// Prefix 为文件名前缀：当前文件为 <Prefix>.log，轮转后的文件为 <Prefix>-<时间戳>.log[.gz]。
+	Prefix string
+	// MaxSize 为单个文件的最大字节数，超过后轮转；0 表示不按大小轮转。
+	MaxSize int64
+	// Interval 为按时间轮转的周期，以 UTC 对齐（例如 24h 即每天零点）；0 表示不按时间轮转。
+	Interval time.Duration
+	// Compress 指定是否以 gzip 压缩轮转后的文件。
+	Compress bool
+	// MaxAge 为轮转文件的最长保留时间；0 表示不按时间清理。
+	MaxAge time.Duration
+	// MaxTotalSize 为当前文件与轮转文件的总字节数上限，超过时从最旧的轮转文件开始删除；0 表示不限制。
+	MaxTotalSize int64
+	// FlushInterval 为缓冲区定期写入文件的间隔，默认 1 秒。
+	FlushInterval time.Duration
+}
+
+// rotatingWriter 是带缓冲的日志文件写入器，按大小与时间轮转，并在后台压缩、清理轮转文件。
+type rotatingWriter struct {
+	cfg RotateConfig
+
+	mu          sync.Mutex
+	file        *os.File
+	buf         *bufio.Writer
+	size        int64
+	periodStart time.Time
+	closed      bool
+
+	// mill 串行执行压缩与清理，避免阻塞写入。
+	mill     chan struct{}
+	stop     chan struct{}
+	finished sync.WaitGroup
+}
+
+func newRotatingWriter(cfg RotateConfig) (*rotatingWriter, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultFilePrefix
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &rotatingWriter{
+		cfg:  cfg,
+		mill: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+	w.finished.Add(2)
+	go w.flushLoop()
+	go w.millLoop()
+	// 启动时处理上次运行遗留的未压缩或过期文件。
+	w.triggerMill()
+	return w, nil
+}
+
+func (w *rotatingWriter) activePath() string {
+	return filepath.Join(w.cfg.Directory, w.cfg.Prefix+".log")
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.openLocked(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotateLocked(int64(len(p))) {
+		if err := w.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.buf.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) shouldRotateLocked(incoming int64) bool {
+	if w.cfg.MaxSize > 0 && w.size > 0 && w.size+incoming > w.cfg.MaxSize {
+		return true
+	}
+	if w.cfg.Interval > 0 && !time.Now().UTC().Truncate(w.cfg.Interval).Equal(w.periodStart) {
+		return true
+	}
+	return false
+}
+
+// openLocked 打开（或续写）当前日志文件。续写时以文件的修改时间确定所属周期，跨周期的文件会在下次写入时轮转。
+func (w *rotatingWriter) openLocked() error {
+	file, err := os.OpenFile(w.activePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.file = file
+	w.buf = bufio.NewWriterSize(file, writeBufferSize)
+	w.size = info.Size()
+	start := time.Now().UTC()
+	if w.size > 0 {
+		start = info.ModTime().UTC()
+	}
+	if w.cfg.Interval > 0 {
+		w.periodStart = start.Truncate(w.cfg.Interval)
+	}
+	return nil
+}
+
+func (w *rotatingWriter) rotateLocked() error {
+	if err := w.closeFileLocked(); err != nil {
+		return err
+	}
+
+	backup := w.backupPath(time.Now().UTC())
+	if err := os.Rename(w.activePath(), backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.openLocked(); err != nil {
+		return err
+	}
+	w.triggerMill()
+	return nil
+}
+
+// backupPath 返回以轮转时间命名的文件路径；同一毫秒内多次轮转时顺延时间戳，避免覆盖已有文件。
+func (w *rotatingWriter) backupPath(at time.Time) string {
+	for {
+		path := filepath.Join(w.cfg.Directory, fmt.Sprintf("%s-%s.log", w.cfg.Prefix, at.Format(backupTimeFormat)))
+		_, plainErr := os.Stat(path)
+		_, gzErr := os.Stat(path + compressSuffix)
+		if os.IsNotExist(plainErr) && os.IsNotExist(gzErr) {
+			return path
+		}
+		at = at.Add(time.Millisecond)
+	}
+}
+
+func (w *rotatingWriter) closeFileLocked() error {
+	if w.file == nil {
+		return nil
+	}
+	flushErr := w.buf.Flush()
+	syncErr := w.file.Sync()
+	closeErr := w.file.Close()
+	w.file = nil
+	w.buf = nil
+	for _, err := range []error{flushErr, syncErr, closeErr} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush 将缓冲区中的日志写入文件；当前文件已被外部工具（如 logrotate）移走或删除时，重新打开 <Prefix>.log 继续写入。
+func (w *rotatingWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.reopenIfMovedLocked()
+}
+
+// reopenIfMovedLocked 在 <Prefix>.log 已不是当前打开的文件时重新打开它。
+func (w *rotatingWriter) reopenIfMovedLocked() error {
+	opened, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(w.activePath())
+	if err == nil && os.SameFile(opened, info) {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.closeFileLocked(); err != nil {
+		return err
+	}
+	return w.openLocked()
+}
+
+// Close 写出缓冲区并关闭文件，同时等待后台的压缩与清理结束。
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.closeFileLocked()
+	w.mu.Unlock()
+
+	close(w.stop)
+	w.finished.Wait()
+	return err
+}
+
+func (w *rotatingWriter) flushLoop() {
+	defer w.finished.Done()
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Flush()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *rotatingWriter) triggerMill() {
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (w *rotatingWriter) millLoop() {
+	defer w.finished.Done()
+	for {
+		select {
+		case <-w.mill:
+			w.millRun()
+		case <-w.stop:
+			// 退出前处理最后一次轮转留下的文件。
+			select {
+			case <-w.mill:
+				w.millRun()
+			default:
+			}
+			return
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	size int64
+	time time.Time
+}
+
+// millRun 压缩未压缩的轮转文件，再按保留时间与总大小删除最旧的文件。
+// 这里的错误无法再写入日志，只能输出到标准错误。
+func (w *rotatingWriter) millRun() {
+	backups, err := w.listBackups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: list rotated files: %v\n", err)
+		return
+	}
+
+	if w.cfg.Compress {
+		for i, b := range backups {
+			if strings.HasSuffix(b.path, compressSuffix) {
+				continue
+			}
+			compressed, err := compressFile(b.path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logger: compress %s: %v\n", b.path, err)
+				continue
+			}
+			compressed.time = b.time
+			backups[i] = compressed
+		}
+	}
+
+	var total int64
+	if info, err := os.Stat(w.activePath()); err == nil {
+		total = info.Size()
+	}
+	cutoff := time.Now().Add(-w.cfg.MaxAge)
+	// backups 按时间从新到旧排列，超出限制时删除剩余的旧文件。
+	for _, b := range backups {
+		total += b.size
+		expired := w.cfg.MaxAge > 0 && b.time.Before(cutoff)
+		oversize := w.cfg.MaxTotalSize > 0 && total > w.cfg.MaxTotalSize
+		if expired || oversize {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "logger: remove %s: %v\n", b.path, err)
+			}
+		}
+	}
+}
+
+// listBackups 返回目录中属于当前前缀的轮转文件，按轮转时间从新到旧排序。
+func (w *rotatingWriter) listBackups() ([]backupFile, error) {
+	entries, err := os.ReadDir(w.cfg.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := w.cfg.Prefix + "-"
+	var backups []backupFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		stamp = strings.TrimSuffix(stamp, compressSuffix)
+		stamp, ok = strings.CutSuffix(stamp, ".log")
+		if !ok {
+			continue
+		}
+		rotatedAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(w.cfg.Directory, name), size: info.Size(), time: rotatedAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+	return backups, nil
+}
+
+// compressFile 将 path 压缩为 path.gz 并删除原文件。
+func compressFile(path string) (backupFile, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return backupFile{}, err
+	}
+	defer src.Close()
+
+	target := path + compressSuffix
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return backupFile{}, err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, copyErr := io.Copy(gz, src)
+	gzErr := gz.Close()
+	closeErr := dst.Close()
+	for _, err := range []error{copyErr, gzErr, closeErr} {
+		if err != nil {
+			_ = os.Remove(target)
+			return backupFile{}, err
+		}
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return backupFile{}, err
+	}
+	if err := os.Remove(path); err != nil {
+		return backupFile{}, err
+	}
+	return backupFile{path: target, size: info.Size()}, nil
+}
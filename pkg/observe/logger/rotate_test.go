@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWriter(t *testing.T, cfg RotateConfig) *rotatingWriter {
+	t.Helper()
+	if cfg.Directory == "" {
+		cfg.Directory = t.TempDir()
+	}
+	// 由测试显式调用 Flush，避免后台定时落盘干扰断言。
+	cfg.FlushInterval = time.Hour
+	w, err := newRotatingWriter(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func writeLine(t *testing.T, w *rotatingWriter, line string) {
+	t.Helper()
+	_, err := w.Write([]byte(line + "\n"))
+	require.NoError(t, err)
+}
+
+// backupNames 返回目录中的轮转文件名，按轮转时间从旧到新排序。
+func backupNames(t *testing.T, dir, prefix string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), prefix+"-") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, compressSuffix) {
+		gz, err := gzip.NewReader(file)
+		require.NoError(t, err)
+		defer gz.Close()
+		reader = gz
+	}
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(content)
+}
+
+// createBackup 写入一个轮转时间为 at 的轮转文件。
+func createBackup(t *testing.T, dir, prefix string, at time.Time, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, prefix+"-"+at.UTC().Format(backupTimeFormat)+".log")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// TestRotatingWriterSize 验证写入后超过 MaxSize 时先轮转再写入，单次写入不会被拆分。
+func TestRotatingWriterSize(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWriter(t, RotateConfig{Directory: dir, Prefix: "app", MaxSize: 20})
+
+	writeLine(t, w, "first-line") // 11 字节
+	writeLine(t, w, "second")     // 累计 18 字节，不轮转
+	writeLine(t, w, "third-line") // 超过 20 字节，轮转后写入新文件
+	writeLine(t, w, "a-line-longer-than-max-size")
+	require.NoError(t, w.Close())
+
+	backups := backupNames(t, dir, "app")
+	require.Len(t, backups, 2)
+	require.Equal(t, "first-line\nsecond\n", readLog(t, filepath.Join(dir, backups[0])))
+	require.Equal(t, "third-line\n", readLog(t, filepath.Join(dir, backups[1])))
+	require.Equal(t, "a-line-longer-than-max-size\n", readLog(t, filepath.Join(dir, "app.log")))
+}
+
+// TestRotatingWriterInterval 验证续写上一个周期留下的文件时，下一次写入前先轮转。
+func TestRotatingWriterInterval(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(active, []byte("yesterday\n"), 0o644))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(active, old, old))
+
+	w := newTestWriter(t, RotateConfig{Directory: dir, Prefix: "app", Interval: 24 * time.Hour})
+	writeLine(t, w, "today")
+	writeLine(t, w, "still today")
+	require.NoError(t, w.Close())
+
+	backups := backupNames(t, dir, "app")
+	require.Len(t, backups, 1)
+	require.Equal(t, "yesterday\n", readLog(t, filepath.Join(dir, backups[0])))
+	require.Equal(t, "today\nstill today\n", readLog(t, active))
+}
+
+// TestRotatingWriterCurrentPeriod 验证续写当前周期内的文件时不轮转。
+func TestRotatingWriterCurrentPeriod(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(active, []byte("earlier\n"), 0o644))
+
+	w := newTestWriter(t, RotateConfig{Directory: dir, Prefix: "app", Interval: 24 * time.Hour})
+	writeLine(t, w, "later")
+	require.NoError(t, w.Close())
+
+	require.Empty(t, backupNames(t, dir, "app"))
+	require.Equal(t, "earlier\nlater\n", readLog(t, active))
+}
+
+// TestRotatingWriterCompress 验证轮转文件被压缩为 .gz 并删除原文件，启动时遗留的未压缩文件同样会被压缩。
+func TestRotatingWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	leftover := createBackup(t, dir, "app", time.Now().Add(-time.Hour), "leftover\n")
+
+	w := newTestWriter(t, RotateConfig{Directory: dir, Prefix: "app", MaxSize: 10, Compress: true})
+	writeLine(t, w, "rotated")
+	writeLine(t, w, "current")
+	require.NoError(t, w.Close())
+
+	backups := backupNames(t, dir, "app")
+	require.Len(t, backups, 2)
+	for _, name := range backups {
+		require.True(t, strings.HasSuffix(name, ".log"+compressSuffix), name)
+	}
+	require.Equal(t, filepath.Base(leftover)+compressSuffix, backups[0])
+	require.Equal(t, "leftover\n", readLog(t, filepath.Join(dir, backups[0])))
+	require.Equal(t, "rotated\n", readLog(t, filepath.Join(dir, backups[1])))
+	require.Equal(t, "current\n", readLog(t, filepath.Join(dir, "app.log")))
+}
+
+// TestRotatingWriterRetention 验证按 MaxAge 与 MaxTotalSize 从最旧的轮转文件开始清理，且不影响其他前缀的文件。
+func TestRotatingWriterRetention(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name     string
+		cfg      RotateConfig
+		active   string
+		wantKept []int
+	}{
+		{name: "不限制时全部保留", wantKept: []int{0, 1, 2, 3}},
+		{name: "按保留时间清理", cfg: RotateConfig{MaxAge: 36 * time.Hour}, wantKept: []int{0, 1}},
+		{name: "按总大小清理", cfg: RotateConfig{MaxTotalSize: 25}, wantKept: []int{0, 1}},
+		{name: "总大小包含当前文件", cfg: RotateConfig{MaxTotalSize: 35}, active: "0123456789", wantKept: []int{0, 1}},
+		{name: "两个条件同时生效", cfg: RotateConfig{MaxAge: 72 * time.Hour, MaxTotalSize: 15}, wantKept: []int{0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// 四个 10 字节的轮转文件，分别为 1 小时、1 天、2 天与 4 天前轮转。
+			paths := []string{
+				createBackup(t, dir, "app", now.Add(-time.Hour), "0123456789"),
+				createBackup(t, dir, "app", now.Add(-24*time.Hour), "0123456789"),
+				createBackup(t, dir, "app", now.Add(-48*time.Hour), "0123456789"),
+				createBackup(t, dir, "app", now.Add(-96*time.Hour), "0123456789"),
+			}
+			other := createBackup(t, dir, "access", now.Add(-96*time.Hour), "0123456789")
+			if tc.active != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "app.log"), []byte(tc.active), 0o644))
+			}
+
+			cfg := tc.cfg
+			cfg.Directory = dir
+			cfg.Prefix = "app"
+			w := newTestWriter(t, cfg)
+			require.NoError(t, w.Close())
+
+			for i, path := range paths {
+				_, err := os.Stat(path)
+				if slices.Contains(tc.wantKept, i) {
+					require.NoError(t, err, "第 %d 个轮转文件应保留", i)
+				} else {
+					require.True(t, os.IsNotExist(err), "第 %d 个轮转文件应被删除", i)
+				}
+			}
+			_, err := os.Stat(other)
+			require.NoError(t, err)
+		})
+	}
+}
+
+// TestRotatingWriterExternalRotation 验证当前文件被外部工具移走或删除后，下一次落盘时重新打开原路径。
+func TestRotatingWriterExternalRotation(t *testing.T) {
+	cases := []struct {
+		name   string
+		rotate func(active string) error
+		moved  bool
+	}{
+		{name: "文件被移走", rotate: func(active string) error { return os.Rename(active, active+".1") }, moved: true},
+		{name: "文件被删除", rotate: os.Remove},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			active := filepath.Join(dir, "app.log")
+			w := newTestWriter(t, RotateConfig{Directory: dir, Prefix: "app"})
+
+			writeLine(t, w, "before")
+			require.NoError(t, w.Flush())
+			require.NoError(t, tc.rotate(active))
+
+			writeLine(t, w, "buffered")
+			require.NoError(t, w.Flush())
+			writeLine(t, w, "after")
+			require.NoError(t, w.Close())
+
+			require.Equal(t, "after\n", readLog(t, active))
+			if tc.moved {
+				require.Equal(t, "before\nbuffered\n", readLog(t, active+".1"))
+			}
+		})
+	}
+}
+
+// TestRotatingWriterClosed 验证关闭后写入返回错误，重复关闭无副作用。
+func TestRotatingWriterClosed(t *testing.T) {
+	w := newTestWriter(t, RotateConfig{})
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	_, err := w.Write([]byte("late\n"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
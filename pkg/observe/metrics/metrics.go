@@ -1,28 +1,69 @@
 package metrics
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 var (
 	mu       sync.RWMutex
 	registry *prometheus.Registry
+	// pending 记录通过 Register 登记的指标，InitRegistry 创建注册表时统一注册。
+	pending []prometheus.Collector
 )
 
-// NewRegistry 返回一个新的 Prometheus Registry。
+// NewRegistry 返回一个新的 Prometheus Registry，已注册 Go 运行时与进程指标。
 func NewRegistry() *prometheus.Registry {
-	return prometheus.NewRegistry()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
 }
 
-// InitRegistry 构造指标注册表并设置为全局实例。
+// InitRegistry 构造指标注册表，注册已登记的模块指标并设置为全局实例。
 func InitRegistry() *prometheus.Registry {
 	reg := NewRegistry()
-	SetDefault(reg)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range pending {
+		mustRegister(reg, c)
+	}
+	registry = reg
 	return reg
 }
 
+// Register 登记一个模块指标并原样返回，便于在包级变量中声明：
+//
+//	var logins = metrics.Register(prometheus.NewCounterVec(...))
+//
+// 全局注册表已存在时立即注册，否则在 InitRegistry 时注册。
+func Register[C prometheus.Collector](c C) C {
+	mu.Lock()
+	defer mu.Unlock()
+	pending = append(pending, c)
+	if registry != nil {
+		mustRegister(registry, c)
+	}
+	return c
+}
+
+// mustRegister 注册指标，同一指标重复注册时忽略。
+func mustRegister(reg prometheus.Registerer, c prometheus.Collector) {
+	if err := reg.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			return
+		}
+		panic(err)
+	}
+}
+
 // SetDefault 将指标注册表设置为全局默认值。
 func SetDefault(reg *prometheus.Registry) {
 	mu.Lock()
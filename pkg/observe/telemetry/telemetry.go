@@ -2,20 +2,63 @@ package telemetry
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"sync"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/semconv/v1.24.0"
+	"google.golang.org/grpc/credentials"
 )
 
-// Config 用于控制 OTLP 链路追踪的初始化行为。
+// 支持的导出器。
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+	ExporterFile     = "file"
+)
+
+// Config 用于控制链路追踪的初始化行为。
 type Config struct {
 	ServiceName string
 	Endpoint    string
 	Enabled     bool
+	// Exporter 选择导出方式：otlp-grpc（默认）、otlp-http，以及用于本地调试的 stdout 与 file。
+	Exporter string
+	// FilePath 为 file 导出器写入的文件路径，按 JSON 逐条追加。
+	FilePath string
+	// Insecure 为 true 时 OTLP 导出不使用 TLS。
+	Insecure bool
+	// TLS 为 OTLP 导出使用的 TLS 选项，仅在 Insecure 为 false 时生效。
+	TLS TLSConfig
+	// SampleRatio 为根 Span 的采样比例，取值 0-1；大于等于 1 时全部采样，小于等于 0 时不采样。
+	SampleRatio float64
+	// ParentBased 为 true 时，已有上游 Span 的请求沿用上游的采样决定，仅根 Span 按 SampleRatio 采样。
+	ParentBased bool
+}
+
+// TLSConfig 描述连接 OTLP 采集端时的 TLS 选项。
+type TLSConfig struct {
+	// CAFile 为校验服务端证书的 CA 证书文件，为空时使用系统根证书。
+	CAFile string
+	// CertFile 与 KeyFile 为客户端证书，用于双向 TLS，可选。
+	CertFile string
+	KeyFile  string
+	// ServerName 覆盖用于校验证书的服务端名称，可选。
+	ServerName string
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于测试环境。
+	InsecureSkipVerify bool
 }
 
 // Provider 封装追踪提供器的关闭逻辑。
@@ -29,31 +72,142 @@ var (
 )
 
 // NewProvider 根据配置初始化链路追踪。
+// 无论是否启用，都会注册 W3C Trace Context 与 Baggage 传播器，用于解析入站的 traceparent 并向出站调用传递。
 func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	if !cfg.Enabled {
 		return &Provider{}, nil
 	}
 
-	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithInsecure())
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
-		semconv.SchemaURL,
+	// 服务名使用无 Schema 的资源合并，避免与 SDK 默认资源的 Schema 版本冲突。
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
 		semconv.ServiceName(cfg.ServiceName),
 	))
 	if err != nil {
 		return nil, err
 	}
 
-	tp := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
+	opts := []trace.TracerProviderOption{
 		trace.WithResource(res),
-	)
+		trace.WithSampler(newSampler(cfg)),
+	}
+	var closeFile func() error
+	switch exporter := strings.ToLower(strings.TrimSpace(cfg.Exporter)); exporter {
+	case "", ExporterOTLPGRPC, ExporterOTLPHTTP:
+		exp, err := newOTLPExporter(ctx, exporter, cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, trace.WithBatcher(exp))
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		// 本地调试时同步导出，Span 结束后立即可见。
+		opts = append(opts, trace.WithSyncer(exp))
+	case ExporterFile:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("telemetry: file exporter requires a file path")
+		}
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: open trace file: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		closeFile = file.Close
+		opts = append(opts, trace.WithSyncer(exp))
+	default:
+		return nil, fmt.Errorf("telemetry: unsupported exporter %q", cfg.Exporter)
+	}
+
+	tp := trace.NewTracerProvider(opts...)
 	otel.SetTracerProvider(tp)
 
-	return &Provider{shutdown: tp.Shutdown}, nil
+	return &Provider{shutdown: func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+		return err
+	}}, nil
+}
+
+// newSampler 根据采样比例构造采样器，ParentBased 时沿用上游的采样决定。
+func newSampler(cfg Config) trace.Sampler {
+	var root trace.Sampler
+	switch {
+	case cfg.SampleRatio >= 1:
+		root = trace.AlwaysSample()
+	case cfg.SampleRatio <= 0:
+		root = trace.NeverSample()
+	default:
+		root = trace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	if cfg.ParentBased {
+		return trace.ParentBased(root)
+	}
+	return root
+}
+
+func newOTLPExporter(ctx context.Context, exporter string, cfg Config) (trace.SpanExporter, error) {
+	var tlsCfg *tls.Config
+	if !cfg.Insecure {
+		var err error
+		if tlsCfg, err = cfg.TLS.build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if exporter == ExporterOTLPHTTP {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if tlsCfg == nil {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if tlsCfg == nil {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// build 根据配置构造 tls.Config。
+func (c TLSConfig) build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("telemetry: no certificates found in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
 }
 
 // Init 构造追踪提供器并注册为全局实例。
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	applogger "github.com/Jayleonc/service/pkg/observe/logger"
+)
+
+// Transport 在出站 HTTP 请求中写入当前请求的 X-Request-ID 与 W3C traceparent，
+// 使下游服务的日志和链路能与本服务关联。请求已自带 X-Request-ID 时保持不变。
+type Transport struct {
+	// Base 为实际发送请求的 RoundTripper，为空时使用 http.DefaultTransport。
+	Base http.RoundTripper
+}
+
+// NewTransport 包装 base 并返回传播请求 ID 与链路上下文的 Transport。
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// NewHTTPClient 返回使用 Transport 的 HTTP 客户端，调用方需通过 http.NewRequestWithContext 传入请求上下文。
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: NewTransport(nil)}
+}
+
+// RoundTrip 实现 http.RoundTripper。
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	// RoundTripper 不应修改调用方的请求，因此先克隆再写入请求头。
+	out := req.Clone(ctx)
+	if out.Header.Get(applogger.RequestIDHeader) == "" {
+		if id := applogger.RequestIDFromContext(ctx); id != "" {
+			out.Header.Set(applogger.RequestIDHeader, id)
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out.Header))
+
+	return base.RoundTrip(out)
+}
@@ -0,0 +1,230 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+
+	"github.com/Jayleonc/service/pkg/i18n"
+)
+
+// typeMismatchKey 为 JSON 类型不匹配错误的翻译键。
+const typeMismatchKey = "type"
+
+// FieldError 描述单个字段的校验失败，可直接序列化返回给客户端。
+type FieldError struct {
+	// Field 为字段的 JSON 路径，例如 filters[0].field。
+	Field string `json:"field"`
+	// Rule 为未通过的校验规则，例如 required、email；类型不匹配时为 type。
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+	// Message 为按请求语言本地化后的错误描述。
+	Message string `json:"message"`
+}
+
+var (
+	translatorsOnce sync.Once
+	translators     map[string]ut.Translator
+)
+
+// sharedTranslator 允许同一组翻译注册到多个校验器实例上：
+// 翻译文本只需写入一次，重复注册时忽略冲突，但每个校验器仍会登记自己的翻译函数。
+type sharedTranslator struct {
+	ut.Translator
+}
+
+func (t *sharedTranslator) Add(key any, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+func (t *sharedTranslator) AddCardinal(key any, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+func (t *sharedTranslator) AddOrdinal(key any, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+func (t *sharedTranslator) AddRange(key any, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+func ignoreConflict(err error) error {
+	var conflict *ut.ErrConflictingTranslation
+	if errors.As(err, &conflict) {
+		return nil
+	}
+	return err
+}
+
+func loadTranslators() map[string]ut.Translator {
+	translatorsOnce.Do(func() {
+		english := en.New()
+		uni := ut.New(english, english, zh.New())
+
+		translators = make(map[string]ut.Translator, 2)
+		for locale, messages := range map[string]map[string]string{
+			"en": {
+				typeMismatchKey: "{0} must be a valid {1}",
+				"type.string":   "string",
+				"type.number":   "number",
+				"type.boolean":  "boolean",
+				"type.array":    "array",
+				"type.object":   "object",
+				"type.value":    "value",
+			},
+			"zh": {
+				typeMismatchKey: "{0}必须是有效的{1}",
+				"type.string":   "字符串",
+				"type.number":   "数字",
+				"type.boolean":  "布尔值",
+				"type.array":    "数组",
+				"type.object":   "对象",
+				"type.value":    "值",
+			},
+		} {
+			trans, _ := uni.GetTranslator(locale)
+			shared := &sharedTranslator{Translator: trans}
+			for key, text := range messages {
+				if err := shared.Add(key, text, false); err != nil {
+					panic(err)
+				}
+			}
+			translators[locale] = shared
+		}
+	})
+	return translators
+}
+
+// registerTranslations 让校验器以 JSON 字段名报告错误，并注册英文与中文的默认错误信息。
+func registerTranslations(v *validator.Validate) {
+	v.RegisterTagNameFunc(fieldName)
+
+	trans := loadTranslators()
+	if err := entranslations.RegisterDefaultTranslations(v, trans["en"]); err != nil {
+		panic(err)
+	}
+	if err := zhtranslations.RegisterDefaultTranslations(v, trans["zh"]); err != nil {
+		panic(err)
+	}
+}
+
+// fieldName 优先使用 json 标签，其次使用 form 标签作为错误中的字段名。
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Translator 返回 Accept-Language 协商出的语言对应的翻译器，协商规则见 i18n.Negotiate。
+func Translator(acceptLanguage string) ut.Translator {
+	return loadTranslators()[i18n.Negotiate(acceptLanguage)]
+}
+
+// Translate 将请求解析或校验失败的错误转换为字段级错误列表，消息按 acceptLanguage 本地化。
+// 无法定位到具体字段的错误（如 JSON 语法错误）返回 nil。
+func Translate(err error, acceptLanguage string) []FieldError {
+	if err == nil {
+		return nil
+	}
+	trans := Translator(acceptLanguage)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		details := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			details = append(details, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fe.Translate(trans),
+			})
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		field := jsonFieldPath(typeErr.Field)
+		expected := jsonTypeName(typeErr.Type)
+		message := typeErr.Error()
+		if typeName, err := trans.T("type." + expected); err == nil {
+			if translated, err := trans.T(typeMismatchKey, field, typeName); err == nil {
+				message = translated
+			}
+		}
+		return []FieldError{{
+			Field:   field,
+			Rule:    typeMismatchKey,
+			Param:   expected,
+			Message: message,
+		}}
+	}
+	return nil
+}
+
+// fieldPath 去掉命名空间中的顶层结构体名，例如 ListUsersRequest.filters[0].field -> filters[0].field。
+func fieldPath(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return namespace
+}
+
+// jsonFieldPath 将 encoding/json 报告的字段路径转换为与校验错误一致的形式，例如 items.0.qty -> items[0].qty。
+func jsonFieldPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
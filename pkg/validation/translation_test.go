@@ -0,0 +1,155 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type orderItem struct {
+	SKU string `json:"sku" validate:"required"`
+	Qty int    `json:"qty" validate:"min=1"`
+}
+
+type orderRequest struct {
+	Email   string `json:"email" validate:"required,email"`
+	Profile struct {
+		Name string `json:"name" validate:"required,max=3"`
+		Age  int    `json:"age"`
+	} `json:"profile"`
+	Items []orderItem `json:"items" validate:"dive"`
+	Tags  []string    `form:"tags" validate:"dive,required"`
+	Note  string      `json:"note" binding:"max=5"`
+}
+
+// TestTranslateValidationErrors 验证校验错误按 JSON 路径定位字段，消息按请求语言本地化。
+func TestTranslateValidationErrors(t *testing.T) {
+	req := orderRequest{Email: "not-an-email", Items: []orderItem{{SKU: "a", Qty: 1}, {Qty: 0}}, Tags: []string{"x", ""}, Note: "too long"}
+	req.Profile.Name = "abcd"
+
+	v := NewDualTagValidator()
+	bindingErr := v.ValidateStruct(&req)
+	require.Error(t, bindingErr)
+
+	// binding 标签先于 validate 标签校验，两者任一失败即返回。
+	require.Equal(t, []FieldError{{Field: "note", Rule: "max", Param: "5", Message: "note must be a maximum of 5 characters in length"}}, Translate(bindingErr, "en"))
+
+	req.Note = ""
+	err := v.ValidateStruct(&req)
+	require.Error(t, err)
+
+	cases := []struct {
+		name           string
+		acceptLanguage string
+		want           []FieldError
+	}{
+		{
+			name:           "英文",
+			acceptLanguage: "en-US",
+			want: []FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "profile.name", Rule: "max", Param: "3", Message: "name must be a maximum of 3 characters in length"},
+				{Field: "items[1].sku", Rule: "required", Message: "sku is a required field"},
+				{Field: "items[1].qty", Rule: "min", Param: "1", Message: "qty must be 1 or greater"},
+				{Field: "tags[1]", Rule: "required", Message: "tags[1] is a required field"},
+			},
+		},
+		{
+			name:           "中文",
+			acceptLanguage: "zh-CN,zh;q=0.9",
+			want: []FieldError{
+				{Field: "email", Rule: "email", Message: "email必须是一个有效的邮箱"},
+				{Field: "profile.name", Rule: "max", Param: "3", Message: "name长度不能超过3个字符"},
+				{Field: "items[1].sku", Rule: "required", Message: "sku为必填字段"},
+				{Field: "items[1].qty", Rule: "min", Param: "1", Message: "qty最小只能为1"},
+				{Field: "tags[1]", Rule: "required", Message: "tags[1]为必填字段"},
+			},
+		},
+		{
+			name:           "不支持的语言回退英文",
+			acceptLanguage: "fr",
+			want: []FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "profile.name", Rule: "max", Param: "3", Message: "name must be a maximum of 3 characters in length"},
+				{Field: "items[1].sku", Rule: "required", Message: "sku is a required field"},
+				{Field: "items[1].qty", Rule: "min", Param: "1", Message: "qty must be 1 or greater"},
+				{Field: "tags[1]", Rule: "required", Message: "tags[1] is a required field"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Translate(err, tc.acceptLanguage))
+		})
+	}
+}
+
+// TestTranslateJSONErrors 验证 JSON 类型不匹配被定位到字段，语法错误等无法定位的错误返回 nil。
+func TestTranslateJSONErrors(t *testing.T) {
+	cases := []struct {
+		name           string
+		body           string
+		acceptLanguage string
+		want           []FieldError
+	}{
+		{
+			name: "顶层字段类型不匹配",
+			body: `{"email": 1}`,
+			want: []FieldError{{Field: "email", Rule: "type", Param: "string", Message: "email must be a valid string"}},
+		},
+		{
+			name:           "嵌套字段类型不匹配",
+			body:           `{"profile": {"age": "old"}}`,
+			acceptLanguage: "zh",
+			want:           []FieldError{{Field: "profile.age", Rule: "type", Param: "number", Message: "profile.age必须是有效的数字"}},
+		},
+		{
+			name: "数组元素字段类型不匹配",
+			body: `{"items": [{"sku": "a"}, {"qty": true}]}`,
+			want: []FieldError{{Field: "items[1].qty", Rule: "type", Param: "number", Message: "items[1].qty must be a valid number"}},
+		},
+		{
+			name:           "期望数组",
+			body:           `{"items": {"sku": "a"}}`,
+			acceptLanguage: "zh",
+			want:           []FieldError{{Field: "items", Rule: "type", Param: "array", Message: "items必须是有效的数组"}},
+		},
+		{name: "期望对象", body: `{"profile": "x"}`, want: []FieldError{{Field: "profile", Rule: "type", Param: "object", Message: "profile must be a valid object"}}},
+		{name: "语法错误", body: `{"email": `},
+		{name: "请求体类型不匹配", body: `[1, 2]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var req orderRequest
+			err := json.Unmarshal([]byte(tc.body), &req)
+			require.Error(t, err)
+			require.Equal(t, tc.want, Translate(err, tc.acceptLanguage))
+		})
+	}
+
+	require.Nil(t, Translate(nil, "en"))
+	require.Nil(t, Translate(errors.New("boom"), "en"))
+}
+
+// TestJSONFieldPath 验证 encoding/json 的字段路径转换为与校验错误一致的形式。
+func TestJSONFieldPath(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "email", want: "email"},
+		{in: "profile.age", want: "profile.age"},
+		{in: "items.0.qty", want: "items[0].qty"},
+		{in: "matrix.1.2", want: "matrix[1][2]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			require.Equal(t, tc.want, jsonFieldPath(tc.in))
+		})
+	}
+}
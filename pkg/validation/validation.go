@@ -16,6 +16,7 @@ var (
 // Init 创建新的校验器实例并设置为全局单例。
 func Init() *validator.Validate {
 	v := validator.New()
+	registerTranslations(v)
 	SetDefault(v)
 	return v
 }
@@ -47,9 +48,11 @@ type dualTagValidator struct {
 func NewDualTagValidator() binding.StructValidator {
 	v1 := validator.New()
 	v1.SetTagName("binding")
+	registerTranslations(v1)
 
 	v2 := validator.New()
 	v2.SetTagName("validate")
+	registerTranslations(v2)
 
 	return &dualTagValidator{
 		bindingV:  v1,
@@ -0,0 +1,152 @@
+package xerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Jayleonc/service/pkg/i18n"
+)
+
+var (
+	catalogMu sync.RWMutex
+	// registered 记录通过 New 定义的错误码及其默认消息。
+	registered = make(map[int]string)
+	// catalogs 按语言保存错误码对应的本地化消息。
+	catalogs = make(map[string]map[int]string)
+	// problems 记录注册阶段发现的重复定义，由 CheckCatalog 统一报告。
+	problems []string
+)
+
+func register(code int, message string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if previous, ok := registered[code]; ok {
+		problems = append(problems, fmt.Sprintf("error code %d registered twice (%q and %q)", code, previous, message))
+		return
+	}
+	registered[code] = message
+}
+
+// RegisterCatalog 从 fsys 的 dir 目录加载 <locale>.json 形式的消息目录，文件内容为错误码到消息的映射：
+//
+//	{"2002": "email already exists"}
+//
+// 通常由模块在 errors.go 中通过 go:embed 嵌入并在 init 中调用。
+func RegisterCatalog(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("xerr: no catalog files in %q", dir)
+	}
+
+	for _, file := range files {
+		locale := strings.TrimSuffix(path.Base(file), ".json")
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var entries map[string]string
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return fmt.Errorf("xerr: parse catalog %s: %w", file, err)
+		}
+
+		messages := make(map[int]string, len(entries))
+		for key, message := range entries {
+			code, err := strconv.Atoi(key)
+			if err != nil {
+				return fmt.Errorf("xerr: catalog %s: key %q is not an error code", file, key)
+			}
+			messages[code] = message
+		}
+		addCatalog(file, locale, messages)
+	}
+	return nil
+}
+
+// MustRegisterCatalog 与 RegisterCatalog 相同，但在目录无法加载时直接 panic。
+func MustRegisterCatalog(fsys fs.FS, dir string) {
+	if err := RegisterCatalog(fsys, dir); err != nil {
+		panic(err)
+	}
+}
+
+func addCatalog(file, locale string, messages map[int]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = make(map[int]string, len(messages))
+		catalogs[locale] = catalog
+	}
+	for code, message := range messages {
+		if _, exists := catalog[code]; exists {
+			problems = append(problems, fmt.Sprintf("error code %d translated twice for locale %q (%s)", code, locale, file))
+			continue
+		}
+		catalog[code] = message
+	}
+}
+
+// CheckCatalog 校验错误码与消息目录是否一致：错误码不得重复定义，
+// 且每个已定义的错误码都必须在 i18n.SupportedLocales 的全部语言中提供翻译。
+// 服务启动时调用，任何问题都会阻止启动。
+func CheckCatalog() error {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	issues := slices.Clone(problems)
+
+	codes := make([]int, 0, len(registered))
+	for code := range registered {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	for _, locale := range i18n.SupportedLocales {
+		catalog := catalogs[locale]
+		for _, code := range codes {
+			if _, ok := catalog[code]; !ok {
+				issues = append(issues, fmt.Sprintf("error code %d has no %q translation", code, locale))
+			}
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return errors.New("xerr: " + strings.Join(issues, "; "))
+}
+
+// Localize 返回错误在 Accept-Language 协商出的语言下的消息。
+// 通过 WithMessage 定制的消息原样返回；目录中缺少翻译时依次回退到默认语言与定义时的消息。
+func (e *Error) Localize(acceptLanguage string) string {
+	if e == nil {
+		return ""
+	}
+	if e.custom {
+		return e.Message
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	for _, locale := range []string{i18n.Negotiate(acceptLanguage), i18n.DefaultLocale} {
+		if message, ok := catalogs[locale][e.Code]; ok {
+			return message
+		}
+	}
+	return e.Message
+}
@@ -0,0 +1,20 @@
+package xerr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	// 导入全部定义了错误码的模块，使其错误码与消息目录在校验前完成注册。
+	_ "github.com/Jayleonc/service/internal/auth"
+	_ "github.com/Jayleonc/service/internal/middleware"
+	_ "github.com/Jayleonc/service/internal/rbac"
+	_ "github.com/Jayleonc/service/internal/system"
+	_ "github.com/Jayleonc/service/internal/user"
+	"github.com/Jayleonc/service/pkg/xerr"
+)
+
+// TestModuleCatalogs 确认各模块的错误码没有重复定义，且在所有支持的语言中都有翻译。
+func TestModuleCatalogs(t *testing.T) {
+	require.NoError(t, xerr.CheckCatalog())
+}
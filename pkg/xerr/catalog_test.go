@@ -0,0 +1,200 @@
+package xerr
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// isolateCatalog 清空全局的错误码登记与消息目录，并在测试结束后恢复，使测试中定义的错误码不影响其他测试。
+func isolateCatalog(t *testing.T) {
+	t.Helper()
+
+	catalogMu.Lock()
+	savedRegistered := registered
+	savedCatalogs := catalogs
+	savedProblems := problems
+	registered = make(map[int]string)
+	catalogs = make(map[string]map[int]string)
+	problems = nil
+	catalogMu.Unlock()
+
+	t.Cleanup(func() {
+		catalogMu.Lock()
+		registered = savedRegistered
+		catalogs = savedCatalogs
+		problems = savedProblems
+		catalogMu.Unlock()
+	})
+}
+
+func catalogFS(files map[string]string) fstest.MapFS {
+	fsys := make(fstest.MapFS, len(files))
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+// TestCheckCatalog 验证重复定义的错误码、重复的翻译与缺失的翻译都会被报告。
+func TestCheckCatalog(t *testing.T) {
+	cases := []struct {
+		name    string
+		prepare func(t *testing.T)
+		wantErr []string
+	}{
+		{
+			name: "目录完整",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				New(9002, "second")
+				require.NoError(t, RegisterCatalog(catalogFS(map[string]string{
+					"locales/en.json": `{"9001": "first", "9002": "second"}`,
+					"locales/zh.json": `{"9001": "第一", "9002": "第二"}`,
+				}), "locales"))
+			},
+		},
+		{
+			name: "错误码重复定义",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				New(9001, "again")
+				require.NoError(t, RegisterCatalog(catalogFS(map[string]string{
+					"locales/en.json": `{"9001": "first"}`,
+					"locales/zh.json": `{"9001": "第一"}`,
+				}), "locales"))
+			},
+			wantErr: []string{`error code 9001 registered twice ("first" and "again")`},
+		},
+		{
+			name: "缺少中文翻译",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				New(9002, "second")
+				require.NoError(t, RegisterCatalog(catalogFS(map[string]string{
+					"locales/en.json": `{"9001": "first", "9002": "second"}`,
+					"locales/zh.json": `{"9001": "第一"}`,
+				}), "locales"))
+			},
+			wantErr: []string{`error code 9002 has no "zh" translation`},
+		},
+		{
+			name: "缺少整个语言目录",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				require.NoError(t, RegisterCatalog(catalogFS(map[string]string{
+					"locales/zh.json": `{"9001": "第一"}`,
+				}), "locales"))
+			},
+			wantErr: []string{`error code 9001 has no "en" translation`},
+		},
+		{
+			name: "不同模块重复翻译同一错误码",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				fsys := catalogFS(map[string]string{
+					"a/en.json": `{"9001": "first"}`,
+					"a/zh.json": `{"9001": "第一"}`,
+					"b/en.json": `{"9001": "other"}`,
+				})
+				require.NoError(t, RegisterCatalog(fsys, "a"))
+				require.NoError(t, RegisterCatalog(fsys, "b"))
+			},
+			wantErr: []string{`error code 9001 translated twice for locale "en" (b/en.json)`},
+		},
+		{
+			name: "同时报告多个问题",
+			prepare: func(t *testing.T) {
+				New(9001, "first")
+				New(9001, "again")
+				New(9002, "second")
+			},
+			wantErr: []string{
+				`error code 9001 registered twice`,
+				`error code 9001 has no "en" translation`,
+				`error code 9002 has no "zh" translation`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateCatalog(t)
+			tc.prepare(t)
+
+			err := CheckCatalog()
+			if len(tc.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tc.wantErr {
+				require.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+// TestRegisterCatalogErrors 验证无法加载的目录返回错误。
+func TestRegisterCatalogErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{name: "目录为空", files: map[string]string{"other/en.json": `{}`}, wantErr: `no catalog files in "locales"`},
+		{name: "不是 JSON", files: map[string]string{"locales/en.json": `not json`}, wantErr: "parse catalog locales/en.json"},
+		{name: "键不是错误码", files: map[string]string{"locales/en.json": `{"oops": "message"}`}, wantErr: `key "oops" is not an error code`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateCatalog(t)
+			err := RegisterCatalog(catalogFS(tc.files), "locales")
+			require.ErrorContains(t, err, tc.wantErr)
+			require.Panics(t, func() { MustRegisterCatalog(catalogFS(tc.files), "locales") })
+		})
+	}
+}
+
+// TestLocalize 验证消息按协商出的语言渲染，缺少翻译时依次回退到默认语言与定义时的消息。
+func TestLocalize(t *testing.T) {
+	isolateCatalog(t)
+
+	translated := New(9001, "defined message")
+	englishOnly := New(9002, "english only")
+	untranslated := New(9003, "untranslated")
+	require.NoError(t, RegisterCatalog(catalogFS(map[string]string{
+		"locales/en.json": `{"9001": "english message", "9002": "english catalog"}`,
+		"locales/zh.json": `{"9001": "中文消息"}`,
+	}), "locales"))
+
+	cases := []struct {
+		name           string
+		err            *Error
+		acceptLanguage string
+		want           string
+	}{
+		{name: "中文", err: translated, acceptLanguage: "zh-CN,zh;q=0.9", want: "中文消息"},
+		{name: "英文", err: translated, acceptLanguage: "en-US", want: "english message"},
+		{name: "按 q 值选择英文", err: translated, acceptLanguage: "zh;q=0.5, en", want: "english message"},
+		{name: "未提供请求头使用默认语言", err: translated, want: "english message"},
+		{name: "不支持的语言回退默认语言", err: translated, acceptLanguage: "fr", want: "english message"},
+		{name: "缺少中文翻译回退默认语言", err: englishOnly, acceptLanguage: "zh", want: "english catalog"},
+		{name: "目录中没有翻译时使用定义时的消息", err: untranslated, acceptLanguage: "zh", want: "untranslated"},
+		{name: "定制消息原样返回", err: translated.WithMessage("custom"), acceptLanguage: "zh", want: "custom"},
+		{name: "派生的错误仍使用目录翻译", err: translated.WithStatus(418).Wrap(errTest), acceptLanguage: "zh", want: "中文消息"},
+		{name: "空错误", acceptLanguage: "zh", want: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.err.Localize(tc.acceptLanguage))
+		})
+	}
+
+	require.Equal(t, []string{"en", "zh"}, slices.Sorted(maps.Keys(catalogs)))
+}
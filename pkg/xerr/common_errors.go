@@ -1,11 +1,27 @@
 package xerr
 
+import (
+	"embed"
+	"net/http"
+)
+
 // 定义全局通用错误，错误码范围 1-999 预留给系统级错误使用。
 var (
-	ErrBadRequest     = New(400, "请求参数错误")
-	ErrUnauthorized   = New(401, "未授权")
-	ErrForbidden      = New(403, "禁止访问")
-	ErrNotFound       = New(404, "资源未找到")
-	ErrInternalServer = New(500, "服务器内部错误")
-	ErrDatabase       = New(501, "数据库错误")
+	ErrBadRequest       = New(400, "请求参数错误")
+	ErrUnauthorized     = New(401, "未授权")
+	ErrForbidden        = New(403, "禁止访问")
+	ErrNotFound         = New(404, "资源未找到")
+	ErrMethodNotAllowed = New(405, "请求方法不被允许")
+	ErrConflict         = New(409, "资源冲突")
+	ErrInternalServer   = New(500, "服务器内部错误")
+	ErrDatabase         = New(501, "数据库错误").WithStatus(http.StatusInternalServerError)
 )
+
+// locales 为通用错误码的消息目录。各模块以同样的方式嵌入并注册自己的目录，缺少翻译时 CheckCatalog 会阻止服务启动。
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+func init() {
+	MustRegisterCatalog(locales, "locales")
+}
@@ -1,23 +1,66 @@
 package xerr
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
-// Error 表示包含业务错误码与消息的结构化错误。
+// Error 表示包含业务错误码与消息的结构化错误，可携带默认 HTTP 状态码与底层原因。
+// 底层原因只用于日志与 errors.Is/As 判断，不会出现在响应中。
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// custom 表示消息已通过 WithMessage 定制，渲染时不再替换为目录中的翻译。
+	custom bool
+	status int
+	cause  error
+	data   any
 }
 
-// Error 实现 error 接口。
+// Error 实现 error 接口，携带底层原因时一并输出，便于记录日志。
 func (e *Error) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
 	return e.Message
 }
 
-// New 构造一个带错误码的业务错误。
+// Unwrap 返回底层原因。
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.cause
+}
+
+// Is 按错误码判断是否为同一业务错误，使 WithMessage、Wrap 得到的副本仍能与定义时的错误匹配。
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e != nil && t != nil && t.Code == e.Code
+}
+
+// HTTPStatus 返回错误对应的 HTTP 状态码：优先使用 WithStatus 指定的值；
+// 未指定时，400-599 之间的错误码直接作为状态码，其余返回 500。
+func (e *Error) HTTPStatus() int {
+	switch {
+	case e == nil:
+		return http.StatusInternalServerError
+	case e.status != 0:
+		return e.status
+	case e.Code >= http.StatusBadRequest && e.Code < 600:
+		return e.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// New 构造一个带错误码的业务错误，并登记错误码以便 CheckCatalog 校验重复定义与缺失的翻译。
+// message 为默认消息，仅在消息目录缺少对应翻译时使用。
 func New(code int, message string) *Error {
+	register(code, message)
 	return &Error{Code: code, Message: message}
 }
 
@@ -28,6 +71,45 @@ func (e *Error) WithMessage(message string) *Error {
 	}
 	clone := *e
 	clone.Message = message
+	clone.custom = true
+	return &clone
+}
+
+// WithStatus 克隆当前错误并指定默认 HTTP 状态码，通常在定义错误时使用。
+func (e *Error) WithStatus(status int) *Error {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.status = status
+	return &clone
+}
+
+// WithData 克隆当前错误并附带返回给客户端的结构化详情，例如被拒绝的过滤字段。
+func (e *Error) WithData(data any) *Error {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.data = data
+	return &clone
+}
+
+// Data 返回 WithData 附带的详情。
+func (e *Error) Data() any {
+	if e == nil {
+		return nil
+	}
+	return e.data
+}
+
+// Wrap 克隆当前错误并附带底层原因。
+func (e *Error) Wrap(cause error) *Error {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.cause = cause
 	return &clone
 }
 
@@ -0,0 +1,122 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var errTest = errors.New("connection reset")
+
+// customError 模拟通过 As 方法把自身转换为业务错误的错误类型，例如 filter.Error。
+type customError struct{}
+
+func (customError) Error() string { return "custom" }
+
+func (customError) As(target any) bool {
+	business, ok := target.(**Error)
+	if ok {
+		*business = ErrBadRequest.WithMessage("custom").WithData("detail")
+	}
+	return ok
+}
+
+// TestErrorDerivation 验证 WithMessage、WithStatus、WithData 与 Wrap 返回副本，原错误保持不变，且副本按错误码匹配原错误。
+func TestErrorDerivation(t *testing.T) {
+	isolateCatalog(t)
+	base := New(9001, "base")
+
+	derived := base.WithStatus(http.StatusTeapot).WithMessage("changed").WithData("detail").Wrap(errTest)
+
+	require.Equal(t, "base", base.Message)
+	require.Equal(t, http.StatusInternalServerError, base.HTTPStatus())
+	require.Nil(t, base.Data())
+	require.Nil(t, base.Unwrap())
+
+	require.Equal(t, 9001, derived.Code)
+	require.Equal(t, "changed", derived.Message)
+	require.Equal(t, http.StatusTeapot, derived.HTTPStatus())
+	require.Equal(t, "detail", derived.Data())
+	require.Equal(t, "changed: connection reset", derived.Error())
+	require.Equal(t, "changed: connection reset", fmt.Sprintf("%v", derived))
+	require.ErrorIs(t, derived, base)
+	require.ErrorIs(t, derived, errTest)
+	require.NotErrorIs(t, derived, ErrBadRequest)
+
+	var nilErr *Error
+	require.Nil(t, nilErr.WithMessage("x"))
+	require.Nil(t, nilErr.WithStatus(http.StatusTeapot))
+	require.Nil(t, nilErr.WithData("x"))
+	require.Nil(t, nilErr.Wrap(errTest))
+	require.Nil(t, nilErr.Data())
+	require.Equal(t, "<nil>", nilErr.Error())
+}
+
+// TestErrorHTTPStatus 验证状态码优先取 WithStatus 指定的值，否则由错误码推导。
+func TestErrorHTTPStatus(t *testing.T) {
+	cases := []struct {
+		name string
+		err  *Error
+		want int
+	}{
+		{name: "错误码即状态码", err: ErrNotFound, want: http.StatusNotFound},
+		{name: "显式指定的状态码", err: ErrDatabase, want: http.StatusInternalServerError},
+		{name: "覆盖错误码推导的状态码", err: ErrConflict.WithStatus(http.StatusPreconditionFailed), want: http.StatusPreconditionFailed},
+		{name: "业务错误码默认 500", err: &Error{Code: 2001}, want: http.StatusInternalServerError},
+		{name: "空错误", want: http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.err.HTTPStatus())
+		})
+	}
+}
+
+// TestFromOr 验证错误链中的业务错误被原样取出，gorm 错误被映射，其余错误使用兜底错误并保留原因。
+func TestFromOr(t *testing.T) {
+	fallback := ErrBadRequest.WithStatus(http.StatusUnprocessableEntity)
+	conflict := ErrConflict.WithStatus(http.StatusPreconditionFailed)
+
+	cases := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus int
+		wantCause  error
+		wantData   any
+	}{
+		{name: "业务错误", err: ErrForbidden, wantCode: ErrForbidden.Code, wantStatus: http.StatusForbidden},
+		{name: "包装后的业务错误保留状态码", err: fmt.Errorf("update: %w", conflict), wantCode: ErrConflict.Code, wantStatus: http.StatusPreconditionFailed},
+		{name: "业务错误的原因", err: fmt.Errorf("query: %w", ErrDatabase.Wrap(errTest)), wantCode: ErrDatabase.Code, wantStatus: http.StatusInternalServerError, wantCause: errTest},
+		{name: "通过 As 转换的错误", err: fmt.Errorf("list: %w", customError{}), wantCode: ErrBadRequest.Code, wantStatus: http.StatusBadRequest, wantData: "detail"},
+		{name: "记录不存在", err: fmt.Errorf("find: %w", gorm.ErrRecordNotFound), wantCode: ErrNotFound.Code, wantStatus: http.StatusNotFound, wantCause: gorm.ErrRecordNotFound},
+		{name: "唯一键冲突", err: gorm.ErrDuplicatedKey, wantCode: ErrConflict.Code, wantStatus: http.StatusConflict, wantCause: gorm.ErrDuplicatedKey},
+		{name: "外键约束", err: gorm.ErrForeignKeyViolated, wantCode: ErrConflict.Code, wantStatus: http.StatusConflict, wantCause: gorm.ErrForeignKeyViolated},
+		{name: "未知错误使用兜底", err: errTest, wantCode: ErrBadRequest.Code, wantStatus: http.StatusUnprocessableEntity, wantCause: errTest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FromOr(tc.err, fallback)
+			require.Equal(t, tc.wantCode, got.Code)
+			require.Equal(t, tc.wantStatus, got.HTTPStatus())
+			require.Equal(t, tc.wantData, got.Data())
+			if tc.wantCause != nil {
+				require.ErrorIs(t, got, tc.wantCause)
+			}
+		})
+	}
+
+	require.Nil(t, FromOr(nil, fallback))
+	require.Nil(t, From(nil))
+
+	internal := From(errTest)
+	require.ErrorIs(t, internal, ErrInternalServer)
+	require.ErrorIs(t, internal, errTest)
+	require.Equal(t, http.StatusInternalServerError, internal.HTTPStatus())
+}
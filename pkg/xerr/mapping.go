@@ -0,0 +1,38 @@
+package xerr
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// From 将任意错误转换为业务错误，无法识别的错误视为 ErrInternalServer，见 FromOr。
+func From(err error) *Error {
+	return FromOr(err, ErrInternalServer)
+}
+
+// FromOr 将任意错误转换为业务错误：
+//   - 错误链中已有 *Error 时直接返回；
+//   - gorm.ErrRecordNotFound 映射为 ErrNotFound，gorm.ErrDuplicatedKey 与 gorm.ErrForeignKeyViolated 映射为 ErrConflict；
+//   - 其余错误使用 fallback。
+//
+// 映射得到的错误以 err 作为底层原因。err 为 nil 时返回 nil。
+func FromOr(err error, fallback *Error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var business *Error
+	if errors.As(err, &business) {
+		return business
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound.Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.Is(err, gorm.ErrForeignKeyViolated):
+		return ErrConflict.Wrap(err)
+	default:
+		return fallback.Wrap(err)
+	}
+}